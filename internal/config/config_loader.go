@@ -1,23 +1,157 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
 )
 
-// LoadFromEnv loads database configuration from environment variables
-func LoadFromEnv() *DBConfig {
-	config := &DBConfig{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnvAsInt("DB_PORT", 5432),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", ""),
-		DBName:   getEnv("DB_NAME", "fyne"),
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+// ConfigFileName is looked up next to the executable first, then in the user config dir
+const ConfigFileName = "config.toml"
+
+// appConfigDirName is the folder name used inside os.UserConfigDir()
+const appConfigDirName = "ProgramSO"
+
+type PoolConfig struct {
+	MaxOpenConns       int `toml:"max_open_conns"`
+	MaxIdleConns       int `toml:"max_idle_conns"`
+	ConnMaxLifetimeMin int `toml:"conn_max_lifetime_minutes"`
+}
+
+type PathsConfig struct {
+	TempDir string `toml:"temp_dir"`
+}
+
+type StoreConfig struct {
+	Name    string `toml:"name"`
+	Address string `toml:"address"`
+	Phone   string `toml:"phone"`
+}
+
+type CleanupConfig struct {
+	PDFMaxAgeDays int `toml:"pdf_max_age_days"`
+}
+
+// NumberingConfig holds the document number pattern of each generated
+// document type. Notas pembelian keep the supplier's own invoice number.
+type NumberingConfig struct {
+	Sell      string `toml:"sell"`
+	Retur     string `toml:"retur"`
+	SellRetur string `toml:"sell_retur"`
+}
+
+// CostingConfig seeds the costing method in app_settings on the first start;
+// after that the method stored in the database is used by every workstation
+type CostingConfig struct {
+	Method string `toml:"method"`
+}
+
+// AppConfig is the full application configuration as read from config.toml
+type AppConfig struct {
+	Database  DBConfig        `toml:"database"`
+	Pool      PoolConfig      `toml:"pool"`
+	Paths     PathsConfig     `toml:"paths"`
+	Store     StoreConfig     `toml:"store"`
+	Cleanup   CleanupConfig   `toml:"cleanup"`
+	Numbering NumberingConfig `toml:"numbering"`
+	Costing   CostingConfig   `toml:"costing"`
+
+	// Source is the config file that was loaded, empty when only defaults/env are used
+	Source string `toml:"-"`
+}
+
+// DefaultAppConfig returns the built-in defaults used when no config file exists
+func DefaultAppConfig() *AppConfig {
+	return &AppConfig{
+		Database: *NewDBConfig(),
+		Pool: PoolConfig{
+			MaxOpenConns:       25,
+			MaxIdleConns:       5,
+			ConnMaxLifetimeMin: 0,
+		},
+		Paths: PathsConfig{
+			TempDir: "temp",
+		},
+		Store: StoreConfig{
+			Name: "Program SO",
+		},
+		Cleanup: CleanupConfig{
+			PDFMaxAgeDays: 90,
+		},
+		Numbering: NumberingConfig{
+			Sell:      "PJ/{YYYY}{MM}/{seq:5}",
+			Retur:     "RB/{YYYY}{MM}/{seq:5}",
+			SellRetur: "RJ/{YYYY}{MM}/{seq:5}",
+		},
+		Costing: CostingConfig{
+			Method: "average",
+		},
 	}
+}
+
+// PDFMaxAge returns the cleanup age for generated PDF files
+func (c *AppConfig) PDFMaxAge() time.Duration {
+	return time.Duration(c.Cleanup.PDFMaxAgeDays) * 24 * time.Hour
+}
+
+// ConnMaxLifetime returns the pool connection lifetime, 0 means unlimited
+func (c *PoolConfig) ConnMaxLifetime() time.Duration {
+	return time.Duration(c.ConnMaxLifetimeMin) * time.Minute
+}
+
+// Load builds the configuration in layers: defaults, then the first config.toml
+// found by ConfigPaths, then DB_* environment variables.
+func Load() (*AppConfig, error) {
+	cfg := DefaultAppConfig()
+
+	for _, path := range ConfigPaths() {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if _, err := toml.DecodeFile(path, cfg); err != nil {
+			return nil, fmt.Errorf("gagal membaca %s: %w", path, err)
+		}
+		cfg.Source = path
+		break
+	}
+
+	applyEnv(&cfg.Database)
+	return cfg, nil
+}
+
+// ConfigPaths returns the candidate config.toml locations in lookup order
+func ConfigPaths() []string {
+	var paths []string
+	if exe, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(exe), ConfigFileName))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, appConfigDirName, ConfigFileName))
+	}
+	return paths
+}
+
+// LoadFromEnv loads database configuration from the defaults overridden by environment variables
+func LoadFromEnv() *DBConfig {
+	config := NewDBConfig()
+	applyEnv(config)
 	return config
 }
 
+// applyEnv overrides database settings with any DB_* environment variable that is set
+func applyEnv(config *DBConfig) {
+	config.Host = getEnv("DB_HOST", config.Host)
+	config.Port = getEnvAsInt("DB_PORT", config.Port)
+	config.User = getEnv("DB_USER", config.User)
+	config.Password = getEnv("DB_PASSWORD", config.Password)
+	config.DBName = getEnv("DB_NAME", config.DBName)
+	config.SSLMode = getEnv("DB_SSLMODE", config.SSLMode)
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
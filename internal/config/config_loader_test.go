@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// isolate points os.UserConfigDir at a temporary directory, clears the DB_*
+// variables of the host and returns where Load looks for config.toml
+func isolate(t *testing.T) string {
+	t.Helper()
+	for _, key := range []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSLMODE"} {
+		t.Setenv(key, "")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+
+	userDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatalf("UserConfigDir: %v", err)
+	}
+	return filepath.Join(userDir, appConfigDirName, ConfigFileName)
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadWithoutFileUsesDefaults(t *testing.T) {
+	isolate(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Source != "" {
+		t.Errorf("Source = %q, want empty", cfg.Source)
+	}
+	if cfg.Database != *NewDBConfig() {
+		t.Errorf("Database = %+v, want defaults", cfg.Database)
+	}
+	if cfg.Pool.MaxOpenConns != 25 || cfg.Cleanup.PDFMaxAgeDays != 90 {
+		t.Errorf("Pool/Cleanup = %+v/%+v, want defaults", cfg.Pool, cfg.Cleanup)
+	}
+}
+
+func TestLoadFileOverridesOnlyGivenKeys(t *testing.T) {
+	path := isolate(t)
+	writeConfig(t, path, `
+[database]
+host = "server"
+port = 5433
+
+[store]
+name = "Toko Maju"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Source != path {
+		t.Errorf("Source = %q, want %q", cfg.Source, path)
+	}
+	if cfg.Database.Host != "server" || cfg.Database.Port != 5433 {
+		t.Errorf("Database = %s:%d, want server:5433", cfg.Database.Host, cfg.Database.Port)
+	}
+	// Keys missing from the file keep their defaults
+	if cfg.Database.DBName != "fyne" || cfg.Pool.MaxIdleConns != 5 {
+		t.Errorf("DBName/MaxIdleConns = %q/%d, want defaults", cfg.Database.DBName, cfg.Pool.MaxIdleConns)
+	}
+	if cfg.Store.Name != "Toko Maju" {
+		t.Errorf("Store.Name = %q, want %q", cfg.Store.Name, "Toko Maju")
+	}
+}
+
+func TestLoadEnvironmentWinsOverFile(t *testing.T) {
+	path := isolate(t)
+	writeConfig(t, path, "[database]\nhost = \"server\"\nuser = \"file-user\"\n")
+	t.Setenv("DB_HOST", "env-host")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Database.Host != "env-host" {
+		t.Errorf("Database.Host = %q, want env-host", cfg.Database.Host)
+	}
+	if cfg.Database.User != "file-user" {
+		t.Errorf("Database.User = %q, want file-user", cfg.Database.User)
+	}
+}
+
+func TestLoadRejectsInvalidFile(t *testing.T) {
+	path := isolate(t)
+	writeConfig(t, path, "[database")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() error = nil, want parse error")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	isolate(t)
+	t.Setenv("DB_HOST", "db.local")
+	t.Setenv("DB_PORT", "6543")
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("DB_SSLMODE", "require")
+
+	got := NewDBConfig()
+	applyEnv(got)
+	want := DBConfig{Host: "db.local", Port: 6543, User: "postgres", Password: "secret", DBName: "fyne", SSLMode: "require"}
+	if *got != want {
+		t.Errorf("applyEnv() = %+v, want %+v", *got, want)
+	}
+
+	// A port that is not a number keeps the previous value
+	t.Setenv("DB_PORT", "abc")
+	got = &DBConfig{Port: 7000}
+	applyEnv(got)
+	if got.Port != 7000 {
+		t.Errorf("Port = %d, want 7000", got.Port)
+	}
+}
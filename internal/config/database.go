@@ -7,12 +7,12 @@ import (
 )
 
 type DBConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host     string `toml:"host"`
+	Port     int    `toml:"port"`
+	User     string `toml:"user"`
+	Password string `toml:"password"`
+	DBName   string `toml:"dbname"`
+	SSLMode  string `toml:"sslmode"`
 }
 
 func NewDBConfig() *DBConfig {
@@ -33,15 +33,20 @@ func (c *DBConfig) ConnectionString() string {
 	)
 }
 
-func ConnectDB(config *DBConfig) (*sqlx.DB, error) {
+// ConnectDB opens the connection pool. A nil pool uses the default pool sizes.
+func ConnectDB(config *DBConfig, pool *PoolConfig) (*sqlx.DB, error) {
 	db, err := sqlx.Connect("postgres", config.ConnectionString())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	if pool == nil {
+		pool = &DefaultAppConfig().Pool
+	}
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime())
 
 	// Test connection
 	if err := db.Ping(); err != nil {
@@ -0,0 +1,432 @@
+// Package costing keeps the cost layers of every item and values the goods
+// sold (HPP penjualan) with the costing method stored in app_settings.
+//
+// Layers are maintained for both methods so the method can be switched
+// without a rebuild: every purchase or retur penjualan opens a layer, every
+// sale consumes layers oldest first, and stock corrections open or consume
+// layers at the moving-average cost of the item.
+//
+// Units issued while no layer is left (an item sold below zero stock) go on a
+// backlog layer: a layer with negative remaining_qty at the current cost of the
+// item. The next receipt settles the backlog before its own units become
+// available, so the open layers always add up to items.qty.
+package costing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Costing methods accepted in [costing] method
+const (
+	MethodAverage = "average"
+	MethodFIFO    = "fifo"
+)
+
+// syncTolerance absorbs float rounding when comparing the layers with the stock
+const syncTolerance = 0.0001
+
+// Service keeps the cost layers. Every repository that moves stock gets the
+// same instance, so layers and HPP always follow one costing method.
+type Service struct {
+	method string
+}
+
+// NewService takes the costing method shared through app_settings
+func NewService(method string) *Service {
+	return &Service{method: NormalizeMethod(method)}
+}
+
+// NormalizeMethod maps a configured method to MethodFIFO or MethodAverage;
+// anything but "fifo" uses the moving average
+func NormalizeMethod(method string) string {
+	if strings.EqualFold(strings.TrimSpace(method), MethodFIFO) {
+		return MethodFIFO
+	}
+	return MethodAverage
+}
+
+// Method returns MethodAverage or MethodFIFO
+func (s *Service) Method() string {
+	return s.method
+}
+
+// Take is the part of one layer used by a stock issue
+type Take struct {
+	LayerID  uuid.UUID `db:"id"`
+	Qty      float64   `db:"qty"`
+	UnitCost float64   `db:"unit_cost"`
+}
+
+// OpenLayer adds qty units at unitCost for the document modelID/modelType.
+// Backlog layers of the item are settled first, oldest first; only the units
+// left after that remain available on the new layer.
+func (s *Service) OpenLayer(tx *sqlx.Tx, itemID uuid.UUID, qty, unitCost float64, trxDate time.Time, modelID uuid.UUID, modelType string) error {
+	if qty <= 0 {
+		return nil
+	}
+
+	var backlogs []Take
+	err := tx.Select(&backlogs, `SELECT id, -remaining_qty AS qty, unit_cost FROM cost_layers
+		WHERE item_id = $1 AND remaining_qty < 0
+		ORDER BY trx_date, created_at
+		FOR UPDATE`, itemID)
+	if err != nil {
+		return fmt.Errorf("gagal membaca backlog layer HPP: %v", err)
+	}
+
+	remaining := qty
+	for _, b := range backlogs {
+		if remaining <= 0 {
+			break
+		}
+		settle := b.Qty
+		if settle > remaining {
+			settle = remaining
+		}
+		_, err = tx.Exec(`UPDATE cost_layers SET remaining_qty = remaining_qty + $1 WHERE id = $2`, settle, b.LayerID)
+		if err != nil {
+			return fmt.Errorf("gagal melunasi backlog layer HPP: %v", err)
+		}
+		remaining -= settle
+	}
+
+	query := `INSERT INTO cost_layers
+			  (id, item_id, trx_date, model_id, model_type, qty, remaining_qty, unit_cost, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err = tx.Exec(query, uuid.New(), itemID, trxDate, modelID, modelType, qty, remaining, unitCost, time.Now())
+	if err != nil {
+		return fmt.Errorf("gagal membuka layer HPP: %v", err)
+	}
+	return nil
+}
+
+// consume takes qty units from the open layers of an item, oldest first. Layers
+// of preferModelID (e.g. the purchase a retur refers to) are used before the
+// others. The part not covered by any layer, when the item goes below zero
+// stock, is put on a new backlog layer dated trxDate at the current cost.
+func (s *Service) consume(tx *sqlx.Tx, itemID uuid.UUID, qty float64, preferModelID *uuid.UUID, trxDate time.Time) ([]Take, error) {
+	if qty <= 0 {
+		return nil, nil
+	}
+
+	var layers []Take
+	err := tx.Select(&layers, `SELECT id, remaining_qty AS qty, unit_cost FROM cost_layers
+		WHERE item_id = $1 AND remaining_qty > 0
+		ORDER BY COALESCE(model_id = $2::uuid, false) DESC, trx_date, created_at
+		FOR UPDATE`, itemID, preferModelID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca layer HPP: %v", err)
+	}
+
+	var takes []Take
+	left := qty
+	for _, l := range layers {
+		if left <= 0 {
+			break
+		}
+		take := l.Qty
+		if take > left {
+			take = left
+		}
+		_, err = tx.Exec(`UPDATE cost_layers SET remaining_qty = remaining_qty - $1 WHERE id = $2`, take, l.LayerID)
+		if err != nil {
+			return nil, fmt.Errorf("gagal memakai layer HPP: %v", err)
+		}
+		takes = append(takes, Take{LayerID: l.LayerID, Qty: take, UnitCost: l.UnitCost})
+		left -= take
+	}
+
+	if left > syncTolerance {
+		backlog, err := openBacklog(tx, itemID, left, trxDate)
+		if err != nil {
+			return nil, err
+		}
+		takes = append(takes, backlog)
+	}
+	return takes, nil
+}
+
+// openBacklog records qty units issued without a layer to take them from
+func openBacklog(tx *sqlx.Tx, itemID uuid.UUID, qty float64, trxDate time.Time) (Take, error) {
+	unitCost, err := currentCost(tx, itemID)
+	if err != nil {
+		return Take{}, err
+	}
+
+	backlog := Take{LayerID: uuid.New(), Qty: qty, UnitCost: unitCost}
+	query := `INSERT INTO cost_layers
+			  (id, item_id, trx_date, model_type, qty, remaining_qty, unit_cost, created_at)
+			  VALUES ($1, $2, $3, 'backlog', 0, $4, $5, $6)`
+
+	_, err = tx.Exec(query, backlog.LayerID, itemID, trxDate, -qty, unitCost, time.Now())
+	if err != nil {
+		return Take{}, fmt.Errorf("gagal mencatat backlog layer HPP: %v", err)
+	}
+	return backlog, nil
+}
+
+// CloseLayers reverses the layers opened by a document that is voided or
+// rewritten. Units of those layers that were already used are taken from the
+// other layers so the layers still add up to the stock on hand, and the
+// consumptions recorded on a closed layer are moved to the layers that took
+// its place, so a later restore does not put units back on a closed layer.
+func (s *Service) CloseLayers(tx *sqlx.Tx, modelID uuid.UUID, modelType string) error {
+	var layers []struct {
+		LayerID uuid.UUID `db:"id"`
+		ItemID  uuid.UUID `db:"item_id"`
+		Used    float64   `db:"used"`
+	}
+	now := time.Now()
+	err := tx.Select(&layers, `UPDATE cost_layers SET remaining_qty = 0, closed_at = $3
+		WHERE model_id = $1 AND model_type = $2 AND closed_at IS NULL
+		RETURNING id, item_id, qty - remaining_qty AS used`, modelID, modelType, now)
+	if err != nil {
+		return fmt.Errorf("gagal menutup layer HPP: %v", err)
+	}
+
+	for _, l := range layers {
+		takes, err := s.consume(tx, l.ItemID, l.Used, nil, now)
+		if err != nil {
+			return err
+		}
+		takes, err = moveConsumptions(tx, "sell_cost_consumptions", "sell_detail_id, unit_cost, created_at", l.LayerID, takes)
+		if err != nil {
+			return err
+		}
+		if _, err = moveConsumptions(tx, "cost_consumptions", "model_id, model_type, trx_date, unit_cost, created_at", l.LayerID, takes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveConsumptions points the consumptions of table recorded on a closed layer
+// at the replacement takes, splitting rows where needed; cols are the columns
+// copied to a split row. What no take covers stays on the closed layer. The
+// takes not used up are returned for the next table.
+func moveConsumptions(tx *sqlx.Tx, table, cols string, layerID uuid.UUID, takes []Take) ([]Take, error) {
+	var rows []struct {
+		ID  uuid.UUID `db:"id"`
+		Qty float64   `db:"qty"`
+	}
+	err := tx.Select(&rows, `SELECT id, qty FROM `+table+` WHERE layer_id = $1 ORDER BY created_at FOR UPDATE`, layerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca pemakaian layer HPP: %v", err)
+	}
+
+	insert := `INSERT INTO ` + table + ` (id, layer_id, qty, ` + cols + `)
+		SELECT $1, $2, $3, ` + cols + ` FROM ` + table + ` WHERE id = $4`
+
+	for _, row := range rows {
+		left := row.Qty
+		for len(takes) > 0 && left > 0 {
+			qty := takes[0].Qty
+			if qty > left {
+				qty = left
+			}
+			if _, err := tx.Exec(insert, uuid.New(), takes[0].LayerID, qty, row.ID); err != nil {
+				return nil, fmt.Errorf("gagal memindahkan pemakaian layer HPP: %v", err)
+			}
+			takes[0].Qty -= qty
+			if takes[0].Qty <= 0 {
+				takes = takes[1:]
+			}
+			left -= qty
+		}
+
+		if left > 0 {
+			_, err = tx.Exec(`UPDATE `+table+` SET qty = $1 WHERE id = $2`, left, row.ID)
+		} else {
+			_, err = tx.Exec(`DELETE FROM `+table+` WHERE id = $1`, row.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gagal memindahkan pemakaian layer HPP: %v", err)
+		}
+	}
+	return takes, nil
+}
+
+// consumed is a recorded consumption together with the layer it was taken from
+type consumed struct {
+	LayerID  uuid.UUID  `db:"layer_id"`
+	ItemID   uuid.UUID  `db:"item_id"`
+	TrxDate  time.Time  `db:"trx_date"`
+	ClosedAt *time.Time `db:"closed_at"`
+	Qty      float64    `db:"qty"`
+	UnitCost float64    `db:"unit_cost"`
+}
+
+// restore puts consumed units back on their layers. A layer closed in the
+// meantime is not revived; a replacement layer at the consumed cost is opened
+// for the document modelID instead.
+func (s *Service) restore(tx *sqlx.Tx, rows []consumed, modelID uuid.UUID) error {
+	for _, c := range rows {
+		if c.ClosedAt != nil {
+			if err := s.OpenLayer(tx, c.ItemID, c.Qty, c.UnitCost, c.TrxDate, modelID, "restore"); err != nil {
+				return err
+			}
+			continue
+		}
+		_, err := tx.Exec(`UPDATE cost_layers SET remaining_qty = remaining_qty + $1 WHERE id = $2`, c.Qty, c.LayerID)
+		if err != nil {
+			return fmt.Errorf("gagal mengembalikan layer HPP: %v", err)
+		}
+	}
+	return nil
+}
+
+// ConsumeFor issues qty units for a document other than a sale (retur
+// pembelian, stock correction): the layers it used are recorded with trxDate so
+// Restore can put the units back and the valuation knows when they left
+func (s *Service) ConsumeFor(tx *sqlx.Tx, itemID uuid.UUID, qty float64, preferModelID *uuid.UUID, trxDate time.Time, modelID uuid.UUID, modelType string) error {
+	takes, err := s.consume(tx, itemID, qty, preferModelID, trxDate)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO cost_consumptions (id, layer_id, model_id, model_type, trx_date, qty, unit_cost, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	for _, t := range takes {
+		_, err = tx.Exec(query, uuid.New(), t.LayerID, modelID, modelType, trxDate, t.Qty, t.UnitCost, time.Now())
+		if err != nil {
+			return fmt.Errorf("gagal mencatat pemakaian layer HPP: %v", err)
+		}
+	}
+	return nil
+}
+
+// Restore puts the units consumed through ConsumeFor by modelID/modelType back
+// on their layers, before the document is voided or its lines are rewritten
+func (s *Service) Restore(tx *sqlx.Tx, modelID uuid.UUID, modelType string) error {
+	var rows []consumed
+	err := tx.Select(&rows, `SELECT c.layer_id, l.item_id, l.trx_date, l.closed_at, c.qty, c.unit_cost
+		FROM cost_consumptions c
+		JOIN cost_layers l ON l.id = c.layer_id
+		WHERE c.model_id = $1 AND c.model_type = $2`, modelID, modelType)
+	if err != nil {
+		return fmt.Errorf("gagal membaca pemakaian layer HPP: %v", err)
+	}
+
+	if err := s.restore(tx, rows, modelID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM cost_consumptions WHERE model_id = $1 AND model_type = $2`, modelID, modelType)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus pemakaian layer HPP: %v", err)
+	}
+	return nil
+}
+
+// Adjust follows a stock correction (opname, edit barang) of delta units: an
+// increase opens a layer at the current cost of the item, a decrease consumes layers
+func (s *Service) Adjust(tx *sqlx.Tx, itemID uuid.UUID, delta float64, trxDate time.Time, modelID uuid.UUID, modelType string) error {
+	if delta < 0 {
+		return s.ConsumeFor(tx, itemID, -delta, nil, trxDate, modelID, modelType)
+	}
+	if delta == 0 {
+		return nil
+	}
+
+	unitCost, err := currentCost(tx, itemID)
+	if err != nil {
+		return err
+	}
+	return s.OpenLayer(tx, itemID, delta, unitCost, trxDate, modelID, modelType)
+}
+
+// currentCost is the moving-average cost of an item, or its latest purchase
+// price while no average has been built up yet
+func currentCost(tx *sqlx.Tx, itemID uuid.UUID) (float64, error) {
+	var cost float64
+	err := tx.Get(&cost, `SELECT COALESCE(NULLIF(i.avg_cost, 0), (
+			SELECT pd.price_amount FROM purchase_details pd
+			JOIN purchase_headers ph ON ph.id = pd.header_id
+			WHERE pd.item_id = i.id AND ph.status = 'ACTIVE'
+			ORDER BY ph.purchase_date DESC, pd.created_at DESC
+			LIMIT 1), 0)
+		FROM items i WHERE i.id = $1`, itemID)
+	if err != nil {
+		return 0, fmt.Errorf("gagal membaca HPP barang: %v", err)
+	}
+	return cost, nil
+}
+
+// Sync opens or consumes layers so the open layers of an item add up to qty
+// again, after its stock was rebuilt or found out of step with the layers
+func (s *Service) Sync(tx *sqlx.Tx, itemID uuid.UUID, qty float64, trxDate time.Time, modelID uuid.UUID, modelType string) error {
+	var onLayers float64
+	err := tx.Get(&onLayers, `SELECT COALESCE(SUM(remaining_qty), 0) FROM cost_layers WHERE item_id = $1`, itemID)
+	if err != nil {
+		return fmt.Errorf("gagal membaca layer HPP: %v", err)
+	}
+
+	delta := qty - onLayers
+	if delta < syncTolerance && delta > -syncTolerance {
+		return nil
+	}
+	return s.Adjust(tx, itemID, delta, trxDate, modelID, modelType)
+}
+
+// IssueSale consumes the layers for one sell_details row, records which layers
+// it used and returns the cost of goods sold of the line for the configured method
+func (s *Service) IssueSale(tx *sqlx.Tx, sellDetailID, itemID uuid.UUID, qty float64, trxDate time.Time) (float64, error) {
+	var avgCost float64
+	if err := tx.Get(&avgCost, `SELECT avg_cost FROM items WHERE id = $1`, itemID); err != nil {
+		return 0, fmt.Errorf("gagal membaca HPP barang: %v", err)
+	}
+
+	takes, err := s.consume(tx, itemID, qty, nil, trxDate)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO sell_cost_consumptions (id, sell_detail_id, layer_id, qty, unit_cost, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	var fifoCost float64
+	for _, t := range takes {
+		_, err = tx.Exec(query, uuid.New(), sellDetailID, t.LayerID, t.Qty, t.UnitCost, time.Now())
+		if err != nil {
+			return 0, fmt.Errorf("gagal mencatat pemakaian layer HPP: %v", err)
+		}
+		fifoCost += t.Qty * t.UnitCost
+	}
+
+	if s.method == MethodFIFO {
+		return fifoCost, nil
+	}
+	return qty * avgCost, nil
+}
+
+// RestoreSale puts the units consumed by every line of a nota penjualan back on
+// their layers, before the nota is voided or its lines are rewritten
+func (s *Service) RestoreSale(tx *sqlx.Tx, sellID uuid.UUID) error {
+	var rows []consumed
+	err := tx.Select(&rows, `SELECT c.layer_id, l.item_id, l.trx_date, l.closed_at, c.qty, c.unit_cost
+		FROM sell_cost_consumptions c
+		JOIN sell_details d ON d.id = c.sell_detail_id
+		JOIN cost_layers l ON l.id = c.layer_id
+		WHERE d.header_id = $1`, sellID)
+	if err != nil {
+		return fmt.Errorf("gagal membaca pemakaian layer HPP: %v", err)
+	}
+
+	if err := s.restore(tx, rows, sellID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM sell_cost_consumptions
+		WHERE sell_detail_id IN (SELECT id FROM sell_details WHERE header_id = $1)`, sellID)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus pemakaian layer HPP: %v", err)
+	}
+	return nil
+}
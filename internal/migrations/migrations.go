@@ -0,0 +1,254 @@
+// Package migrations applies the versioned schema changes embedded from sql/.
+//
+// Files are named NNNN_description.up.sql / NNNN_description.down.sql. Every
+// migration runs in its own transaction and is recorded in schema_migrations.
+// A Postgres advisory lock keeps two workstations from migrating at the same time.
+// The baseline 0001 adopts existing databases and refuses to be rolled back.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// advisoryLockKey is an arbitrary constant shared by every instance of the app
+const advisoryLockKey int64 = 73112024
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type AppliedMigration struct {
+	Version   int       `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load parses the sql files of fsys into migrations sorted by version
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		match := fileNamePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s", e.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := fs.ReadFile(fsys, "sql/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("versi migrasi %d dipakai oleh dua nama: %s dan %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrasi %04d_%s tidak memiliki file up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest returns the highest embedded migration version
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sqlx.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS public.schema_migrations (
+		version int NOT NULL,
+		name varchar(255) NOT NULL,
+		applied_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		CONSTRAINT schema_migrations_pkey PRIMARY KEY (version)
+	)`)
+	return err
+}
+
+// Applied returns the migrations recorded in schema_migrations, oldest first
+func (m *Migrator) Applied() ([]AppliedMigration, error) {
+	ctx := context.Background()
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	return m.applied(ctx, conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sqlx.Conn) ([]AppliedMigration, error) {
+	var rows []AppliedMigration
+	err := conn.SelectContext(ctx, &rows, `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	return rows, err
+}
+
+// Pending returns the embedded migrations that have not been applied yet
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return nil, err
+	}
+	return m.pending(applied), nil
+}
+
+func (m *Migrator) pending(applied []AppliedMigration) []Migration {
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if !done[mig.Version] {
+			pending = append(pending, mig)
+		}
+	}
+	return pending
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	return m.MigrateTo(m.Latest())
+}
+
+// MigrateTo applies pending migrations up to and including target, and rolls back
+// applied migrations above target (newest first) using their down files.
+func (m *Migrator) MigrateTo(target int) error {
+	ctx := context.Background()
+
+	// The advisory lock is held by the session, so everything runs on one connection
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return fmt.Errorf("gagal mengambil advisory lock migrasi: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockKey)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	// Read the state only after the lock, another instance may have just migrated
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range m.pending(applied) {
+		if mig.Version > target {
+			break
+		}
+		if err := m.run(ctx, conn, mig.Version, mig.Name, mig.Up, true); err != nil {
+			return err
+		}
+	}
+
+	byVersion := make(map[int]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+	for i := len(applied) - 1; i >= 0; i-- {
+		a := applied[i]
+		if a.Version <= target {
+			break
+		}
+		mig, ok := byVersion[a.Version]
+		if !ok || mig.Down == "" {
+			return fmt.Errorf("migrasi %04d_%s tidak memiliki file down", a.Version, a.Name)
+		}
+		if err := m.run(ctx, conn, mig.Version, mig.Name, mig.Down, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) run(ctx context.Context, conn *sqlx.Conn, version int, name, script string, up bool) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	direction := "up"
+	if !up {
+		direction = "down"
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migrasi %04d_%s (%s) gagal: %w", version, name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+			version, name, time.Now())
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func sqlFile(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestLoadSortsByVersionAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0010_tenth.up.sql":     sqlFile("up 10"),
+		"sql/0002_second.down.sql":  sqlFile("down 2"),
+		"sql/0001_initial.up.sql":   sqlFile("up 1"),
+		"sql/0002_second.up.sql":    sqlFile("up 2"),
+		"sql/0001_initial.down.sql": sqlFile("down 1"),
+		"sql/0010_tenth.down.sql":   sqlFile("down 10"),
+		"sql/0011_no_down.up.sql":   sqlFile("up 11"),
+	}
+
+	got, err := load(fsys)
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "initial", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+		{Version: 10, Name: "tenth", Up: "up 10", Down: "down 10"},
+		{Version: 11, Name: "no_down", Up: "up 11"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("load() returned %d migrations, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejectsBrokenSets(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"down without up": {
+			"sql/0001_initial.up.sql":  sqlFile("up 1"),
+			"sql/0002_orphan.down.sql": sqlFile("down 2"),
+		},
+		"one version with two names": {
+			"sql/0001_initial.up.sql": sqlFile("up 1"),
+			"sql/0001_other.down.sql": sqlFile("down 1"),
+		},
+		"invalid file name": {
+			"sql/0001_initial.up.sql": sqlFile("up 1"),
+			"sql/initial.sql":         sqlFile("up"),
+		},
+	}
+
+	for name, fsys := range cases {
+		if got, err := load(fsys); err == nil {
+			t.Errorf("%s: load() = %+v, want error", name, got)
+		}
+	}
+}
+
+// TestEmbeddedMigrations guards the shipped files: consecutive versions, each with a down file
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatalf("load(files) error = %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %04d, want %04d", i, m.Version, i+1)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %04d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+-- 0001 adopts existing installs (CREATE ... IF NOT EXISTS), so its tables hold
+-- production data that predates the migrations. Rolling it back would drop all
+-- of it; refuse instead. Roll back to version 1 at the lowest.
+DO $$
+BEGIN
+	RAISE EXCEPTION 'migrasi 0001_initial_schema adalah skema dasar dan tidak dapat di-rollback';
+END
+$$;
@@ -0,0 +1,148 @@
+-- Baseline schema. Written with IF NOT EXISTS so databases created earlier from
+-- database/create_tables.sql are adopted without changes.
+
+CREATE TABLE IF NOT EXISTS public.users (
+	id uuid NOT NULL,
+	username varchar(50) NOT NULL,
+	"name" varchar(255) NOT NULL,
+	"password" varchar(255) NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT users_pkey PRIMARY KEY (id),
+	CONSTRAINT users_username_unique UNIQUE (username)
+);
+CREATE INDEX IF NOT EXISTS users_username_index ON public.users USING btree (username);
+
+CREATE TABLE IF NOT EXISTS public.user_logs (
+	id uuid NOT NULL,
+	message text NOT NULL,
+	channel varchar(255) NOT NULL,
+	"level" int2 NOT NULL DEFAULT '0'::smallint,
+	level_name varchar(20) NOT NULL,
+	datetime varchar(255) NOT NULL,
+	context text NOT NULL,
+	extra text NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.items (
+	id uuid NOT NULL,
+	code varchar(10) NOT NULL,
+	"name" varchar(255) NOT NULL,
+	qty float NOT NULL,
+	price float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	deleted_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT items_code_unique UNIQUE (code),
+	CONSTRAINT items_pkey PRIMARY KEY (id),
+	CONSTRAINT items_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT items_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.stock_mutations (
+	id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	period varchar(50) NOT NULL,
+	trx_date date NOT NULL,
+	qty float NOT NULL,
+	model_id uuid NOT NULL,
+	model_type varchar(255) NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT stock_mutations_pkey PRIMARY KEY (id),
+	CONSTRAINT stock_mutations_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS public.purchase_headers (
+	id uuid NOT NULL,
+	purchase_invoice_num varchar(255) NOT NULL,
+	purchase_date date NOT NULL,
+	supplier_name varchar(255) NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT purchase_headers_pkey PRIMARY KEY (id),
+	CONSTRAINT purchase_headers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT purchase_headers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+ALTER TABLE public.purchase_headers ADD COLUMN IF NOT EXISTS total_amount float DEFAULT 0 NOT NULL;
+ALTER TABLE public.purchase_headers ADD COLUMN IF NOT EXISTS status varchar(10) DEFAULT 'ACTIVE';
+
+CREATE TABLE IF NOT EXISTS public.purchase_details (
+	id uuid NOT NULL,
+	header_id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	qty float NOT NULL,
+	price_amount float NOT NULL,
+	total_amount float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT purchase_details_pkey PRIMARY KEY (id),
+	CONSTRAINT purchase_details_header_id_foreign FOREIGN KEY (header_id) REFERENCES public.purchase_headers(id) ON DELETE CASCADE,
+	CONSTRAINT purchase_details_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS public.sell_headers (
+	id uuid NOT NULL,
+	sell_invoice_num varchar(255) NOT NULL,
+	sell_date date NOT NULL,
+	customer_name varchar(255) NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT sell_headers_pkey PRIMARY KEY (id),
+	CONSTRAINT sell_headers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT sell_headers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+ALTER TABLE public.sell_headers ADD COLUMN IF NOT EXISTS total_amount float DEFAULT 0 NOT NULL;
+ALTER TABLE public.sell_headers ADD COLUMN IF NOT EXISTS status varchar(10) DEFAULT 'ACTIVE';
+
+CREATE TABLE IF NOT EXISTS public.sell_details (
+	id uuid NOT NULL,
+	header_id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	qty float NOT NULL,
+	price_amount float NOT NULL,
+	total_amount float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT sell_details_pkey PRIMARY KEY (id),
+	CONSTRAINT sell_details_header_id_foreign FOREIGN KEY (header_id) REFERENCES public.sell_headers(id) ON DELETE CASCADE,
+	CONSTRAINT sell_details_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS public.retur_headers (
+	id uuid NOT NULL,
+	retur_invoice_num varchar(255) NOT NULL,
+	retur_date date NOT NULL,
+	supplier_name varchar(255) NOT NULL,
+	total_amount float DEFAULT 0 NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT retur_headers_pkey PRIMARY KEY (id),
+	CONSTRAINT retur_headers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT retur_headers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+ALTER TABLE public.retur_headers ADD COLUMN IF NOT EXISTS status varchar(10) DEFAULT 'ACTIVE';
+
+CREATE TABLE IF NOT EXISTS public.retur_details (
+	id uuid NOT NULL,
+	header_id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	qty float NOT NULL,
+	price_amount float NOT NULL,
+	total_amount float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT retur_details_pkey PRIMARY KEY (id),
+	CONSTRAINT retur_details_header_id_foreign FOREIGN KEY (header_id) REFERENCES public.retur_headers(id) ON DELETE CASCADE,
+	CONSTRAINT retur_details_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.opname_details;
+DROP TABLE IF EXISTS public.opname_headers;
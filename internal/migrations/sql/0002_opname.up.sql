@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS public.opname_headers (
+	id uuid NOT NULL,
+	opname_num varchar(255) NOT NULL,
+	opname_date date NOT NULL,
+	notes text NOT NULL DEFAULT '',
+	status varchar(10) NOT NULL DEFAULT 'DRAFT',
+	posted_at timestamp(0) NULL,
+	posted_by uuid NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT opname_headers_pkey PRIMARY KEY (id),
+	CONSTRAINT opname_headers_opname_num_unique UNIQUE (opname_num),
+	CONSTRAINT opname_headers_posted_by_foreign FOREIGN KEY (posted_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT opname_headers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT opname_headers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.opname_details (
+	id uuid NOT NULL,
+	header_id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	system_qty float NOT NULL,
+	counted_qty float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT opname_details_pkey PRIMARY KEY (id),
+	CONSTRAINT opname_details_header_item_unique UNIQUE (header_id, item_id),
+	CONSTRAINT opname_details_header_id_foreign FOREIGN KEY (header_id) REFERENCES public.opname_headers(id) ON DELETE CASCADE,
+	CONSTRAINT opname_details_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
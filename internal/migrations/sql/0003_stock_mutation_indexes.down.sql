@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.stock_mutations_model_index;
+DROP INDEX IF EXISTS public.stock_mutations_item_id_trx_date_index;
@@ -0,0 +1,3 @@
+-- Kartu stok and ledger reconciliation read mutations per item and date
+CREATE INDEX IF NOT EXISTS stock_mutations_item_id_trx_date_index ON public.stock_mutations USING btree (item_id, trx_date);
+CREATE INDEX IF NOT EXISTS stock_mutations_model_index ON public.stock_mutations USING btree (model_id, model_type);
@@ -0,0 +1,4 @@
+ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_role_foreign;
+ALTER TABLE public.users DROP COLUMN IF EXISTS "role";
+DROP TABLE IF EXISTS public.role_permissions;
+DROP TABLE IF EXISTS public.roles;
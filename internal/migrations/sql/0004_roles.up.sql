@@ -0,0 +1,46 @@
+-- Roles and the permissions granted to each of them
+CREATE TABLE public.roles (
+	code varchar(20) NOT NULL,
+	"name" varchar(50) NOT NULL,
+	CONSTRAINT roles_pkey PRIMARY KEY (code)
+);
+
+INSERT INTO public.roles (code, "name") VALUES
+	('admin', 'Administrator'),
+	('supervisor', 'Supervisor'),
+	('kasir', 'Kasir'),
+	('gudang', 'Gudang');
+
+CREATE TABLE public.role_permissions (
+	"role" varchar(20) NOT NULL,
+	permission varchar(50) NOT NULL,
+	CONSTRAINT role_permissions_pkey PRIMARY KEY ("role", permission),
+	CONSTRAINT role_permissions_role_foreign FOREIGN KEY ("role") REFERENCES public.roles(code) ON DELETE CASCADE
+);
+
+INSERT INTO public.role_permissions ("role", permission) VALUES
+	('admin', 'sales'),
+	('admin', 'purchase'),
+	('admin', 'inventory'),
+	('admin', 'reports'),
+	('admin', 'void'),
+	('admin', 'opname.post'),
+	('admin', 'stock.reconcile'),
+	('admin', 'data.purge'),
+	('admin', 'users.manage'),
+	('supervisor', 'sales'),
+	('supervisor', 'purchase'),
+	('supervisor', 'inventory'),
+	('supervisor', 'reports'),
+	('supervisor', 'void'),
+	('supervisor', 'opname.post'),
+	('supervisor', 'stock.reconcile'),
+	('kasir', 'sales'),
+	('gudang', 'purchase'),
+	('gudang', 'inventory');
+
+ALTER TABLE public.users ADD COLUMN "role" varchar(20) NOT NULL DEFAULT 'kasir';
+ALTER TABLE public.users ADD CONSTRAINT users_role_foreign FOREIGN KEY ("role") REFERENCES public.roles(code);
+
+-- Accounts that existed before roles keep the full access they already had
+UPDATE public.users SET "role" = 'admin';
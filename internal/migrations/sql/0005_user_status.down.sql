@@ -0,0 +1,3 @@
+ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_updated_by_foreign;
+ALTER TABLE public.users DROP COLUMN IF EXISTS updated_by;
+ALTER TABLE public.users DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+-- Users are disabled by setting deleted_at, the row stays for created_by/updated_by references
+ALTER TABLE public.users ADD COLUMN deleted_at timestamp(0) NULL;
+ALTER TABLE public.users ADD COLUMN updated_by uuid NULL;
+ALTER TABLE public.users ADD CONSTRAINT users_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+DELETE FROM public.role_permissions WHERE permission = 'audit.view';
+DROP INDEX IF EXISTS public.user_logs_user_id_index;
+DROP INDEX IF EXISTS public.user_logs_created_at_index;
+ALTER TABLE public.user_logs DROP CONSTRAINT IF EXISTS user_logs_user_id_foreign;
+ALTER TABLE public.user_logs DROP COLUMN IF EXISTS user_id;
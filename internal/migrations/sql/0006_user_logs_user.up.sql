@@ -0,0 +1,9 @@
+-- The audit viewer filters user_logs by user and date
+ALTER TABLE public.user_logs ADD COLUMN user_id uuid NULL;
+ALTER TABLE public.user_logs ADD CONSTRAINT user_logs_user_id_foreign FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE SET NULL;
+CREATE INDEX user_logs_created_at_index ON public.user_logs USING btree (created_at);
+CREATE INDEX user_logs_user_id_index ON public.user_logs USING btree (user_id);
+
+INSERT INTO public.role_permissions ("role", permission) VALUES
+	('admin', 'audit.view'),
+	('supervisor', 'audit.view');
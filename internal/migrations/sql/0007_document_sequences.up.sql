@@ -0,0 +1,8 @@
+-- Per-type, per-period counters used by the document numbering service
+CREATE TABLE public.document_sequences (
+	doc_type varchar(20) NOT NULL,
+	period varchar(8) NOT NULL,
+	last_seq int NOT NULL,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT document_sequences_pkey PRIMARY KEY (doc_type, period)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.purchase_headers_supplier_invoice_num_unique;
+DROP INDEX IF EXISTS public.retur_headers_retur_invoice_num_unique;
+DROP INDEX IF EXISTS public.sell_headers_sell_invoice_num_unique;
@@ -0,0 +1,29 @@
+-- Nota numbers were only checked for duplicates in the UI. Existing duplicates
+-- keep the oldest row unchanged and get a " (2)", " (3)", ... suffix so the
+-- unique indexes can be created.
+
+UPDATE public.sell_headers h SET sell_invoice_num = h.sell_invoice_num || ' (' || d.rn || ')'
+FROM (
+	SELECT id, ROW_NUMBER() OVER (PARTITION BY sell_invoice_num ORDER BY created_at, id) AS rn
+	FROM public.sell_headers
+) d
+WHERE d.id = h.id AND d.rn > 1;
+
+UPDATE public.retur_headers h SET retur_invoice_num = h.retur_invoice_num || ' (' || d.rn || ')'
+FROM (
+	SELECT id, ROW_NUMBER() OVER (PARTITION BY retur_invoice_num ORDER BY created_at, id) AS rn
+	FROM public.retur_headers
+) d
+WHERE d.id = h.id AND d.rn > 1;
+
+-- Nota pembelian dibuat oleh supplier, jadi nomor yang sama boleh dipakai supplier lain
+UPDATE public.purchase_headers h SET purchase_invoice_num = h.purchase_invoice_num || ' (' || d.rn || ')'
+FROM (
+	SELECT id, ROW_NUMBER() OVER (PARTITION BY supplier_name, purchase_invoice_num ORDER BY created_at, id) AS rn
+	FROM public.purchase_headers
+) d
+WHERE d.id = h.id AND d.rn > 1;
+
+CREATE UNIQUE INDEX sell_headers_sell_invoice_num_unique ON public.sell_headers USING btree (sell_invoice_num);
+CREATE UNIQUE INDEX retur_headers_retur_invoice_num_unique ON public.retur_headers USING btree (retur_invoice_num);
+CREATE UNIQUE INDEX purchase_headers_supplier_invoice_num_unique ON public.purchase_headers USING btree (supplier_name, purchase_invoice_num);
@@ -0,0 +1 @@
+ALTER TABLE public.items DROP COLUMN IF EXISTS allow_negative_stock;
@@ -0,0 +1,2 @@
+-- Per-item policy: barang yang boleh dijual melebihi stok (mis. barang pesanan)
+ALTER TABLE public.items ADD allow_negative_stock bool DEFAULT false NOT NULL;
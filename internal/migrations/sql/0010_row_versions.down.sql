@@ -0,0 +1,4 @@
+ALTER TABLE public.retur_headers DROP COLUMN IF EXISTS version;
+ALTER TABLE public.sell_headers DROP COLUMN IF EXISTS version;
+ALTER TABLE public.purchase_headers DROP COLUMN IF EXISTS version;
+ALTER TABLE public.items DROP COLUMN IF EXISTS version;
@@ -0,0 +1,6 @@
+-- Row versions for optimistic locking: every update bumps version and edits
+-- are only applied when the version the user loaded is still current
+ALTER TABLE public.items ADD version int DEFAULT 1 NOT NULL;
+ALTER TABLE public.purchase_headers ADD version int DEFAULT 1 NOT NULL;
+ALTER TABLE public.sell_headers ADD version int DEFAULT 1 NOT NULL;
+ALTER TABLE public.retur_headers ADD version int DEFAULT 1 NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE public.retur_headers DROP COLUMN IF EXISTS supplier_id;
+ALTER TABLE public.purchase_headers DROP COLUMN IF EXISTS supplier_id;
+DROP TABLE IF EXISTS public.suppliers;
@@ -0,0 +1,61 @@
+-- gen_random_uuid() is built in from PostgreSQL 13; older servers get it from
+-- pgcrypto. This is the first migration that generates ids in SQL.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+-- Supplier master data; purchase and retur headers keep supplier_name as the
+-- name printed on the nota and point to the supplier record through supplier_id
+CREATE TABLE public.suppliers (
+	id uuid NOT NULL,
+	code varchar(50) NOT NULL,
+	"name" varchar(255) NOT NULL,
+	address text DEFAULT '' NOT NULL,
+	phone varchar(50) DEFAULT '' NOT NULL,
+	npwp varchar(30) DEFAULT '' NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	deleted_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	version int DEFAULT 1 NOT NULL,
+	CONSTRAINT suppliers_pkey PRIMARY KEY (id),
+	CONSTRAINT suppliers_code_unique UNIQUE (code),
+	CONSTRAINT suppliers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT suppliers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+ALTER TABLE public.purchase_headers ADD supplier_id uuid NULL;
+ALTER TABLE public.purchase_headers ADD CONSTRAINT purchase_headers_supplier_id_foreign FOREIGN KEY (supplier_id) REFERENCES public.suppliers(id) ON DELETE SET NULL;
+CREATE INDEX purchase_headers_supplier_id_index ON public.purchase_headers USING btree (supplier_id);
+
+ALTER TABLE public.retur_headers ADD supplier_id uuid NULL;
+ALTER TABLE public.retur_headers ADD CONSTRAINT retur_headers_supplier_id_foreign FOREIGN KEY (supplier_id) REFERENCES public.suppliers(id) ON DELETE SET NULL;
+CREATE INDEX retur_headers_supplier_id_index ON public.retur_headers USING btree (supplier_id);
+
+-- One supplier per normalised name (lowercase, punctuation removed, spaces
+-- collapsed), so "PT Maju" and "pt. maju" end up as the same record. The most
+-- used spelling becomes the supplier name.
+INSERT INTO public.suppliers (id, code, "name", created_at)
+SELECT gen_random_uuid(), 'SUP' || lpad(ROW_NUMBER() OVER (ORDER BY name_key)::text, 4, '0'), supplier_name, CURRENT_TIMESTAMP
+FROM (
+	SELECT DISTINCT ON (name_key) name_key, supplier_name
+	FROM (
+		SELECT btrim(regexp_replace(lower(supplier_name), '[^a-z0-9]+', ' ', 'g')) AS name_key,
+			btrim(supplier_name) AS supplier_name, COUNT(*) AS used
+		FROM (
+			SELECT supplier_name FROM public.purchase_headers
+			UNION ALL
+			SELECT supplier_name FROM public.retur_headers
+		) n
+		GROUP BY 1, 2
+	) spellings
+	WHERE name_key <> ''
+	ORDER BY name_key, used DESC, supplier_name
+) canonical;
+
+UPDATE public.purchase_headers h SET supplier_id = s.id
+FROM public.suppliers s
+WHERE btrim(regexp_replace(lower(h.supplier_name), '[^a-z0-9]+', ' ', 'g')) = btrim(regexp_replace(lower(s."name"), '[^a-z0-9]+', ' ', 'g'));
+
+UPDATE public.retur_headers h SET supplier_id = s.id
+FROM public.suppliers s
+WHERE btrim(regexp_replace(lower(h.supplier_name), '[^a-z0-9]+', ' ', 'g')) = btrim(regexp_replace(lower(s."name"), '[^a-z0-9]+', ' ', 'g'));
@@ -0,0 +1,2 @@
+ALTER TABLE public.sell_headers DROP COLUMN IF EXISTS customer_id;
+DROP TABLE IF EXISTS public.customers;
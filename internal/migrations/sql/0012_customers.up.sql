@@ -0,0 +1,55 @@
+-- Customer master data; sell_headers keeps customer_name as printed on the nota
+-- and points to the customer record through customer_id
+CREATE TABLE public.customers (
+	id uuid NOT NULL,
+	code varchar(50) NOT NULL,
+	"name" varchar(255) NOT NULL,
+	address text DEFAULT '' NOT NULL,
+	phone varchar(50) DEFAULT '' NOT NULL,
+	email varchar(255) DEFAULT '' NOT NULL,
+	price_tier varchar(20) DEFAULT 'umum' NOT NULL,
+	credit_limit numeric(15, 2) DEFAULT 0 NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	deleted_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	version int DEFAULT 1 NOT NULL,
+	CONSTRAINT customers_pkey PRIMARY KEY (id),
+	CONSTRAINT customers_code_unique UNIQUE (code),
+	CONSTRAINT customers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT customers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+-- Walk-in customer, preselected in the penjualan dialog
+INSERT INTO public.customers (id, code, "name", created_at)
+VALUES (gen_random_uuid(), 'UMUM', 'Umum', CURRENT_TIMESTAMP);
+
+ALTER TABLE public.sell_headers ADD customer_id uuid NULL;
+ALTER TABLE public.sell_headers ADD CONSTRAINT sell_headers_customer_id_foreign FOREIGN KEY (customer_id) REFERENCES public.customers(id) ON DELETE SET NULL;
+CREATE INDEX sell_headers_customer_id_index ON public.sell_headers USING btree (customer_id);
+
+-- One customer per normalised name (lowercase, punctuation removed, spaces
+-- collapsed); the most used spelling becomes the customer name. Blank names
+-- and "umum" belong to the walk-in customer.
+INSERT INTO public.customers (id, code, "name", created_at)
+SELECT gen_random_uuid(), 'CUS' || lpad(ROW_NUMBER() OVER (ORDER BY name_key)::text, 4, '0'), customer_name, CURRENT_TIMESTAMP
+FROM (
+	SELECT DISTINCT ON (name_key) name_key, customer_name
+	FROM (
+		SELECT btrim(regexp_replace(lower(customer_name), '[^a-z0-9]+', ' ', 'g')) AS name_key,
+			btrim(customer_name) AS customer_name, COUNT(*) AS used
+		FROM public.sell_headers
+		GROUP BY 1, 2
+	) spellings
+	WHERE name_key NOT IN ('', 'umum')
+	ORDER BY name_key, used DESC, customer_name
+) canonical;
+
+UPDATE public.sell_headers h SET customer_id = c.id
+FROM public.customers c
+WHERE c.code <> 'UMUM'
+AND btrim(regexp_replace(lower(h.customer_name), '[^a-z0-9]+', ' ', 'g')) = btrim(regexp_replace(lower(c."name"), '[^a-z0-9]+', ' ', 'g'));
+
+UPDATE public.sell_headers SET customer_id = (SELECT id FROM public.customers WHERE code = 'UMUM')
+WHERE customer_id IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS public.purchase_payments;
+ALTER TABLE public.retur_headers DROP COLUMN IF EXISTS purchase_id;
+ALTER TABLE public.purchase_headers DROP COLUMN IF EXISTS due_date;
+ALTER TABLE public.purchase_headers DROP COLUMN IF EXISTS payment_term_days;
@@ -0,0 +1,38 @@
+-- Accounts payable: payment term and due date on purchases, (partial) payments
+-- per purchase, and returs linked to the purchase whose payable they reduce
+ALTER TABLE public.purchase_headers ADD payment_term_days int DEFAULT 0 NOT NULL;
+ALTER TABLE public.purchase_headers ADD due_date date NULL;
+UPDATE public.purchase_headers SET due_date = purchase_date::date;
+ALTER TABLE public.purchase_headers ALTER COLUMN due_date SET NOT NULL;
+
+ALTER TABLE public.retur_headers ADD purchase_id uuid NULL;
+ALTER TABLE public.retur_headers ADD CONSTRAINT retur_headers_purchase_id_foreign FOREIGN KEY (purchase_id) REFERENCES public.purchase_headers(id) ON DELETE SET NULL;
+CREATE INDEX retur_headers_purchase_id_index ON public.retur_headers USING btree (purchase_id);
+
+CREATE TABLE public.purchase_payments (
+	id uuid NOT NULL,
+	purchase_id uuid NOT NULL,
+	payment_date date NOT NULL,
+	amount numeric(15, 2) NOT NULL,
+	method varchar(50) DEFAULT '' NOT NULL,
+	reference varchar(100) DEFAULT '' NOT NULL,
+	note text DEFAULT '' NOT NULL,
+	status varchar(10) DEFAULT 'ACTIVE' NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT purchase_payments_pkey PRIMARY KEY (id),
+	CONSTRAINT purchase_payments_amount_positive CHECK (amount > 0),
+	CONSTRAINT purchase_payments_purchase_id_foreign FOREIGN KEY (purchase_id) REFERENCES public.purchase_headers(id) ON DELETE CASCADE,
+	CONSTRAINT purchase_payments_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT purchase_payments_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+CREATE INDEX purchase_payments_purchase_id_index ON public.purchase_payments USING btree (purchase_id);
+
+-- Until now every purchase was paid in cash, so existing notas are recorded as
+-- settled instead of showing up as outstanding payables
+INSERT INTO public.purchase_payments (id, purchase_id, payment_date, amount, method, note, created_at)
+SELECT gen_random_uuid(), id, purchase_date::date, total_amount, 'Tunai', 'Saldo awal (pelunasan otomatis migrasi)', CURRENT_TIMESTAMP
+FROM public.purchase_headers
+WHERE status = 'ACTIVE' AND total_amount > 0;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.sell_payments;
+DROP INDEX IF EXISTS public.sell_headers_payment_method_index;
+ALTER TABLE public.sell_headers DROP COLUMN IF EXISTS due_date;
+ALTER TABLE public.sell_headers DROP COLUMN IF EXISTS payment_term_days;
+ALTER TABLE public.sell_headers DROP COLUMN IF EXISTS payment_method;
@@ -0,0 +1,30 @@
+-- Accounts receivable: payment method on sales, payment term and due date for
+-- credit sales, and (partial) payments per credit sale. Existing sales were
+-- all paid in cash.
+ALTER TABLE public.sell_headers ADD payment_method varchar(20) DEFAULT 'cash' NOT NULL;
+ALTER TABLE public.sell_headers ADD payment_term_days int DEFAULT 0 NOT NULL;
+ALTER TABLE public.sell_headers ADD due_date date NULL;
+UPDATE public.sell_headers SET due_date = sell_date::date;
+ALTER TABLE public.sell_headers ALTER COLUMN due_date SET NOT NULL;
+CREATE INDEX sell_headers_payment_method_index ON public.sell_headers USING btree (payment_method, customer_id);
+
+CREATE TABLE public.sell_payments (
+	id uuid NOT NULL,
+	sell_id uuid NOT NULL,
+	payment_date date NOT NULL,
+	amount numeric(15, 2) NOT NULL,
+	method varchar(50) DEFAULT '' NOT NULL,
+	reference varchar(100) DEFAULT '' NOT NULL,
+	note text DEFAULT '' NOT NULL,
+	status varchar(10) DEFAULT 'ACTIVE' NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	CONSTRAINT sell_payments_pkey PRIMARY KEY (id),
+	CONSTRAINT sell_payments_amount_positive CHECK (amount > 0),
+	CONSTRAINT sell_payments_sell_id_foreign FOREIGN KEY (sell_id) REFERENCES public.sell_headers(id) ON DELETE CASCADE,
+	CONSTRAINT sell_payments_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT sell_payments_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+CREATE INDEX sell_payments_sell_id_index ON public.sell_payments USING btree (sell_id);
@@ -0,0 +1,4 @@
+DELETE FROM public.stock_mutations WHERE model_type IN ('sell_retur', 'void_sell_retur');
+DELETE FROM public.document_sequences WHERE doc_type = 'sell_retur';
+DROP TABLE IF EXISTS public.sell_retur_details;
+DROP TABLE IF EXISTS public.sell_retur_headers;
@@ -0,0 +1,43 @@
+-- Retur penjualan: goods a customer brings back against an earlier nota
+-- penjualan. The return adds the goods back to stock and reduces the
+-- receivable (or the refund) of the linked sale. Returns follow their sale
+-- when old sales are purged.
+CREATE TABLE public.sell_retur_headers (
+	id uuid NOT NULL,
+	retur_invoice_num varchar(255) NOT NULL,
+	retur_date date NOT NULL,
+	sell_id uuid NOT NULL,
+	customer_name varchar(255) NOT NULL,
+	customer_id uuid NULL,
+	total_amount float DEFAULT 0 NOT NULL,
+	status varchar(10) DEFAULT 'ACTIVE' NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	created_by uuid NULL,
+	updated_by uuid NULL,
+	version int DEFAULT 1 NOT NULL,
+	CONSTRAINT sell_retur_headers_pkey PRIMARY KEY (id),
+	CONSTRAINT sell_retur_headers_sell_id_foreign FOREIGN KEY (sell_id) REFERENCES public.sell_headers(id) ON DELETE CASCADE,
+	CONSTRAINT sell_retur_headers_customer_id_foreign FOREIGN KEY (customer_id) REFERENCES public.customers(id) ON DELETE SET NULL,
+	CONSTRAINT sell_retur_headers_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL,
+	CONSTRAINT sell_retur_headers_updated_by_foreign FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX sell_retur_headers_retur_invoice_num_unique ON public.sell_retur_headers USING btree (retur_invoice_num);
+CREATE INDEX sell_retur_headers_sell_id_index ON public.sell_retur_headers USING btree (sell_id);
+CREATE INDEX sell_retur_headers_retur_date_index ON public.sell_retur_headers USING btree (retur_date);
+
+CREATE TABLE public.sell_retur_details (
+	id uuid NOT NULL,
+	header_id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	qty float NOT NULL,
+	price_amount float NOT NULL,
+	total_amount float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT sell_retur_details_pkey PRIMARY KEY (id),
+	CONSTRAINT sell_retur_details_qty_positive CHECK (qty > 0),
+	CONSTRAINT sell_retur_details_header_id_foreign FOREIGN KEY (header_id) REFERENCES public.sell_retur_headers(id) ON DELETE CASCADE,
+	CONSTRAINT sell_retur_details_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
+CREATE INDEX sell_retur_details_header_id_index ON public.sell_retur_details USING btree (header_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.item_cost_history;
+ALTER TABLE public.items DROP COLUMN IF EXISTS avg_cost;
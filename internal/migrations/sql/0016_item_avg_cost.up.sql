@@ -0,0 +1,37 @@
+-- Moving-average cost (HPP) per item, updated by purchases, returs and voids,
+-- with a history row for every change
+ALTER TABLE public.items ADD avg_cost float DEFAULT 0 NOT NULL;
+
+CREATE TABLE public.item_cost_history (
+	id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	trx_date date NOT NULL,
+	model_id uuid NULL,
+	model_type varchar(255) NOT NULL,
+	qty float NOT NULL,
+	unit_cost float NOT NULL,
+	qty_before float NOT NULL,
+	cost_before float NOT NULL,
+	cost_after float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT item_cost_history_pkey PRIMARY KEY (id),
+	CONSTRAINT item_cost_history_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
+CREATE INDEX item_cost_history_item_id_index ON public.item_cost_history USING btree (item_id, created_at);
+
+-- Opening cost: weighted average of the active purchases so far
+UPDATE public.items i SET avg_cost = c.cost
+FROM (
+	SELECT pd.item_id, SUM(pd.total_amount) / SUM(pd.qty) AS cost
+	FROM public.purchase_details pd
+	JOIN public.purchase_headers ph ON ph.id = pd.header_id
+	WHERE ph.status = 'ACTIVE'
+	GROUP BY pd.item_id
+	HAVING SUM(pd.qty) > 0
+) c
+WHERE c.item_id = i.id;
+
+INSERT INTO public.item_cost_history (id, item_id, trx_date, model_type, qty, unit_cost, qty_before, cost_before, cost_after, created_at)
+SELECT gen_random_uuid(), id, CURRENT_DATE, 'opening_cost', qty, avg_cost, 0, 0, avg_cost, CURRENT_TIMESTAMP
+FROM public.items
+WHERE deleted_at IS NULL AND avg_cost > 0;
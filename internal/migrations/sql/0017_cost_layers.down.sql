@@ -0,0 +1,3 @@
+ALTER TABLE public.sell_details DROP COLUMN IF EXISTS cogs_amount;
+DROP TABLE IF EXISTS public.sell_cost_consumptions;
+DROP TABLE IF EXISTS public.cost_layers;
@@ -0,0 +1,44 @@
+-- Cost layers for FIFO valuation: purchases open layers, sales consume them
+-- oldest first and remember which layers every sell_details row used
+CREATE TABLE public.cost_layers (
+	id uuid NOT NULL,
+	item_id uuid NOT NULL,
+	trx_date date NOT NULL,
+	model_id uuid NULL,
+	model_type varchar(255) NOT NULL,
+	qty float NOT NULL,
+	remaining_qty float NOT NULL,
+	unit_cost float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT cost_layers_pkey PRIMARY KEY (id),
+	CONSTRAINT cost_layers_item_id_foreign FOREIGN KEY (item_id) REFERENCES public.items(id) ON DELETE CASCADE
+);
+CREATE INDEX cost_layers_open_index ON public.cost_layers USING btree (item_id, trx_date, created_at) WHERE remaining_qty > 0;
+CREATE INDEX cost_layers_model_id_index ON public.cost_layers USING btree (model_id);
+
+CREATE TABLE public.sell_cost_consumptions (
+	id uuid NOT NULL,
+	sell_detail_id uuid NOT NULL,
+	layer_id uuid NOT NULL,
+	qty float NOT NULL,
+	unit_cost float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT sell_cost_consumptions_pkey PRIMARY KEY (id),
+	CONSTRAINT sell_cost_consumptions_sell_detail_id_foreign FOREIGN KEY (sell_detail_id) REFERENCES public.sell_details(id) ON DELETE CASCADE,
+	CONSTRAINT sell_cost_consumptions_layer_id_foreign FOREIGN KEY (layer_id) REFERENCES public.cost_layers(id) ON DELETE CASCADE
+);
+CREATE INDEX sell_cost_consumptions_sell_detail_id_index ON public.sell_cost_consumptions USING btree (sell_detail_id);
+
+-- HPP of every sale line, whichever method was active when it was sold
+ALTER TABLE public.sell_details ADD cogs_amount float DEFAULT 0 NOT NULL;
+
+-- Older sales are valued at the current average cost, the best estimate available
+UPDATE public.sell_details d SET cogs_amount = d.qty * i.avg_cost
+FROM public.items i
+WHERE i.id = d.item_id;
+
+-- The stock on hand becomes one opening layer per item at its average cost
+INSERT INTO public.cost_layers (id, item_id, trx_date, model_type, qty, remaining_qty, unit_cost, created_at)
+SELECT gen_random_uuid(), id, CURRENT_DATE, 'opening_cost', qty, qty, avg_cost, CURRENT_TIMESTAMP
+FROM public.items
+WHERE deleted_at IS NULL AND qty > 0;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.sell_cost_consumptions_layer_id_index;
+DROP TABLE IF EXISTS public.cost_consumptions;
+ALTER TABLE public.cost_layers DROP COLUMN IF EXISTS closed_at;
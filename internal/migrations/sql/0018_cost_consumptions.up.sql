@@ -0,0 +1,29 @@
+-- A layer closed by a void or edit keeps its consumptions pointing at it only
+-- when no other layer could take its place; closed_at tells restores to open a
+-- replacement layer instead of reviving the closed one
+ALTER TABLE public.cost_layers ADD closed_at timestamp(0) NULL;
+
+-- Layers already closed: those of voided notas and those replaced by a later edit
+UPDATE public.cost_layers l SET closed_at = CURRENT_TIMESTAMP
+WHERE (l.model_type = 'purchase' AND EXISTS (SELECT 1 FROM public.purchase_headers h WHERE h.id = l.model_id AND h.status = 'VOID'))
+   OR (l.model_type = 'sell_retur' AND EXISTS (SELECT 1 FROM public.sell_retur_headers h WHERE h.id = l.model_id AND h.status = 'VOID'))
+   OR (l.model_type IN ('purchase', 'sell_retur') AND l.created_at < (
+		SELECT MAX(l2.created_at) FROM public.cost_layers l2
+		WHERE l2.model_id = l.model_id AND l2.model_type = l.model_type));
+
+-- Layers used by documents other than sales (retur pembelian), so an edit or
+-- void puts the units back on the layers they came from
+CREATE TABLE public.cost_consumptions (
+	id uuid NOT NULL,
+	layer_id uuid NOT NULL,
+	model_id uuid NOT NULL,
+	model_type varchar(255) NOT NULL,
+	qty float NOT NULL,
+	unit_cost float NOT NULL,
+	created_at timestamp(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT cost_consumptions_pkey PRIMARY KEY (id),
+	CONSTRAINT cost_consumptions_layer_id_foreign FOREIGN KEY (layer_id) REFERENCES public.cost_layers(id) ON DELETE CASCADE
+);
+CREATE INDEX cost_consumptions_model_id_index ON public.cost_consumptions USING btree (model_id);
+CREATE INDEX cost_consumptions_layer_id_index ON public.cost_consumptions USING btree (layer_id);
+CREATE INDEX sell_cost_consumptions_layer_id_index ON public.sell_cost_consumptions USING btree (layer_id);
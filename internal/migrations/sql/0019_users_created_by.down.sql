@@ -0,0 +1,2 @@
+ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_created_by_foreign;
+ALTER TABLE public.users DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,3 @@
+-- Who created each account, like updated_by records who changed it last
+ALTER TABLE public.users ADD COLUMN created_by uuid NULL;
+ALTER TABLE public.users ADD CONSTRAINT users_created_by_foreign FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL;
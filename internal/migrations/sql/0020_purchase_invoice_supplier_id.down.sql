@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.purchase_headers_supplier_invoice_num_unique;
+CREATE UNIQUE INDEX purchase_headers_supplier_invoice_num_unique ON public.purchase_headers USING btree (supplier_name, purchase_invoice_num);
@@ -0,0 +1,16 @@
+-- The nota pembelian number is unique per supplier record instead of per
+-- free-text supplier name, so "PT Maju" and "pt. maju" no longer count as two
+-- suppliers. Notas without a supplier record fall back to the name with case,
+-- spaces and punctuation removed.
+UPDATE public.purchase_headers h SET purchase_invoice_num = h.purchase_invoice_num || ' (' || d.rn || ')'
+FROM (
+	SELECT id, ROW_NUMBER() OVER (
+		PARTITION BY COALESCE(supplier_id::text, regexp_replace(lower(supplier_name), '[^a-z0-9]', '', 'g')), purchase_invoice_num
+		ORDER BY created_at, id) AS rn
+	FROM public.purchase_headers
+) d
+WHERE d.id = h.id AND d.rn > 1;
+
+DROP INDEX IF EXISTS public.purchase_headers_supplier_invoice_num_unique;
+CREATE UNIQUE INDEX purchase_headers_supplier_invoice_num_unique ON public.purchase_headers USING btree (
+	(COALESCE(supplier_id::text, regexp_replace(lower(supplier_name), '[^a-z0-9]', '', 'g'))), purchase_invoice_num);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.cost_layers_backlog_index;
@@ -0,0 +1,3 @@
+-- Backlog layers hold units sold below zero stock (negative remaining_qty);
+-- every receipt looks them up to settle them before opening its own layer
+CREATE INDEX IF NOT EXISTS cost_layers_backlog_index ON public.cost_layers USING btree (item_id, trx_date, created_at) WHERE remaining_qty < 0;
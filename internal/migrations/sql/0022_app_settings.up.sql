@@ -0,0 +1,8 @@
+-- Settings every workstation must agree on, such as the costing method; they
+-- live in the shared database instead of each PC's config.toml
+CREATE TABLE public.app_settings (
+	"key" varchar(100) NOT NULL,
+	value varchar(255) NOT NULL,
+	updated_at timestamp(0) NULL,
+	CONSTRAINT app_settings_pkey PRIMARY KEY ("key")
+);
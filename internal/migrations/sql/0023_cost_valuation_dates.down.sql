@@ -0,0 +1 @@
+ALTER TABLE public.cost_consumptions DROP COLUMN IF EXISTS trx_date;
@@ -0,0 +1,24 @@
+-- Consumptions carry the date of the document that used the layer, so the
+-- FIFO valuation can tell which units had left a layer on a given day
+ALTER TABLE public.cost_consumptions ADD trx_date date NULL;
+
+UPDATE public.cost_consumptions c SET trx_date = h.retur_date
+FROM public.retur_headers h
+WHERE c.model_type = 'retur' AND h.id = c.model_id;
+
+UPDATE public.cost_consumptions SET trx_date = created_at::date WHERE trx_date IS NULL;
+
+ALTER TABLE public.cost_consumptions ALTER COLUMN trx_date SET NOT NULL;
+
+-- The opening layers of 0017 hold the stock on hand when costing was
+-- introduced, so they date from the last stock movement before that instead
+-- of the day the migration ran
+UPDATE public.cost_layers l SET trx_date = m.trx_date
+FROM (
+	SELECT l2.id, MAX(sm.trx_date) AS trx_date
+	FROM public.cost_layers l2
+	JOIN public.stock_mutations sm ON sm.item_id = l2.item_id AND sm.created_at <= l2.created_at
+	WHERE l2.model_type = 'opening_cost' AND l2.model_id IS NULL
+	GROUP BY l2.id
+) m
+WHERE l.id = m.id AND m.trx_date IS NOT NULL;
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Aging buckets, by days past the due date
+const (
+	AgingCurrent = iota // belum jatuh tempo
+	Aging1To30
+	Aging31To60
+	Aging61To90
+	AgingOver90
+	agingBucketCount
+)
+
+// AgingBucketLabels are the column titles of the aging reports, indexed by bucket
+var AgingBucketLabels = [agingBucketCount]string{"Belum Jatuh Tempo", "1-30 Hari", "31-60 Hari", "61-90 Hari", "> 90 Hari"}
+
+// DaysOverdue returns how many whole days dueDate lies before asOf, or 0 when not yet due
+func DaysOverdue(dueDate, asOf time.Time) int {
+	due := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.After(due) {
+		return 0
+	}
+	return int(day.Sub(due).Hours() / 24)
+}
+
+// AgingBucket returns the bucket of a balance that is daysOverdue days late
+func AgingBucket(daysOverdue int) int {
+	switch {
+	case daysOverdue <= 0:
+		return AgingCurrent
+	case daysOverdue <= 30:
+		return Aging1To30
+	case daysOverdue <= 60:
+		return Aging31To60
+	case daysOverdue <= 90:
+		return Aging61To90
+	}
+	return AgingOver90
+}
+
+// AgingRow is the outstanding balance of one supplier or customer split by age
+type AgingRow struct {
+	PartyID   *uuid.UUID
+	PartyName string
+	Buckets   [agingBucketCount]float64
+	Total     float64
+}
+
+// Add puts balance into the bucket for dueDate as of asOf
+func (a *AgingRow) Add(balance float64, dueDate, asOf time.Time) {
+	a.Buckets[AgingBucket(DaysOverdue(dueDate, asOf))] += balance
+	a.Total += balance
+}
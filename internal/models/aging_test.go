@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAgingRowAddBucketBoundaries(t *testing.T) {
+	asOf := time.Date(2024, time.June, 30, 15, 0, 0, 0, time.Local)
+
+	// Days late -> bucket; the edges of every bucket are checked
+	edges := map[int]int{
+		-10: AgingCurrent,
+		0:   AgingCurrent,
+		1:   Aging1To30,
+		30:  Aging1To30,
+		31:  Aging31To60,
+		60:  Aging31To60,
+		61:  Aging61To90,
+		90:  Aging61To90,
+		91:  AgingOver90,
+		400: AgingOver90,
+	}
+
+	for days, bucket := range edges {
+		var row AgingRow
+		row.Add(1000, asOf.AddDate(0, 0, -days), asOf)
+
+		var want [agingBucketCount]float64
+		want[bucket] = 1000
+		if row.Buckets != want || row.Total != 1000 {
+			t.Errorf("%d days late: Buckets = %v Total = %v, want %v Total = 1000", days, row.Buckets, row.Total, want)
+		}
+	}
+}
+
+func TestAgingRowAddIgnoresTimeOfDay(t *testing.T) {
+	asOf := time.Date(2024, time.June, 30, 8, 0, 0, 0, time.Local)
+
+	var row AgingRow
+	row.Add(500, time.Date(2024, time.June, 30, 23, 59, 0, 0, time.Local), asOf)
+	if row.Buckets[AgingCurrent] != 500 {
+		t.Errorf("due later the same day: Buckets = %v, want current", row.Buckets)
+	}
+}
+
+func TestAgingRowAddAccumulates(t *testing.T) {
+	asOf := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.Local)
+
+	var row AgingRow
+	row.Add(1000, asOf.AddDate(0, 0, -5), asOf)
+	row.Add(250.5, asOf.AddDate(0, 0, -20), asOf)
+	row.Add(400, asOf.AddDate(0, 0, -100), asOf)
+	row.Add(-150, asOf, asOf)
+
+	want := [agingBucketCount]float64{-150, 1250.5, 0, 0, 400}
+	for i := range want {
+		if math.Abs(row.Buckets[i]-want[i]) > 1e-9 {
+			t.Errorf("Buckets[%d] = %v, want %v", i, row.Buckets[i], want[i])
+		}
+	}
+	if math.Abs(row.Total-1500.5) > 1e-9 {
+		t.Errorf("Total = %v, want 1500.5", row.Total)
+	}
+}
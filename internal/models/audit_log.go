@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLog struct {
+	ID        uuid.UUID  `db:"id"`
+	Message   string     `db:"message"`
+	Channel   string     `db:"channel"`
+	Level     int16      `db:"level"`
+	LevelName string     `db:"level_name"`
+	Context   string     `db:"context"`
+	Extra     string     `db:"extra"`
+	UserID    *uuid.UUID `db:"user_id"`
+	Username  *string    `db:"username"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// AuditLogFilter narrows the audit viewer, zero values mean no filter
+type AuditLogFilter struct {
+	UserID    *uuid.UUID
+	Channel   string
+	StartDate time.Time
+	EndDate   time.Time
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCustomerCode is the walk-in ("Umum") customer created by the migrations
+const DefaultCustomerCode = "UMUM"
+
+// Price tiers a customer can be assigned
+const (
+	PriceTierUmum   = "umum"
+	PriceTierMember = "member"
+	PriceTierGrosir = "grosir"
+)
+
+// PriceTiers lists every tier in the order shown in the customer dialog
+var PriceTiers = []string{PriceTierUmum, PriceTierMember, PriceTierGrosir}
+
+type Customer struct {
+	ID          uuid.UUID  `db:"id"`
+	Code        string     `db:"code"`
+	Name        string     `db:"name"`
+	Address     string     `db:"address"`
+	Phone       string     `db:"phone"`
+	Email       string     `db:"email"`
+	PriceTier   string     `db:"price_tier"`
+	CreditLimit float64    `db:"credit_limit"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+	CreatedBy   *uuid.UUID `db:"created_by"`
+	UpdatedBy   *uuid.UUID `db:"updated_by"`
+	Version     int        `db:"version"`
+}
+
+// IsDefault reports whether this is the walk-in customer
+func (c Customer) IsDefault() bool {
+	return c.Code == DefaultCustomerCode
+}
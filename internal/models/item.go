@@ -6,14 +6,32 @@ import (
 )
 
 type Item struct {
-	ID        uuid.UUID  `db:"id"`
-	Code      string     `db:"code"`
-	Name      string     `db:"name"`
-	Qty       float64    `db:"qty"`
-	Price     float64    `db:"price"`
-	CreatedAt time.Time  `db:"created_at"`
-	UpdatedAt *time.Time `db:"updated_at"`
-	DeletedAt *time.Time `db:"deleted_at"`
-	CreatedBy *uuid.UUID `db:"created_by"`
-	UpdatedBy *uuid.UUID `db:"updated_by"`
+	ID                 uuid.UUID  `db:"id"`
+	Code               string     `db:"code"`
+	Name               string     `db:"name"`
+	Qty                float64    `db:"qty"`
+	Price              float64    `db:"price"`
+	AvgCost            float64    `db:"avg_cost"`
+	AllowNegativeStock bool       `db:"allow_negative_stock"`
+	Version            int        `db:"version"`
+	CreatedAt          time.Time  `db:"created_at"`
+	UpdatedAt          *time.Time `db:"updated_at"`
+	DeletedAt          *time.Time `db:"deleted_at"`
+	CreatedBy          *uuid.UUID `db:"created_by"`
+	UpdatedBy          *uuid.UUID `db:"updated_by"`
+}
+
+// ItemCostHistory records one change of the moving-average cost (HPP) of an item
+type ItemCostHistory struct {
+	ID         uuid.UUID  `db:"id"`
+	ItemID     uuid.UUID  `db:"item_id"`
+	TrxDate    time.Time  `db:"trx_date"`
+	ModelID    *uuid.UUID `db:"model_id"`
+	ModelType  string     `db:"model_type"`
+	Qty        float64    `db:"qty"`
+	UnitCost   float64    `db:"unit_cost"`
+	QtyBefore  float64    `db:"qty_before"`
+	CostBefore float64    `db:"cost_before"`
+	CostAfter  float64    `db:"cost_after"`
+	CreatedAt  time.Time  `db:"created_at"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OpnameHeader struct {
+	ID         uuid.UUID  `db:"id"`
+	OpnameNum  string     `db:"opname_num"`
+	OpnameDate time.Time  `db:"opname_date"`
+	Notes      string     `db:"notes"`
+	Status     string     `db:"status"`
+	PostedAt   *time.Time `db:"posted_at"`
+	PostedBy   *uuid.UUID `db:"posted_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  *time.Time `db:"updated_at"`
+	CreatedBy  *uuid.UUID `db:"created_by"`
+	UpdatedBy  *uuid.UUID `db:"updated_by"`
+}
+
+type OpnameDetail struct {
+	ID         uuid.UUID  `db:"id"`
+	HeaderID   uuid.UUID  `db:"header_id"`
+	ItemID     uuid.UUID  `db:"item_id"`
+	ItemCode   string     `db:"item_code"`
+	ItemName   string     `db:"item_name"`
+	SystemQty  float64    `db:"system_qty"`
+	CountedQty float64    `db:"counted_qty"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  *time.Time `db:"updated_at"`
+}
+
+// DiffQty returns the variance between the physical count and the snapshot
+func (d OpnameDetail) DiffQty() float64 {
+	return d.CountedQty - d.SystemQty
+}
+
+type OpnameFull struct {
+	Header  OpnameHeader
+	Details []OpnameDetail
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentTerms are the termin choices in days offered on a nota pembelian; 0 is tunai
+var PaymentTerms = []int{0, 7, 14, 30, 45, 60, 90}
+
+// PaymentMethods are the methods offered when recording a payment
+var PaymentMethods = []string{"Tunai", "Transfer", "Giro/Cek"}
+
+// PurchasePayment is one (partial) payment of a nota pembelian
+type PurchasePayment struct {
+	ID          uuid.UUID  `db:"id"`
+	PurchaseID  uuid.UUID  `db:"purchase_id"`
+	PaymentDate time.Time  `db:"payment_date"`
+	Amount      float64    `db:"amount"`
+	Method      string     `db:"method"`
+	Reference   string     `db:"reference"`
+	Note        string     `db:"note"`
+	Status      string     `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+	CreatedBy   *uuid.UUID `db:"created_by"`
+	UpdatedBy   *uuid.UUID `db:"updated_by"`
+}
+
+// PayableInvoice is an active nota pembelian with what is still owed on it.
+// Balance is the total less active payments and active linked returs.
+type PayableInvoice struct {
+	PurchaseID         uuid.UUID  `db:"purchase_id"`
+	PurchaseInvoiceNum string     `db:"purchase_invoice_num"`
+	PurchaseDate       time.Time  `db:"purchase_date"`
+	DueDate            time.Time  `db:"due_date"`
+	SupplierID         *uuid.UUID `db:"supplier_id"`
+	SupplierName       string     `db:"supplier_name"`
+	TotalAmount        float64    `db:"total_amount"`
+	PaidAmount         float64    `db:"paid_amount"`
+	ReturAmount        float64    `db:"retur_amount"`
+	Balance            float64    `db:"balance"`
+}
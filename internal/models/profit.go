@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Groupings of the laporan laba kotor
+const (
+	ProfitByDay      = "day"
+	ProfitByItem     = "item"
+	ProfitByCustomer = "customer"
+)
+
+// ProfitSummary is one group (day, item or customer) of the laporan laba kotor
+type ProfitSummary struct {
+	GroupKey  string  `db:"group_key"`
+	GroupName string  `db:"group_name"`
+	Qty       float64 `db:"qty"`
+	Revenue   float64 `db:"revenue"`
+	Cogs      float64 `db:"cogs"`
+}
+
+// GrossProfit is the revenue minus the cost of goods sold
+func (p ProfitSummary) GrossProfit() float64 {
+	return p.Revenue - p.Cogs
+}
+
+// Margin returns the gross profit as a percentage of revenue, 0 without revenue
+func (p ProfitSummary) Margin() float64 {
+	if p.Revenue == 0 {
+		return 0
+	}
+	return p.GrossProfit() / p.Revenue * 100
+}
+
+// ProfitLine is one nota line behind a ProfitSummary; retur penjualan lines
+// carry negative qty, revenue and cost
+type ProfitLine struct {
+	TrxDate      time.Time `db:"trx_date"`
+	DocumentID   uuid.UUID `db:"document_id"`
+	DocumentNum  string    `db:"document_num"`
+	DocumentType string    `db:"document_type"`
+	CustomerName string    `db:"customer_name"`
+	ItemCode     string    `db:"item_code"`
+	ItemName     string    `db:"item_name"`
+	Qty          float64   `db:"qty"`
+	Revenue      float64   `db:"revenue"`
+	Cogs         float64   `db:"cogs"`
+}
+
+// GrossProfit is the revenue minus the cost of goods sold of the line
+func (l ProfitLine) GrossProfit() float64 {
+	return l.Revenue - l.Cogs
+}
@@ -10,12 +10,16 @@ type PurchaseHeader struct {
 	PurchaseInvoiceNum string     `db:"purchase_invoice_num"`
 	PurchaseDate       time.Time  `db:"purchase_date"`
 	SupplierName       string     `db:"supplier_name"`
+	SupplierID         *uuid.UUID `db:"supplier_id"`
 	TotalAmount        float64    `db:"total_amount"`
+	PaymentTermDays    int        `db:"payment_term_days"`
+	DueDate            time.Time  `db:"due_date"`
 	CreatedAt          time.Time  `db:"created_at"`
 	UpdatedAt          *time.Time `db:"updated_at"`
 	CreatedBy          *uuid.UUID `db:"created_by"`
 	UpdatedBy          *uuid.UUID `db:"updated_by"`
 	Status             string     `db:"status"`
+	Version            int        `db:"version"`
 }
 
 type PurchaseDetail struct {
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Groupings of the laporan pembelian
+const (
+	PurchaseByDay      = "day"
+	PurchaseBySupplier = "supplier"
+	PurchaseByItem     = "item"
+)
+
+// PurchaseSummary is one group (day, supplier or item) of the laporan pembelian
+type PurchaseSummary struct {
+	GroupKey    string  `db:"group_key"`
+	GroupName   string  `db:"group_name"`
+	NotaCount   int     `db:"nota_count"`
+	Qty         float64 `db:"qty"`
+	TotalAmount float64 `db:"total_amount"`
+}
+
+// PurchaseReportLine is one nota pembelian line behind a PurchaseSummary
+type PurchaseReportLine struct {
+	PurchaseDate       time.Time `db:"purchase_date"`
+	HeaderID           uuid.UUID `db:"header_id"`
+	PurchaseInvoiceNum string    `db:"purchase_invoice_num"`
+	SupplierName       string    `db:"supplier_name"`
+	Status             string    `db:"status"`
+	ItemCode           string    `db:"item_code"`
+	ItemName           string    `db:"item_name"`
+	Qty                float64   `db:"qty"`
+	PriceAmount        float64   `db:"price_amount"`
+	TotalAmount        float64   `db:"total_amount"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SellPayment is one (partial) payment of a credit sale
+type SellPayment struct {
+	ID          uuid.UUID  `db:"id"`
+	SellID      uuid.UUID  `db:"sell_id"`
+	PaymentDate time.Time  `db:"payment_date"`
+	Amount      float64    `db:"amount"`
+	Method      string     `db:"method"`
+	Reference   string     `db:"reference"`
+	Note        string     `db:"note"`
+	Status      string     `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+	CreatedBy   *uuid.UUID `db:"created_by"`
+	UpdatedBy   *uuid.UUID `db:"updated_by"`
+}
+
+// ReceivableInvoice is an active credit sale with what the customer still owes on it
+type ReceivableInvoice struct {
+	SellID         uuid.UUID  `db:"sell_id"`
+	SellInvoiceNum string     `db:"sell_invoice_num"`
+	SellDate       time.Time  `db:"sell_date"`
+	DueDate        time.Time  `db:"due_date"`
+	CustomerID     *uuid.UUID `db:"customer_id"`
+	CustomerName   string     `db:"customer_name"`
+	TotalAmount    float64    `db:"total_amount"`
+	PaidAmount     float64    `db:"paid_amount"`
+	ReturAmount    float64    `db:"retur_amount"`
+	Balance        float64    `db:"balance"`
+}
+
+// StatementEntry is one line of a customer statement: a credit sale (debit)
+// or a payment or retur penjualan (credit)
+type StatementEntry struct {
+	Date        time.Time `db:"entry_date"`
+	DocumentNum string    `db:"document_num"`
+	Description string    `db:"description"`
+	Debit       float64   `db:"debit"`
+	Credit      float64   `db:"credit"`
+	CreatedAt   time.Time `db:"created_at"`
+	Balance     float64   `db:"-"`
+}
+
+type CustomerStatement struct {
+	Customer       Customer
+	StartDate      time.Time
+	EndDate        time.Time
+	OpeningBalance float64
+	Entries        []StatementEntry
+	ClosingBalance float64
+}
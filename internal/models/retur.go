@@ -11,12 +11,15 @@ type ReturHeader struct {
 	ReturInvoiceNum string     `db:"retur_invoice_num"`
 	ReturDate       time.Time  `db:"retur_date"`
 	SupplierName    string     `db:"supplier_name"`
+	SupplierID      *uuid.UUID `db:"supplier_id"`
+	PurchaseID      *uuid.UUID `db:"purchase_id"`
 	TotalAmount     float64    `db:"total_amount"`
 	CreatedAt       time.Time  `db:"created_at"`
 	UpdatedAt       *time.Time `db:"updated_at"`
 	CreatedBy       *uuid.UUID `db:"created_by"`
 	UpdatedBy       *uuid.UUID `db:"updated_by"`
 	Status          string     `db:"status"`
+	Version         int        `db:"version"`
 }
 
 type ReturDetail struct {
@@ -0,0 +1,28 @@
+package models
+
+// Role codes stored in roles.code and users.role
+const (
+	RoleAdmin      = "admin"
+	RoleSupervisor = "supervisor"
+	RoleKasir      = "kasir"
+	RoleGudang     = "gudang"
+)
+
+// Permission codes stored in role_permissions.permission
+const (
+	PermSales       = "sales"           // penjualan
+	PermPurchase    = "purchase"        // pembelian dan retur pembelian
+	PermInventory   = "inventory"       // master barang, stock opname, kartu stok
+	PermReports     = "reports"         // laporan
+	PermVoid        = "void"            // void nota dan sesi opname
+	PermPostOpname  = "opname.post"     // posting selisih stock opname
+	PermReconcile   = "stock.reconcile" // rekonsiliasi ledger stok
+	PermPurgeData   = "data.purge"      // hapus data transaksi lama
+	PermManageUsers = "users.manage"    // manajemen user
+	PermAuditView   = "audit.view"      // melihat audit trail
+)
+
+type Role struct {
+	Code string `db:"code"`
+	Name string `db:"name"`
+}
@@ -5,17 +5,32 @@ import (
 	"time"
 )
 
+// Payment methods of a nota penjualan; only credit sales become receivables
+const (
+	SellPaymentCash     = "cash"
+	SellPaymentTransfer = "transfer"
+	SellPaymentCredit   = "credit"
+)
+
+// SellPaymentMethods lists every method in the order shown in the penjualan dialog
+var SellPaymentMethods = []string{SellPaymentCash, SellPaymentTransfer, SellPaymentCredit}
+
 type SellHeader struct {
-	ID             uuid.UUID  `db:"id"`
-	SellInvoiceNum string     `db:"sell_invoice_num"`
-	SellDate       time.Time  `db:"sell_date"`
-	CustomerName   string     `db:"customer_name"`
-	TotalAmount    float64    `db:"total_amount"`
-	CreatedAt      time.Time  `db:"created_at"`
-	UpdatedAt      *time.Time `db:"updated_at"`
-	CreatedBy      *uuid.UUID `db:"created_by"`
-	UpdatedBy      *uuid.UUID `db:"updated_by"`
-	Status         string     `db:"status"`
+	ID              uuid.UUID  `db:"id"`
+	SellInvoiceNum  string     `db:"sell_invoice_num"`
+	SellDate        time.Time  `db:"sell_date"`
+	CustomerName    string     `db:"customer_name"`
+	CustomerID      *uuid.UUID `db:"customer_id"`
+	TotalAmount     float64    `db:"total_amount"`
+	PaymentMethod   string     `db:"payment_method"`
+	PaymentTermDays int        `db:"payment_term_days"`
+	DueDate         time.Time  `db:"due_date"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       *time.Time `db:"updated_at"`
+	CreatedBy       *uuid.UUID `db:"created_by"`
+	UpdatedBy       *uuid.UUID `db:"updated_by"`
+	Status          string     `db:"status"`
+	Version         int        `db:"version"`
 }
 
 type SellDetail struct {
@@ -25,6 +40,7 @@ type SellDetail struct {
 	Qty         float64    `db:"qty"`
 	PriceAmount float64    `db:"price_amount"`
 	TotalAmount float64    `db:"total_amount"`
+	CogsAmount  float64    `db:"cogs_amount"`
 	CreatedAt   time.Time  `db:"created_at"`
 	UpdatedAt   *time.Time `db:"updated_at"`
 }
@@ -38,4 +54,5 @@ type DailySalesReport struct {
 	SellDate         time.Time `db:"sell_date"`
 	TransactionCount int       `db:"transaction_count"`
 	TotalAmount      float64   `db:"total_amount"`
+	ReturAmount      float64   `db:"retur_amount"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SellReturHeader is a retur penjualan: goods a customer returns against one nota penjualan
+type SellReturHeader struct {
+	ID              uuid.UUID  `db:"id"`
+	ReturInvoiceNum string     `db:"retur_invoice_num"`
+	ReturDate       time.Time  `db:"retur_date"`
+	SellID          uuid.UUID  `db:"sell_id"`
+	SellInvoiceNum  string     `db:"sell_invoice_num"`
+	CustomerName    string     `db:"customer_name"`
+	CustomerID      *uuid.UUID `db:"customer_id"`
+	TotalAmount     float64    `db:"total_amount"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       *time.Time `db:"updated_at"`
+	CreatedBy       *uuid.UUID `db:"created_by"`
+	UpdatedBy       *uuid.UUID `db:"updated_by"`
+	Status          string     `db:"status"`
+	Version         int        `db:"version"`
+}
+
+type SellReturDetail struct {
+	ID          uuid.UUID  `db:"id"`
+	HeaderID    uuid.UUID  `db:"header_id"`
+	ItemID      uuid.UUID  `db:"item_id"`
+	Qty         float64    `db:"qty"`
+	PriceAmount float64    `db:"price_amount"`
+	TotalAmount float64    `db:"total_amount"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+}
+
+type SellReturFull struct {
+	Header  SellReturHeader
+	Details []SellReturDetail
+}
+
+// SellReturnable is one item of a nota penjualan with how much of it was sold
+// and how much the customer already returned
+type SellReturnable struct {
+	ItemID      uuid.UUID `db:"item_id"`
+	Code        string    `db:"code"`
+	Name        string    `db:"name"`
+	SoldQty     float64   `db:"sold_qty"`
+	ReturnedQty float64   `db:"returned_qty"`
+	PriceAmount float64   `db:"price_amount"`
+}
+
+// Available returns the quantity that can still be returned
+func (r SellReturnable) Available() float64 {
+	return r.SoldQty - r.ReturnedQty
+}
@@ -16,3 +16,54 @@ type StockMutation struct {
 	CreatedAt time.Time  `db:"created_at"`
 	UpdatedAt *time.Time `db:"updated_at"`
 }
+
+// StockCardEntry is a single line on the kartu stok, with the source document
+// number resolved from model_id/model_type
+type StockCardEntry struct {
+	TrxDate     time.Time `db:"trx_date"`
+	ModelID     uuid.UUID `db:"model_id"`
+	ModelType   string    `db:"model_type"`
+	DocumentNum string    `db:"document_num"`
+	Qty         float64   `db:"qty"`
+	CreatedAt   time.Time `db:"created_at"`
+	Balance     float64   `db:"-"`
+}
+
+// QtyIn returns the incoming quantity of the entry (0 for outgoing mutations)
+func (e StockCardEntry) QtyIn() float64 {
+	if e.Qty > 0 {
+		return e.Qty
+	}
+	return 0
+}
+
+// QtyOut returns the outgoing quantity of the entry as a positive number
+func (e StockCardEntry) QtyOut() float64 {
+	if e.Qty < 0 {
+		return -e.Qty
+	}
+	return 0
+}
+
+type StockCard struct {
+	Item           Item
+	StartDate      time.Time
+	EndDate        time.Time
+	OpeningBalance float64
+	Entries        []StockCardEntry
+	ClosingBalance float64
+}
+
+// StockDiscrepancy is an item whose items.qty differs from the sum of its stock_mutations
+type StockDiscrepancy struct {
+	ItemID    uuid.UUID `db:"item_id"`
+	Code      string    `db:"code"`
+	Name      string    `db:"name"`
+	Qty       float64   `db:"qty"`
+	LedgerQty float64   `db:"ledger_qty"`
+}
+
+// Difference returns items.qty minus the ledger balance
+func (d StockDiscrepancy) Difference() float64 {
+	return d.Qty - d.LedgerQty
+}
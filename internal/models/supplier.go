@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Supplier struct {
+	ID        uuid.UUID  `db:"id"`
+	Code      string     `db:"code"`
+	Name      string     `db:"name"`
+	Address   string     `db:"address"`
+	Phone     string     `db:"phone"`
+	NPWP      string     `db:"npwp"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	CreatedBy *uuid.UUID `db:"created_by"`
+	UpdatedBy *uuid.UUID `db:"updated_by"`
+	Version   int        `db:"version"`
+}
@@ -10,6 +10,15 @@ type User struct {
 	Username  string     `db:"username"`
 	Name      string     `db:"name"`
 	Password  string     `db:"password"`
+	Role      string     `db:"role"`
 	CreatedAt time.Time  `db:"created_at"`
 	UpdatedAt *time.Time `db:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	CreatedBy *uuid.UUID `db:"created_by"`
+	UpdatedBy *uuid.UUID `db:"updated_by"`
+}
+
+// IsActive reports whether the user has not been disabled
+func (u *User) IsActive() bool {
+	return u.DeletedAt == nil
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cost bases of the laporan nilai persediaan
+const (
+	ValuationLatestPurchase = "latest_purchase"
+	ValuationAverage        = "average"
+	ValuationFIFO           = "fifo"
+)
+
+// ValuationRow is the stock of one item as of the report date and its value
+type ValuationRow struct {
+	ItemID   uuid.UUID `db:"item_id"`
+	Code     string    `db:"code"`
+	Name     string    `db:"name"`
+	Qty      float64   `db:"qty"`
+	UnitCost float64   `db:"-"`
+	Value    float64   `db:"-"`
+}
+
+// InventoryValuation is the stock of every item rebuilt from stock_mutations as of AsOf
+type InventoryValuation struct {
+	AsOf       time.Time
+	Method     string
+	Rows       []ValuationRow
+	TotalQty   float64
+	TotalValue float64
+}
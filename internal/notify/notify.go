@@ -0,0 +1,153 @@
+// Package notify tells every running workstation that shared data changed,
+// using Postgres LISTEN/NOTIFY.
+//
+// Repositories call Publish with the transaction of the change. Postgres holds
+// the notification until that transaction commits and drops it on rollback, so
+// listeners only hear about committed data. Each app runs one Hub that LISTENs
+// on the channel and calls the subscribers of the changed topics.
+package notify
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres notification channel shared by all workstations
+const Channel = "stock_app_changes"
+
+// Topics carried in the notification payload
+const (
+	TopicItems      = "items"
+	TopicPurchase   = "purchase"
+	TopicSell       = "sell"
+	TopicRetur      = "retur"
+	TopicSupplier   = "supplier"
+	TopicCustomer   = "customer"
+	TopicPayable    = "payable"
+	TopicReceivable = "receivable"
+	TopicSellRetur  = "sell_retur"
+)
+
+// AllTopics is published by actions that touch everything, such as the data purge
+var AllTopics = []string{TopicItems, TopicPurchase, TopicSell, TopicRetur, TopicSupplier, TopicCustomer, TopicPayable, TopicReceivable, TopicSellRetur}
+
+// Publish queues one notification for the given topics on exec, normally the
+// transaction of the change
+func Publish(exec sqlx.Execer, topics ...string) error {
+	_, err := exec.Exec(`SELECT pg_notify($1, $2)`, Channel, strings.Join(topics, ","))
+	return err
+}
+
+type subscription struct {
+	topics map[string]bool
+	fn     func()
+}
+
+// Hub owns the LISTEN connection of this workstation. A nil *Hub is valid and
+// never calls its subscribers, so the app keeps working without live refresh.
+type Hub struct {
+	listener *pq.Listener
+
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]subscription
+	done   chan struct{}
+}
+
+// NewHub opens a dedicated listener connection with dsn and starts dispatching
+func NewHub(dsn string) (*Hub, error) {
+	listener := pq.NewListener(dsn, 5*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("notify: listener: %v", err)
+		}
+	})
+	if err := listener.Listen(Channel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	h := &Hub{
+		listener: listener,
+		subs:     map[int]subscription{},
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+func (h *Hub) run() {
+	for {
+		select {
+		case n := <-h.listener.Notify:
+			if n == nil {
+				// Reconnected: notifications may have been missed, refresh everything
+				h.dispatch(AllTopics)
+				continue
+			}
+			h.dispatch(strings.Split(n.Extra, ","))
+		case <-time.After(90 * time.Second):
+			go h.listener.Ping()
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// dispatch calls every subscriber of at least one of the topics, once
+func (h *Hub) dispatch(topics []string) {
+	h.mu.Lock()
+	var fns []func()
+	for _, sub := range h.subs {
+		for _, t := range topics {
+			if sub.topics[t] {
+				fns = append(fns, sub.fn)
+				break
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Subscribe registers fn for the topics and returns the function that removes
+// it again. fn runs on the listener goroutine, UI code must hop to the main
+// thread itself (fyne.Do).
+func (h *Hub) Subscribe(fn func(), topics ...string) (unsubscribe func()) {
+	if h == nil {
+		return func() {}
+	}
+
+	set := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		set[t] = true
+	}
+
+	h.mu.Lock()
+	h.nextID++
+	id := h.nextID
+	h.subs[id] = subscription{topics: set, fn: fn}
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		delete(h.subs, id)
+		h.mu.Unlock()
+	}
+}
+
+// Close stops dispatching and closes the listener connection
+func (h *Hub) Close() error {
+	if h == nil {
+		return nil
+	}
+	close(h.done)
+	return h.listener.Close()
+}
@@ -0,0 +1,156 @@
+// Package numbering generates document numbers such as PJ/202410/00042 from a
+// pattern and a per-type, per-period sequence stored in document_sequences.
+//
+// Supported tokens: {YYYY}, {YY}, {MM}, {DD} and {seq} or {seq:N} where N is
+// the zero-padded width. The sequence restarts whenever the date part of the
+// pattern changes, so a pattern with {MM} numbers each month from 1.
+package numbering
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Document types, equal to the model_type used in stock_mutations. Notas
+// pembelian are not numbered here: they carry the supplier's invoice number.
+const (
+	DocSell      = "sell"
+	DocRetur     = "retur"
+	DocSellRetur = "sell_retur"
+)
+
+// numberInUse checks whether a number is already in use by a document type,
+// so numbers typed in by hand before numbering was automatic are stepped over
+var numberInUse = map[string]string{
+	DocSell:      "SELECT EXISTS (SELECT 1 FROM sell_headers WHERE sell_invoice_num = $1)",
+	DocRetur:     "SELECT EXISTS (SELECT 1 FROM retur_headers WHERE retur_invoice_num = $1)",
+	DocSellRetur: "SELECT EXISTS (SELECT 1 FROM sell_retur_headers WHERE retur_invoice_num = $1)",
+}
+
+var seqPattern = regexp.MustCompile(`\{seq(?::(\d+))?\}`)
+
+type Service struct {
+	patterns map[string]string
+}
+
+// NewService takes the pattern of every document type keyed by Doc* constant
+func NewService(patterns map[string]string) *Service {
+	return &Service{patterns: patterns}
+}
+
+func (s *Service) pattern(docType string) (string, error) {
+	p, ok := s.patterns[docType]
+	if !ok || p == "" {
+		return "", fmt.Errorf("pola penomoran untuk %s belum diatur", docType)
+	}
+	if !seqPattern.MatchString(p) {
+		return "", fmt.Errorf("pola penomoran %s harus mengandung {seq}", p)
+	}
+	return p, nil
+}
+
+// Next allocates the next number inside tx. The upsert locks the sequence row
+// until tx ends, so two cashiers saving at once get consecutive numbers and a
+// rolled back transaction does not consume one. Numbers already used by an
+// existing nota are skipped, and the counter moves past them.
+func (s *Service) Next(tx *sqlx.Tx, docType string, date time.Time) (string, error) {
+	p, err := s.pattern(docType)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		var seq int
+		err = tx.Get(&seq, `INSERT INTO document_sequences (doc_type, period, last_seq, updated_at)
+			VALUES ($1, $2, 1, $3)
+			ON CONFLICT (doc_type, period) DO UPDATE
+			SET last_seq = document_sequences.last_seq + 1, updated_at = $3
+			RETURNING last_seq`, docType, periodKey(p, date), time.Now())
+		if err != nil {
+			return "", fmt.Errorf("gagal mengambil nomor urut %s: %w", docType, err)
+		}
+
+		num := render(p, date, seq)
+		taken, err := numberTaken(tx, docType, num)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return num, nil
+		}
+	}
+}
+
+// Preview returns the number the next document would get without allocating it
+func (s *Service) Preview(db sqlx.Queryer, docType string, date time.Time) (string, error) {
+	p, err := s.pattern(docType)
+	if err != nil {
+		return "", err
+	}
+
+	var last int
+	err = sqlx.Get(db, &last, `SELECT COALESCE(MAX(last_seq), 0) FROM document_sequences
+		WHERE doc_type = $1 AND period = $2`, docType, periodKey(p, date))
+	if err != nil {
+		return "", err
+	}
+
+	for seq := last + 1; ; seq++ {
+		num := render(p, date, seq)
+		taken, err := numberTaken(db, docType, num)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return num, nil
+		}
+	}
+}
+
+// numberTaken reports whether a nota of docType already carries num
+func numberTaken(db sqlx.Queryer, docType, num string) (bool, error) {
+	query, ok := numberInUse[docType]
+	if !ok {
+		return false, nil
+	}
+	var taken bool
+	err := sqlx.Get(db, &taken, query, num)
+	return taken, err
+}
+
+// periodKey is the date part of the pattern, e.g. "202410" for {YYYY}{MM}
+func periodKey(pattern string, date time.Time) string {
+	key := ""
+	switch {
+	case strings.Contains(pattern, "{DD}"):
+		key = date.Format("20060102")
+	case strings.Contains(pattern, "{MM}"):
+		key = date.Format("200601")
+	case strings.Contains(pattern, "{YYYY}"), strings.Contains(pattern, "{YY}"):
+		key = date.Format("2006")
+	}
+	return key
+}
+
+func render(pattern string, date time.Time, seq int) string {
+	r := strings.NewReplacer(
+		"{YYYY}", date.Format("2006"),
+		"{YY}", date.Format("06"),
+		"{MM}", date.Format("01"),
+		"{DD}", date.Format("02"),
+	)
+	out := r.Replace(pattern)
+
+	return seqPattern.ReplaceAllStringFunc(out, func(token string) string {
+		width := 0
+		if m := seqPattern.FindStringSubmatch(token); m[1] != "" {
+			width, _ = strconv.Atoi(m[1])
+		}
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
@@ -0,0 +1,48 @@
+package numbering
+
+import (
+	"testing"
+	"time"
+)
+
+var testDate = time.Date(2024, time.October, 5, 14, 30, 0, 0, time.Local)
+
+func TestRender(t *testing.T) {
+	// pattern and seq -> expected number
+	cases := []struct {
+		pattern string
+		seq     int
+		want    string
+	}{
+		{"PJ/{YYYY}{MM}/{seq:5}", 42, "PJ/202410/00042"},
+		{"RB-{YY}{MM}{DD}-{seq:3}", 7, "RB-241005-007"},
+		{"PB/{YYYY}/{seq}", 123, "PB/2024/123"},
+		{"PJ/{seq:2}", 1234, "PJ/1234"},
+		{"{seq:4}", 9, "0009"},
+		{"{seq:3}/{seq}", 5, "005/5"},
+	}
+
+	for _, c := range cases {
+		if got := render(c.pattern, testDate, c.seq); got != c.want {
+			t.Errorf("render(%q, %d) = %q, want %q", c.pattern, c.seq, got, c.want)
+		}
+	}
+}
+
+func TestPeriodKeyFollowsSmallestDateToken(t *testing.T) {
+	keys := map[string]string{
+		"PJ/{YYYY}{MM}{DD}/{seq:4}": "20241005",
+		"PJ/{YYYY}{MM}/{seq:5}":     "202410",
+		"PJ/{YY}{MM}/{seq:5}":       "202410",
+		"PJ/{YYYY}/{seq:5}":         "2024",
+		"PJ/{YY}/{seq:5}":           "2024",
+		// Without a date part the sequence never restarts
+		"PJ/{seq:5}": "",
+	}
+
+	for pattern, want := range keys {
+		if got := periodKey(pattern, testDate); got != want {
+			t.Errorf("periodKey(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
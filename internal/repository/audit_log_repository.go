@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne-app/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// auditLogLimit caps the rows returned to the viewer
+const auditLogLimit = 1000
+
+type AuditLogRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditLogRepository(db *sqlx.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Search returns the newest audit entries matching the filter. EndDate is inclusive.
+func (r *AuditLogRepository) Search(filter models.AuditLogFilter) ([]models.AuditLog, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("l.user_id = $%d", len(args)))
+	}
+	if filter.Channel != "" {
+		args = append(args, filter.Channel)
+		conditions = append(conditions, fmt.Sprintf("l.channel = $%d", len(args)))
+	}
+	if !filter.StartDate.IsZero() {
+		args = append(args, filter.StartDate)
+		conditions = append(conditions, fmt.Sprintf("l.created_at >= $%d", len(args)))
+	}
+	if !filter.EndDate.IsZero() {
+		args = append(args, filter.EndDate.AddDate(0, 0, 1))
+		conditions = append(conditions, fmt.Sprintf("l.created_at < $%d", len(args)))
+	}
+
+	query := `SELECT l.id, l.message, l.channel, l."level", l.level_name, l.context, l.extra,
+			  l.user_id, u.username, l.created_at
+			  FROM user_logs l
+			  LEFT JOIN users u ON u.id = l.user_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY l.created_at DESC LIMIT %d", auditLogLimit)
+
+	var logs []models.AuditLog
+	err := r.db.Select(&logs, query, args...)
+	return logs, err
+}
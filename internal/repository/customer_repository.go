@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type CustomerRepository struct {
+	db    *sqlx.DB
+	audit *audit.Logger
+}
+
+func NewCustomerRepository(db *sqlx.DB, auditLogger *audit.Logger) *CustomerRepository {
+	return &CustomerRepository{db: db, audit: auditLogger}
+}
+
+// ErrDefaultCustomer is returned when deleting or recoding the walk-in customer
+var ErrDefaultCustomer = errors.New("customer Umum tidak dapat dihapus atau diganti kodenya")
+
+// GetAll returns the active customers ordered by name
+func (r *CustomerRepository) GetAll() ([]models.Customer, error) {
+	var customers []models.Customer
+	query := `SELECT id, code, "name", address, phone, email, price_tier, credit_limit,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM customers
+			  WHERE deleted_at IS NULL
+			  ORDER BY "name"`
+
+	err := r.db.Select(&customers, query)
+	return customers, err
+}
+
+func (r *CustomerRepository) Search(keyword string) ([]models.Customer, error) {
+	var customers []models.Customer
+	query := `SELECT id, code, "name", address, phone, email, price_tier, credit_limit,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM customers
+			  WHERE deleted_at IS NULL
+			  AND (code ILIKE $1 OR "name" ILIKE $1 OR phone ILIKE $1)
+			  ORDER BY "name"`
+
+	err := r.db.Select(&customers, query, "%"+keyword+"%")
+	return customers, err
+}
+
+// GetDefault returns the walk-in customer preselected for new sales
+func (r *CustomerRepository) GetDefault() (*models.Customer, error) {
+	var customer models.Customer
+	query := `SELECT id, code, "name", address, phone, email, price_tier, credit_limit,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM customers
+			  WHERE code = $1`
+
+	err := r.db.Get(&customer, query, models.DefaultCustomerCode)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetByID also returns deleted customers so old notas can still show theirs
+func (r *CustomerRepository) GetByID(id uuid.UUID) (*models.Customer, error) {
+	return r.getByID(r.db, id)
+}
+
+func (r *CustomerRepository) getByID(q sqlx.Queryer, id uuid.UUID) (*models.Customer, error) {
+	var customer models.Customer
+	query := `SELECT id, code, "name", address, phone, email, price_tier, credit_limit,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM customers
+			  WHERE id = $1`
+
+	err := sqlx.Get(q, &customer, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *CustomerRepository) Create(customer *models.Customer) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	customer.ID = uuid.New()
+	customer.CreatedAt = time.Now()
+	customer.Version = 1
+
+	query := `INSERT INTO customers (id, code, "name", address, phone, email, price_tier, credit_limit, created_at, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = tx.Exec(query, customer.ID, customer.Code, customer.Name, customer.Address, customer.Phone,
+		customer.Email, customer.PriceTier, customer.CreditLimit, customer.CreatedAt, customer.CreatedBy)
+	if err != nil {
+		return mapDBError(err)
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  customer.CreatedBy,
+		Channel: audit.ChannelCustomer,
+		Message: "Tambah customer " + customer.Code,
+		After:   customer,
+		Extra:   map[string]interface{}{"id": customer.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicCustomer); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CustomerRepository) Update(customer *models.Customer) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockVersion(tx, "customers", "Customer", customer.ID, customer.Version); err != nil {
+		return err
+	}
+
+	before, err := r.getByID(tx, customer.ID)
+	if err != nil {
+		return err
+	}
+	if before.IsDefault() && customer.Code != before.Code {
+		return ErrDefaultCustomer
+	}
+
+	now := time.Now()
+	customer.UpdatedAt = &now
+
+	query := `UPDATE customers
+			  SET code = $1, "name" = $2, address = $3, phone = $4, email = $5, price_tier = $6,
+			      credit_limit = $7, updated_at = $8, updated_by = $9, version = version + 1
+			  WHERE id = $10`
+
+	_, err = tx.Exec(query, customer.Code, customer.Name, customer.Address, customer.Phone, customer.Email,
+		customer.PriceTier, customer.CreditLimit, customer.UpdatedAt, customer.UpdatedBy, customer.ID)
+	if err != nil {
+		return mapDBError(err)
+	}
+	customer.Version++
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  customer.UpdatedBy,
+		Channel: audit.ChannelCustomer,
+		Message: "Ubah customer " + before.Code,
+		Before:  before,
+		After:   customer,
+		Extra:   map[string]interface{}{"id": customer.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicCustomer); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete hides the customer from the picker; notas that reference it keep the link
+func (r *CustomerRepository) Delete(id uuid.UUID, deletedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := r.getByID(tx, id)
+	if err != nil {
+		return err
+	}
+	if before.IsDefault() {
+		return ErrDefaultCustomer
+	}
+
+	now := time.Now()
+	query := `UPDATE customers
+			  SET deleted_at = $1, updated_by = $2, updated_at = $3, version = version + 1
+			  WHERE id = $4`
+
+	_, err = tx.Exec(query, now, deletedBy, now, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &deletedBy,
+		Channel: audit.ChannelCustomer,
+		Message: "Hapus customer " + before.Code,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		Extra:   map[string]interface{}{"id": id},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicCustomer); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
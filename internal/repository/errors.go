@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrDuplicateInvoiceNum is matched (via errors.Is) by a UniqueViolationError
+// raised by one of the invoice number unique indexes
+var ErrDuplicateInvoiceNum = errors.New("nomor nota sudah terdaftar")
+
+// ErrDuplicateCode is matched (via errors.Is) by a UniqueViolationError raised
+// by the unique code of a master data table
+var ErrDuplicateCode = errors.New("kode sudah terdaftar")
+
+// uniqueConstraintErrors maps the unique indexes the UI reports on to the
+// sentinel error a UniqueViolationError on that index matches
+var uniqueConstraintErrors = map[string]error{
+	"sell_headers_sell_invoice_num_unique":         ErrDuplicateInvoiceNum,
+	"purchase_headers_supplier_invoice_num_unique": ErrDuplicateInvoiceNum,
+	"retur_headers_retur_invoice_num_unique":       ErrDuplicateInvoiceNum,
+	"sell_retur_headers_retur_invoice_num_unique":  ErrDuplicateInvoiceNum,
+	"suppliers_code_unique":                        ErrDuplicateCode,
+	"customers_code_unique":                        ErrDuplicateCode,
+}
+
+// UniqueViolationError wraps a Postgres unique_violation (SQLSTATE 23505)
+type UniqueViolationError struct {
+	Constraint string
+	Err        *pq.Error
+}
+
+func (e *UniqueViolationError) Error() string {
+	if known, ok := uniqueConstraintErrors[e.Constraint]; ok {
+		return known.Error()
+	}
+	return "data sudah terdaftar (" + e.Constraint + ")"
+}
+
+func (e *UniqueViolationError) Unwrap() error { return e.Err }
+
+func (e *UniqueViolationError) Is(target error) bool {
+	known, ok := uniqueConstraintErrors[e.Constraint]
+	return ok && target == known
+}
+
+// mapDBError turns driver errors the UI needs to tell apart into typed
+// repository errors; anything else is returned unchanged
+func mapDBError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return &UniqueViolationError{Constraint: pqErr.Constraint, Err: pqErr}
+	}
+	return err
+}
+
+// ErrInsufficientStock is matched (via errors.Is) by an InsufficientStockError
+var ErrInsufficientStock = errors.New("stok tidak mencukupi")
+
+// StockShortage describes one item a sale would take below zero
+type StockShortage struct {
+	ItemID    uuid.UUID
+	Code      string
+	Name      string
+	Available float64
+	Requested float64
+}
+
+// InsufficientStockError lists every item of a nota that does not have enough stock
+type InsufficientStockError struct {
+	Items []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	lines := make([]string, len(e.Items))
+	for i, it := range e.Items {
+		lines[i] = fmt.Sprintf("%s - %s (stok %.0f, diminta %.0f)", it.Code, it.Name, it.Available, it.Requested)
+	}
+	return ErrInsufficientStock.Error() + ": " + strings.Join(lines, "; ")
+}
+
+func (e *InsufficientStockError) Is(target error) bool { return target == ErrInsufficientStock }
+
+// ErrOverpayment is returned when a payment is larger than the open balance of its nota
+var ErrOverpayment = errors.New("pembayaran melebihi sisa tagihan")
+
+// ErrPurchaseInvoiceNumRequired is returned when a nota pembelian is saved
+// without the supplier's invoice number
+var ErrPurchaseInvoiceNumRequired = errors.New("nomor nota pembelian wajib diisi")
+
+// ErrTotalBelowPaid is returned when an edit would bring the nota total below
+// what has already been paid or returned against it
+var ErrTotalBelowPaid = errors.New("total nota lebih kecil dari jumlah yang sudah dibayar")
+
+// ErrReturExceedsPayable is matched (via errors.Is) by a ReturExceedsPayableError
+var ErrReturExceedsPayable = errors.New("total retur melebihi sisa hutang nota pembelian")
+
+// ReturExceedsPayableError is returned when a retur pembelian linked to a nota
+// is larger than what is still owed on that nota after payments and other returs
+type ReturExceedsPayableError struct {
+	InvoiceNum string
+	Balance    float64
+	Requested  float64
+}
+
+func (e *ReturExceedsPayableError) Error() string {
+	return fmt.Sprintf("%s: %s (sisa %.2f, retur %.2f)", ErrReturExceedsPayable.Error(), e.InvoiceNum, e.Balance, e.Requested)
+}
+
+func (e *ReturExceedsPayableError) Is(target error) bool { return target == ErrReturExceedsPayable }
+
+// ErrHasPayments is returned when voiding a nota that still has active payments
+var ErrHasPayments = errors.New("nota sudah memiliki pembayaran")
+
+// ErrHasReturns is returned when editing or voiding a nota penjualan that still
+// has active retur penjualan
+var ErrHasReturns = errors.New("nota sudah memiliki retur penjualan")
+
+// ErrHasPurchaseReturs is returned when voiding a nota pembelian that still has
+// active retur pembelian linked to it
+var ErrHasPurchaseReturs = errors.New("nota sudah memiliki retur pembelian")
+
+// ErrReturExceedsSold is matched (via errors.Is) by a ReturExceedsSoldError
+var ErrReturExceedsSold = errors.New("qty retur melebihi qty yang dijual")
+
+// ReturExcess describes one item a retur penjualan returns more of than is left on the nota
+type ReturExcess struct {
+	ItemID    uuid.UUID
+	Code      string
+	Name      string
+	Available float64
+	Requested float64
+}
+
+// ReturExceedsSoldError lists every item of a retur penjualan above the quantity
+// sold on the nota minus what was already returned
+type ReturExceedsSoldError struct {
+	Items []ReturExcess
+}
+
+func (e *ReturExceedsSoldError) Error() string {
+	lines := make([]string, len(e.Items))
+	for i, it := range e.Items {
+		lines[i] = fmt.Sprintf("%s - %s (sisa %.0f, diretur %.0f)", it.Code, it.Name, it.Available, it.Requested)
+	}
+	return ErrReturExceedsSold.Error() + ": " + strings.Join(lines, "; ")
+}
+
+func (e *ReturExceedsSoldError) Is(target error) bool { return target == ErrReturExceedsSold }
+
+// ErrCreditLimitExceeded is matched (via errors.Is) by a CreditLimitError
+var ErrCreditLimitExceeded = errors.New("limit kredit customer terlampaui")
+
+// CreditLimitError is returned when a credit sale would take the open
+// receivables of the customer above their credit limit
+type CreditLimitError struct {
+	Customer    string
+	Limit       float64
+	Outstanding float64
+	Requested   float64
+}
+
+func (e *CreditLimitError) Error() string {
+	return fmt.Sprintf("%s: %s (limit %.2f, piutang %.2f, nota %.2f)", ErrCreditLimitExceeded.Error(), e.Customer, e.Limit, e.Outstanding, e.Requested)
+}
+
+func (e *CreditLimitError) Is(target error) bool { return target == ErrCreditLimitExceeded }
+
+// ErrVersionConflict is matched (via errors.Is) by a ConflictError
+var ErrVersionConflict = errors.New("data sudah diubah oleh user lain")
+
+// ConflictError is returned by an update when the row was changed by someone
+// else after the caller loaded it
+type ConflictError struct {
+	Entity   string
+	ID       uuid.UUID
+	Expected int
+	Current  int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s sudah diubah oleh user lain (versi %d, sekarang %d)", e.Entity, e.Expected, e.Current)
+}
+
+func (e *ConflictError) Is(target error) bool { return target == ErrVersionConflict }
+
+// ErrDocumentVoided is matched (via errors.Is) by a DocumentVoidedError
+var ErrDocumentVoided = errors.New("nota sudah di-void")
+
+// DocumentVoidedError is returned by an update of a nota that is no longer ACTIVE
+type DocumentVoidedError struct {
+	Entity string
+	ID     uuid.UUID
+}
+
+func (e *DocumentVoidedError) Error() string {
+	return fmt.Sprintf("%s sudah di-void dan tidak dapat diubah", e.Entity)
+}
+
+func (e *DocumentVoidedError) Is(target error) bool { return target == ErrDocumentVoided }
+
+// lockVersion locks the row for the rest of the transaction and checks that its
+// version is still the one the caller loaded
+func lockVersion(tx *sqlx.Tx, table, entity string, id uuid.UUID, expected int) error {
+	var current int
+	err := tx.Get(&current, `SELECT version FROM `+table+` WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if current != expected {
+		return &ConflictError{Entity: entity, ID: id, Expected: expected, Current: current}
+	}
+	return nil
+}
+
+// lockActiveVersion is lockVersion for a nota: a voided nota is rejected before
+// the version is compared, since Void bumps the version as well and the nota
+// must not be rewritten once its stock has been reversed
+func lockActiveVersion(tx *sqlx.Tx, table, entity string, id uuid.UUID, expected int) error {
+	var row struct {
+		Version int    `db:"version"`
+		Status  string `db:"status"`
+	}
+	err := tx.Get(&row, `SELECT version, status FROM `+table+` WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if row.Status != "ACTIVE" {
+		return &DocumentVoidedError{Entity: entity, ID: id}
+	}
+	if row.Version != expected {
+		return &ConflictError{Entity: entity, ID: id, Expected: expected, Current: row.Version}
+	}
+	return nil
+}
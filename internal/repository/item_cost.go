@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// applyAvgCost moves the moving-average cost (HPP) of an item for a stock change
+// of qty units valued at unitCost and records the change in item_cost_history.
+// It locks the item row and must run before the document changes items.qty.
+func applyAvgCost(tx *sqlx.Tx, itemID uuid.UUID, qty, unitCost float64, trxDate time.Time, modelID uuid.UUID, modelType string) error {
+	var current struct {
+		Qty     float64 `db:"qty"`
+		AvgCost float64 `db:"avg_cost"`
+	}
+	err := tx.Get(&current, `SELECT qty, avg_cost FROM items WHERE id = $1 FOR UPDATE`, itemID)
+	if err != nil {
+		return fmt.Errorf("gagal membaca HPP barang: %v", err)
+	}
+
+	newCost := movingAverage(current.Qty, current.AvgCost, qty, unitCost)
+
+	_, err = tx.Exec(`UPDATE items SET avg_cost = $1 WHERE id = $2`, newCost, itemID)
+	if err != nil {
+		return fmt.Errorf("gagal memperbarui HPP barang: %v", err)
+	}
+
+	query := `INSERT INTO item_cost_history
+			  (id, item_id, trx_date, model_id, model_type, qty, unit_cost, qty_before, cost_before, cost_after, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err = tx.Exec(query, uuid.New(), itemID, trxDate, modelID, modelType, qty, unitCost,
+		current.Qty, current.AvgCost, newCost, time.Now())
+	if err != nil {
+		return fmt.Errorf("gagal mencatat riwayat HPP: %v", err)
+	}
+	return nil
+}
+
+// movingAverage returns the weighted average cost after adding qty units at
+// unitCost (qty is negative when goods leave at their purchase price)
+func movingAverage(oldQty, oldCost, qty, unitCost float64) float64 {
+	newQty := oldQty + qty
+	switch {
+	case qty > 0 && oldQty <= 0:
+		// Nothing (or negative stock) on hand: the incoming price becomes the cost
+		return unitCost
+	case newQty <= 0:
+		// Stock runs out: keep the last known cost for the next sale
+		return oldCost
+	}
+
+	cost := (oldQty*oldCost + qty*unitCost) / newQty
+	if cost < 0 {
+		return 0
+	}
+	return cost
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldQty   float64
+		oldCost  float64
+		qty      float64
+		unitCost float64
+		want     float64
+	}{
+		{"weighted average", 10, 1000, 10, 2000, 1500},
+		{"zero stock takes incoming price", 0, 1000, 5, 1200, 1200},
+		{"negative stock takes incoming price", -3, 1000, 5, 1200, 1200},
+		{"zero incoming qty keeps cost", 10, 1000, 0, 5000, 1000},
+		{"zero stock and zero qty keeps cost", 0, 1000, 0, 5000, 1000},
+		{"retur at purchase price", 10, 1500, -5, 2000, 1000},
+		{"retur empties stock keeps cost", 5, 1500, -5, 2000, 1500},
+		{"retur below zero keeps cost", 3, 1500, -5, 2000, 1500},
+		{"negative result clamps to zero", 10, 100, -5, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := movingAverage(tt.oldQty, tt.oldCost, tt.qty, tt.unitCost)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("movingAverage(%v, %v, %v, %v) = %v, want %v",
+					tt.oldQty, tt.oldCost, tt.qty, tt.unitCost, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,22 +3,27 @@ package repository
 import (
 	"time"
 
+	"fyne-app/internal/audit"
+	"fyne-app/internal/costing"
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type ItemRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	audit   *audit.Logger
+	costing *costing.Service
 }
 
-func NewItemRepository(db *sqlx.DB) *ItemRepository {
-	return &ItemRepository{db: db}
+func NewItemRepository(db *sqlx.DB, auditLogger *audit.Logger, costingService *costing.Service) *ItemRepository {
+	return &ItemRepository{db: db, audit: auditLogger, costing: costingService}
 }
 
 func (r *ItemRepository) GetAll() ([]models.Item, error) {
 	var items []models.Item
-	query := `SELECT id, code, "name", qty, price, 
+	query := `SELECT id, code, "name", qty, price, avg_cost, allow_negative_stock, version, 
 			  created_at, updated_at, deleted_at, created_by, updated_by 
 			  FROM items 
 			  WHERE deleted_at IS NULL 
@@ -30,7 +35,7 @@ func (r *ItemRepository) GetAll() ([]models.Item, error) {
 
 func (r *ItemRepository) GetByID(id uuid.UUID) (*models.Item, error) {
 	var item models.Item
-	query := `SELECT id, code, "name", qty, price, 
+	query := `SELECT id, code, "name", qty, price, avg_cost, allow_negative_stock, version, 
 			  created_at, updated_at, deleted_at, created_by, updated_by 
 			  FROM items 
 			  WHERE id = $1 AND deleted_at IS NULL`
@@ -45,7 +50,7 @@ func (r *ItemRepository) GetByID(id uuid.UUID) (*models.Item, error) {
 
 func (r *ItemRepository) GetByCode(code string) (*models.Item, error) {
 	var item models.Item
-	query := `SELECT id, code, "name", qty, price, 
+	query := `SELECT id, code, "name", qty, price, avg_cost, allow_negative_stock, version, 
 			  created_at, updated_at, deleted_at, created_by, updated_by 
 			  FROM items 
 			  WHERE (code ILIKE $1 OR "name" ILIKE $1) AND deleted_at IS NULL`
@@ -59,44 +64,161 @@ func (r *ItemRepository) GetByCode(code string) (*models.Item, error) {
 }
 
 func (r *ItemRepository) Create(item *models.Item) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	item.ID = uuid.New()
 	item.CreatedAt = time.Now()
 
-	query := `INSERT INTO items (id, code, "name", qty, price, created_at, created_by) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	// Qty is set after the opening cost is recorded, the way a purchase moves the HPP
+	query := `INSERT INTO items (id, code, "name", qty, price, avg_cost, allow_negative_stock, created_at, created_by) 
+			  VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8)`
 
-	_, err := r.db.Exec(query, item.ID, item.Code, item.Name, item.Qty,
-		item.Price, item.CreatedAt, item.CreatedBy)
-	return err
+	_, err = tx.Exec(query, item.ID, item.Code, item.Name,
+		item.Price, item.AvgCost, item.AllowNegativeStock, item.CreatedAt, item.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	// Stok awal barang baru masuk dengan HPP awal yang diisi user dan menjadi layer HPP pertamanya
+	if item.Qty > 0 {
+		if err := applyAvgCost(tx, item.ID, item.Qty, item.AvgCost, item.CreatedAt, item.ID, "opening_cost"); err != nil {
+			return err
+		}
+		if err := r.costing.OpenLayer(tx, item.ID, item.Qty, item.AvgCost, item.CreatedAt, item.ID, "opening_cost"); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`UPDATE items SET qty = $1 WHERE id = $2`, item.Qty, item.ID)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  item.CreatedBy,
+		Channel: audit.ChannelItem,
+		Message: "Tambah barang " + item.Code,
+		After:   item,
+		Extra:   map[string]interface{}{"id": item.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// Update saves the master data of an item. Qty is not written: stock only
+// moves through documents, opname and reconciliation, which keep the ledger and
+// cost layers in step and cannot be undone by a stale edit form.
 func (r *ItemRepository) Update(item *models.Item) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockVersion(tx, "items", "Barang", item.ID, item.Version); err != nil {
+		return err
+	}
+
+	var before models.Item
+	err = tx.Get(&before, `SELECT id, code, "name", qty, price, avg_cost, allow_negative_stock, version, 
+			  created_at, updated_at, deleted_at, created_by, updated_by 
+			  FROM items WHERE id = $1`, item.ID)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	item.UpdatedAt = &now
+	item.Qty = before.Qty
 
 	query := `UPDATE items 
-			  SET "name" = $1, qty = $2, price = $3, 
-			      updated_at = $4, updated_by = $5 
+			  SET "name" = $1, price = $2, allow_negative_stock = $3, 
+			      updated_at = $4, updated_by = $5, version = version + 1 
 			  WHERE id = $6`
 
-	_, err := r.db.Exec(query, item.Name, item.Qty, item.Price,
+	_, err = tx.Exec(query, item.Name, item.Price, item.AllowNegativeStock,
 		item.UpdatedAt, item.UpdatedBy, item.ID)
-	return err
+	if err != nil {
+		return err
+	}
+	item.Version++
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  item.UpdatedBy,
+		Channel: audit.ChannelItem,
+		Message: "Ubah barang " + before.Code,
+		Before:  before,
+		After:   item,
+		Extra:   map[string]interface{}{"id": item.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *ItemRepository) Delete(id uuid.UUID, deletedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.Item
+	err = tx.Get(&before, `SELECT id, code, "name", qty, price, avg_cost, allow_negative_stock, version, 
+			  created_at, updated_at, deleted_at, created_by, updated_by 
+			  FROM items WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	query := `UPDATE items 
-			  SET deleted_at = $1, updated_by = $2, updated_at = $3 
+			  SET deleted_at = $1, updated_by = $2, updated_at = $3, version = version + 1 
 			  WHERE id = $4`
 
-	_, err := r.db.Exec(query, now, deletedBy, now, id)
-	return err
+	_, err = tx.Exec(query, now, deletedBy, now, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &deletedBy,
+		Channel: audit.ChannelItem,
+		Message: "Hapus barang " + before.Code,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		Extra:   map[string]interface{}{"id": id},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *ItemRepository) Search(keyword string) ([]models.Item, error) {
 	var items []models.Item
-	query := `SELECT id, code, "name", qty, price, 
+	query := `SELECT id, code, "name", qty, price, avg_cost, allow_negative_stock, version, 
 			  created_at, updated_at, deleted_at, created_by, updated_by 
 			  FROM items 
 			  WHERE deleted_at IS NULL 
@@ -109,7 +231,7 @@ func (r *ItemRepository) Search(keyword string) ([]models.Item, error) {
 }
 
 func (r *ItemRepository) UpdateQty(tx *sqlx.Tx, itemID uuid.UUID, qtyChange float64) error {
-	query := `UPDATE items SET qty = qty + $1 WHERE id = $2`
+	query := `UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`
 
 	var err error
 	if tx != nil {
@@ -125,28 +247,15 @@ func (r *ItemRepository) BeginTx() (*sqlx.Tx, error) {
 	return r.db.Beginx()
 }
 
-// GetLatestPurchasePrices returns a map of item_id -> latest purchase price (harga beli)
-func (r *ItemRepository) GetLatestPurchasePrices() (map[uuid.UUID]float64, error) {
-	type result struct {
-		ItemID      uuid.UUID `db:"item_id"`
-		PriceAmount float64   `db:"price_amount"`
-	}
-
-	query := `SELECT DISTINCT ON (pd.item_id) pd.item_id, pd.price_amount
-			  FROM purchase_details pd
-			  JOIN purchase_headers ph ON ph.id = pd.header_id
-			  WHERE ph.status = 'ACTIVE'
-			  ORDER BY pd.item_id, ph.purchase_date DESC, pd.created_at DESC`
+// GetCostHistory returns the changes of the moving-average cost of an item, newest first
+func (r *ItemRepository) GetCostHistory(itemID uuid.UUID) ([]models.ItemCostHistory, error) {
+	var history []models.ItemCostHistory
+	query := `SELECT id, item_id, trx_date, model_id, model_type, qty, unit_cost,
+			  qty_before, cost_before, cost_after, created_at
+			  FROM item_cost_history
+			  WHERE item_id = $1
+			  ORDER BY created_at DESC, trx_date DESC`
 
-	var rows []result
-	err := r.db.Select(&rows, query)
-	if err != nil {
-		return nil, err
-	}
-
-	prices := make(map[uuid.UUID]float64, len(rows))
-	for _, row := range rows {
-		prices[row.ItemID] = row.PriceAmount
-	}
-	return prices, nil
+	err := r.db.Select(&history, query, itemID)
+	return history, err
 }
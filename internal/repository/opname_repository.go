@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne-app/internal/costing"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OpnameRepository struct {
+	db      *sqlx.DB
+	costing *costing.Service
+}
+
+func NewOpnameRepository(db *sqlx.DB, costingService *costing.Service) *OpnameRepository {
+	return &OpnameRepository{db: db, costing: costingService}
+}
+
+// Create membuka sesi opname baru berstatus DRAFT dan menyimpan snapshot qty
+// sistem untuk setiap barang aktif. Qty fisik awalnya disamakan dengan qty sistem.
+func (r *OpnameRepository) Create(header *models.OpnameHeader) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	header.ID = uuid.New()
+	header.Status = "DRAFT"
+	header.CreatedAt = time.Now()
+
+	headerQuery := `INSERT INTO opname_headers
+		(id, opname_num, opname_date, notes, status, created_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = tx.Exec(headerQuery, header.ID, header.OpnameNum, header.OpnameDate,
+		header.Notes, header.Status, header.CreatedAt, header.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot qty sistem untuk semua barang yang belum dihapus
+	var items []models.Item
+	err = tx.Select(&items, `SELECT id, qty FROM items WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil data barang: %v", err)
+	}
+
+	detailQuery := `INSERT INTO opname_details
+		(id, header_id, item_id, system_qty, counted_qty, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	for _, it := range items {
+		_, err = tx.Exec(detailQuery, uuid.New(), header.ID, it.ID, it.Qty, it.Qty, header.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByOpnameNum retrieves an Opname header by its document number
+func (r *OpnameRepository) GetByOpnameNum(opnameNum string) (*models.OpnameHeader, error) {
+	var header models.OpnameHeader
+	query := `SELECT id, opname_num, opname_date, notes, status, posted_at, posted_by,
+			  created_at, updated_at, created_by, updated_by
+			  FROM opname_headers
+			  WHERE opname_num = $1`
+
+	err := r.db.Get(&header, query, opnameNum)
+	if err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+func (r *OpnameRepository) GetAll() ([]models.OpnameHeader, error) {
+	var headers []models.OpnameHeader
+	query := `SELECT id, opname_num, opname_date, notes, status, posted_at, posted_by,
+			  created_at, updated_at, created_by, updated_by
+			  FROM opname_headers
+			  ORDER BY opname_date DESC, created_at DESC`
+
+	err := r.db.Select(&headers, query)
+	return headers, err
+}
+
+func (r *OpnameRepository) Search(keyword string) ([]models.OpnameHeader, error) {
+	var headers []models.OpnameHeader
+	query := `SELECT id, opname_num, opname_date, notes, status, posted_at, posted_by,
+			  created_at, updated_at, created_by, updated_by
+			  FROM opname_headers
+			  WHERE LOWER(opname_num) LIKE LOWER($1)
+			  OR LOWER(notes) LIKE LOWER($1)
+			  ORDER BY opname_date DESC, created_at DESC`
+
+	searchPattern := "%" + keyword + "%"
+	err := r.db.Select(&headers, query, searchPattern)
+	return headers, err
+}
+
+func (r *OpnameRepository) GetByID(id uuid.UUID) (*models.OpnameFull, error) {
+	var opname models.OpnameFull
+
+	headerQuery := `SELECT id, opname_num, opname_date, notes, status, posted_at, posted_by,
+					created_at, updated_at, created_by, updated_by
+					FROM opname_headers
+					WHERE id = $1`
+
+	err := r.db.Get(&opname.Header, headerQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	detailQuery := `SELECT od.id, od.header_id, od.item_id, i.code AS item_code, i."name" AS item_name,
+					od.system_qty, od.counted_qty, od.created_at, od.updated_at
+					FROM opname_details od
+					JOIN items i ON i.id = od.item_id
+					WHERE od.header_id = $1
+					ORDER BY i."name"`
+
+	err = r.db.Select(&opname.Details, detailQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opname, nil
+}
+
+// UpdateCount menyimpan qty hasil hitung fisik untuk satu baris opname.
+// Hanya sesi berstatus DRAFT yang boleh diubah.
+func (r *OpnameRepository) UpdateCount(detailID uuid.UUID, countedQty float64, updatedBy uuid.UUID) error {
+	if countedQty < 0 {
+		return errors.New("qty fisik tidak boleh negatif")
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.Get(&status, `SELECT oh.status FROM opname_headers oh
+		JOIN opname_details od ON od.header_id = oh.id
+		WHERE od.id = $1 FOR UPDATE OF oh`, detailID)
+	if err != nil {
+		return err
+	}
+	if status != "DRAFT" {
+		return fmt.Errorf("opname berstatus %s tidak dapat diubah", status)
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE opname_details SET counted_qty = $1, updated_at = $2 WHERE id = $3`,
+		countedQty, now, detailID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE opname_headers SET updated_at = $1, updated_by = $2
+		WHERE id = (SELECT header_id FROM opname_details WHERE id = $3)`, now, updatedBy, detailID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Post memposting sesi opname: selisih (qty fisik - qty snapshot) diterapkan ke
+// items.qty dan dicatat di stock_mutations dengan model_type 'opname'.
+// Setelah diposting sesi tidak dapat diubah lagi, hanya bisa di-Void.
+func (r *OpnameRepository) Post(id uuid.UUID, postedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, postedBy, models.PermPostOpname); err != nil {
+		return err
+	}
+
+	// 1. Kunci header agar tidak diposting dua kali secara bersamaan
+	var header models.OpnameHeader
+	err = tx.Get(&header, `SELECT id, opname_date, status FROM opname_headers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if header.Status != "DRAFT" {
+		return fmt.Errorf("opname berstatus %s tidak dapat diposting", header.Status)
+	}
+
+	// 2. Ambil baris yang memiliki selisih
+	var details []models.OpnameDetail
+	err = tx.Select(&details, `SELECT item_id, system_qty, counted_qty FROM opname_details
+		WHERE header_id = $1 AND counted_qty <> system_qty`, id)
+	if err != nil {
+		return err
+	}
+
+	// 3. Terapkan selisih ke stok dan catat mutasi
+	now := time.Now()
+	period := header.OpnameDate.Format("2006-01")
+	for _, d := range details {
+		diff := d.DiffQty()
+
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, diff, d.ItemID)
+		if err != nil {
+			return err
+		}
+		if err := r.costing.Adjust(tx, d.ItemID, diff, header.OpnameDate, id, "opname"); err != nil {
+			return err
+		}
+
+		mutationQuery := `INSERT INTO stock_mutations
+						  (id, item_id, period, trx_date, qty, model_id, model_type, created_at)
+						  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+		_, err = tx.Exec(mutationQuery, uuid.New(), d.ItemID, period, header.OpnameDate, diff, id, "opname", now)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 4. Update status header menjadi POSTED
+	_, err = tx.Exec(`UPDATE opname_headers SET status = 'POSTED', posted_at = $1, posted_by = $2,
+		updated_at = $1, updated_by = $2 WHERE id = $3`, now, postedBy, id)
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Void membatalkan sesi opname. Jika sesi sudah diposting, selisih stok
+// dikembalikan dan dicatat sebagai mutasi 'void_opname'.
+func (r *OpnameRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM opname_headers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if status == "VOID" {
+		return errors.New("opname ini sudah berstatus VOID")
+	}
+
+	now := time.Now()
+
+	if status == "POSTED" {
+		// 1. Dapatkan baris yang sudah mengubah stok
+		var details []models.OpnameDetail
+		err = tx.Select(&details, `SELECT item_id, system_qty, counted_qty FROM opname_details
+			WHERE header_id = $1 AND counted_qty <> system_qty`, id)
+		if err != nil {
+			return err
+		}
+
+		// 2. Kembalikan stok dan catat mutasi pembatalan
+		period := now.Format("2006-01")
+		for _, d := range details {
+			diff := d.DiffQty()
+
+			_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, diff, d.ItemID)
+			if err != nil {
+				return err
+			}
+			if err := r.costing.Adjust(tx, d.ItemID, -diff, now, id, "void_opname"); err != nil {
+				return err
+			}
+
+			mutationQuery := `INSERT INTO stock_mutations
+							  (id, item_id, period, trx_date, qty, model_id, model_type, created_at)
+							  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+			_, err = tx.Exec(mutationQuery, uuid.New(), d.ItemID, period, now, -diff, id, "void_opname", now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// 3. Update status header menjadi VOID
+	_, err = tx.Exec(`UPDATE opname_headers SET status = 'VOID', updated_at = $1, updated_by = $2 WHERE id = $3`, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// payableSelect lists every active nota pembelian with its paid and returned
+// amounts; callers wrap it to filter on the computed balance
+const payableSelect = `SELECT h.id AS purchase_id, h.purchase_invoice_num, h.purchase_date, h.due_date,
+		h.supplier_id, h.supplier_name, h.total_amount,
+		COALESCE(p.paid, 0) AS paid_amount,
+		COALESCE(r.retur, 0) AS retur_amount,
+		h.total_amount - COALESCE(p.paid, 0) - COALESCE(r.retur, 0) AS balance
+	FROM purchase_headers h
+	LEFT JOIN (
+		SELECT purchase_id, SUM(amount) AS paid FROM purchase_payments
+		WHERE status = 'ACTIVE' GROUP BY purchase_id
+	) p ON p.purchase_id = h.id
+	LEFT JOIN (
+		SELECT purchase_id, SUM(total_amount) AS retur FROM retur_headers
+		WHERE status = 'ACTIVE' AND purchase_id IS NOT NULL GROUP BY purchase_id
+	) r ON r.purchase_id = h.id
+	WHERE h.status = 'ACTIVE'`
+
+// PayableRepository keeps the hutang supplier: payments of notas pembelian
+// and the open balances derived from them
+type PayableRepository struct {
+	db    *sqlx.DB
+	audit *audit.Logger
+}
+
+func NewPayableRepository(db *sqlx.DB, auditLogger *audit.Logger) *PayableRepository {
+	return &PayableRepository{db: db, audit: auditLogger}
+}
+
+// GetOutstanding returns the notas that are not fully settled, oldest due date first
+func (r *PayableRepository) GetOutstanding() ([]models.PayableInvoice, error) {
+	var invoices []models.PayableInvoice
+	query := `SELECT * FROM (` + payableSelect + `) b
+			  WHERE balance > 0.005
+			  ORDER BY due_date, purchase_invoice_num`
+
+	err := r.db.Select(&invoices, query)
+	return invoices, err
+}
+
+// GetBySupplier returns every active nota of the supplier, newest first, for
+// linking a retur to the nota it reduces
+func (r *PayableRepository) GetBySupplier(supplierID uuid.UUID) ([]models.PayableInvoice, error) {
+	var invoices []models.PayableInvoice
+	query := `SELECT * FROM (` + payableSelect + `) b
+			  WHERE supplier_id = $1
+			  ORDER BY purchase_date DESC, purchase_invoice_num DESC`
+
+	err := r.db.Select(&invoices, query, supplierID)
+	return invoices, err
+}
+
+func (r *PayableRepository) GetInvoice(purchaseID uuid.UUID) (*models.PayableInvoice, error) {
+	return r.getInvoice(r.db, purchaseID)
+}
+
+func (r *PayableRepository) getInvoice(q sqlx.Queryer, purchaseID uuid.UUID) (*models.PayableInvoice, error) {
+	var invoice models.PayableInvoice
+	query := `SELECT * FROM (` + payableSelect + `) b WHERE purchase_id = $1`
+
+	err := sqlx.Get(q, &invoice, query, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetAging returns the open balance per supplier split into the aging buckets as of asOf
+func (r *PayableRepository) GetAging(asOf time.Time) ([]models.AgingRow, error) {
+	invoices, err := r.GetOutstanding()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.AgingRow
+	index := map[string]int{}
+	for _, inv := range invoices {
+		key := inv.SupplierName
+		if inv.SupplierID != nil {
+			key = inv.SupplierID.String()
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, models.AgingRow{PartyID: inv.SupplierID, PartyName: inv.SupplierName})
+		}
+		rows[i].Add(inv.Balance, inv.DueDate, asOf)
+	}
+	return rows, nil
+}
+
+// GetPayments returns every payment of the nota, including voided ones
+func (r *PayableRepository) GetPayments(purchaseID uuid.UUID) ([]models.PurchasePayment, error) {
+	var payments []models.PurchasePayment
+	query := `SELECT id, purchase_id, payment_date, amount, method, reference, note, status,
+			  created_at, updated_at, created_by, updated_by
+			  FROM purchase_payments
+			  WHERE purchase_id = $1
+			  ORDER BY payment_date, created_at`
+
+	err := r.db.Select(&payments, query, purchaseID)
+	return payments, err
+}
+
+// AddPayment records a (partial) payment. The nota row is locked so two
+// workstations cannot pay the same balance twice.
+func (r *PayableRepository) AddPayment(payment *models.PurchasePayment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if payment.CreatedBy == nil {
+		return ErrForbidden
+	}
+	if err := RequirePermission(tx, *payment.CreatedBy, models.PermPurchase); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM purchase_headers WHERE id = $1 FOR UPDATE`, payment.PurchaseID)
+	if err != nil {
+		return err
+	}
+	if status != "ACTIVE" {
+		return errors.New("nota pembelian sudah di-void")
+	}
+
+	invoice, err := r.getInvoice(tx, payment.PurchaseID)
+	if err != nil {
+		return err
+	}
+	if payment.Amount > invoice.Balance+0.005 {
+		return ErrOverpayment
+	}
+
+	payment.ID = uuid.New()
+	payment.Status = "ACTIVE"
+	payment.CreatedAt = time.Now()
+
+	query := `INSERT INTO purchase_payments
+			  (id, purchase_id, payment_date, amount, method, reference, note, status, created_at, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = tx.Exec(query, payment.ID, payment.PurchaseID, payment.PaymentDate, payment.Amount, payment.Method,
+		payment.Reference, payment.Note, payment.Status, payment.CreatedAt, payment.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  payment.CreatedBy,
+		Channel: audit.ChannelPayable,
+		Message: "Bayar nota pembelian " + invoice.PurchaseInvoiceNum,
+		After:   payment,
+		Extra:   map[string]interface{}{"id": payment.ID, "purchase_id": payment.PurchaseID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicPayable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// VoidPayment cancels a payment, putting its amount back on the nota balance
+func (r *PayableRepository) VoidPayment(id uuid.UUID, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var before models.PurchasePayment
+	err = tx.Get(&before, `SELECT id, purchase_id, payment_date, amount, method, reference, note, status,
+		created_at, updated_at, created_by, updated_by
+		FROM purchase_payments WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+
+	var invoiceNum string
+	err = tx.Get(&invoiceNum, `SELECT purchase_invoice_num FROM purchase_headers WHERE id = $1`, before.PurchaseID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE purchase_payments SET status = 'VOID', updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &updatedBy,
+		Channel: audit.ChannelPayable,
+		Message: "Void pembayaran nota pembelian " + invoiceNum,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		After:   map[string]string{"status": "VOID"},
+		Extra:   map[string]interface{}{"id": id, "purchase_id": before.PurchaseID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicPayable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrForbidden is returned when the acting user's role lacks the required permission
+var ErrForbidden = errors.New("anda tidak memiliki hak akses untuk aksi ini")
+
+// RequirePermission checks role_permissions for the given user. It is called
+// inside the transaction of a protected action so the UI check cannot be bypassed.
+func RequirePermission(q sqlx.Queryer, userID uuid.UUID, permission string) error {
+	var allowed bool
+	err := sqlx.Get(q, &allowed, `SELECT EXISTS (
+		SELECT 1 FROM users u
+		JOIN role_permissions rp ON rp.role = u.role
+		WHERE u.id = $1 AND u.deleted_at IS NULL AND rp.permission = $2
+	)`, userID, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"fyne-app/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// profitLines lists every line of the active notas penjualan in $1..$2 with its
+// HPP, plus the active retur penjualan as negative lines valued at the HPP of
+// the nota they return to
+const profitLines = `SELECT h.sell_date AS trx_date, h.id AS document_id, h.sell_invoice_num AS document_num,
+		'sell' AS document_type, COALESCE(h.customer_id::text, h.customer_name) AS customer_key, h.customer_name,
+		d.item_id, i.code AS item_code, i."name" AS item_name,
+		d.qty, d.total_amount AS revenue, d.cogs_amount AS cogs, d.created_at
+	FROM sell_details d
+	JOIN sell_headers h ON h.id = d.header_id
+	JOIN items i ON i.id = d.item_id
+	WHERE h.status = 'ACTIVE' AND h.sell_date >= $1::date AND h.sell_date <= $2::date
+	UNION ALL
+	SELECT rh.retur_date, rh.id, rh.retur_invoice_num,
+		'sell_retur', COALESCE(sh.customer_id::text, sh.customer_name), sh.customer_name,
+		rd.item_id, i.code, i."name",
+		-rd.qty, -rd.total_amount,
+		-rd.qty * COALESCE((SELECT SUM(sd.cogs_amount) / NULLIF(SUM(sd.qty), 0) FROM sell_details sd
+			WHERE sd.header_id = rh.sell_id AND sd.item_id = rd.item_id), 0),
+		rd.created_at
+	FROM sell_retur_details rd
+	JOIN sell_retur_headers rh ON rh.id = rd.header_id
+	JOIN sell_headers sh ON sh.id = rh.sell_id
+	JOIN items i ON i.id = rd.item_id
+	WHERE rh.status = 'ACTIVE' AND rh.retur_date >= $1::date AND rh.retur_date <= $2::date`
+
+// profitGroup is how one grouping of the report names and orders its rows
+type profitGroup struct {
+	key   string
+	name  string
+	order string
+}
+
+var profitGroups = map[string]profitGroup{
+	models.ProfitByDay:      {key: `to_char(trx_date, 'YYYY-MM-DD')`, name: `to_char(trx_date, 'YYYY-MM-DD')`, order: `group_key DESC`},
+	models.ProfitByItem:     {key: `item_id::text`, name: `MAX(item_code || ' - ' || item_name)`, order: `group_name`},
+	models.ProfitByCustomer: {key: `customer_key`, name: `MAX(customer_name)`, order: `group_name`},
+}
+
+// ProfitRepository builds the laporan laba kotor from the HPP stored on every
+// sell_details row
+type ProfitRepository struct {
+	db *sqlx.DB
+}
+
+func NewProfitRepository(db *sqlx.DB) *ProfitRepository {
+	return &ProfitRepository{db: db}
+}
+
+func lookupProfitGroup(groupBy string) (profitGroup, error) {
+	g, ok := profitGroups[groupBy]
+	if !ok {
+		return profitGroup{}, fmt.Errorf("pengelompokan laporan laba %q tidak dikenal", groupBy)
+	}
+	return g, nil
+}
+
+// GetSummary returns revenue and HPP per day, item or customer between startDate and endDate
+func (r *ProfitRepository) GetSummary(groupBy string, startDate, endDate time.Time) ([]models.ProfitSummary, error) {
+	g, err := lookupProfitGroup(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.ProfitSummary
+	query := `SELECT ` + g.key + ` AS group_key, ` + g.name + ` AS group_name,
+			  SUM(qty) AS qty, SUM(revenue) AS revenue, SUM(cogs) AS cogs
+			  FROM (` + profitLines + `) l
+			  GROUP BY ` + g.key + `
+			  ORDER BY ` + g.order
+
+	err = r.db.Select(&rows, query, startDate, endDate)
+	return rows, err
+}
+
+// GetLines returns the nota lines behind one row of GetSummary
+func (r *ProfitRepository) GetLines(groupBy, groupKey string, startDate, endDate time.Time) ([]models.ProfitLine, error) {
+	g, err := lookupProfitGroup(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []models.ProfitLine
+	query := `SELECT trx_date, document_id, document_num, document_type, customer_name,
+			  item_code, item_name, qty, revenue, cogs
+			  FROM (` + profitLines + `) l
+			  WHERE ` + g.key + ` = $3
+			  ORDER BY trx_date, document_num, created_at`
+
+	err = r.db.Select(&lines, query, startDate, endDate, groupKey)
+	return lines, err
+}
@@ -1,27 +1,34 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"fyne-app/internal/audit"
+	"fyne-app/internal/costing"
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type PurchaseRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	audit   *audit.Logger
+	costing *costing.Service
 }
 
-func NewPurchaseRepository(db *sqlx.DB) *PurchaseRepository {
-	return &PurchaseRepository{db: db}
+func NewPurchaseRepository(db *sqlx.DB, auditLogger *audit.Logger, costingService *costing.Service) *PurchaseRepository {
+	return &PurchaseRepository{db: db, audit: auditLogger, costing: costingService}
 }
 
 // GetByInvoiceNum retrieves a Purchase header by its invoice number
 func (r *PurchaseRepository) GetByInvoiceNum(invoiceNum string) (*models.PurchaseHeader, error) {
 	var header models.PurchaseHeader
-	query := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, total_amount, 
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, supplier_id, total_amount, payment_term_days, due_date, 
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM purchase_headers 
 			  WHERE purchase_invoice_num = $1`
 
@@ -39,13 +46,19 @@ func (r *PurchaseRepository) Create(purchase *models.PurchaseFull) error {
 	}
 	defer tx.Rollback()
 
+	// The nota pembelian number is the supplier's invoice number, unique per supplier
+	if purchase.Header.PurchaseInvoiceNum == "" {
+		return ErrPurchaseInvoiceNumRequired
+	}
+
 	// Insert header
 	purchase.Header.ID = uuid.New()
 	purchase.Header.CreatedAt = time.Now()
+	purchase.Header.DueDate = purchase.Header.PurchaseDate.AddDate(0, 0, purchase.Header.PaymentTermDays)
 
 	headerQuery := `INSERT INTO purchase_headers 
-		(id, purchase_invoice_num, purchase_date, supplier_name, total_amount, status, created_at, created_by) 
-		VALUES ($1, $2, $3, $4, $5, 'ACTIVE', $6, $7)`
+		(id, purchase_invoice_num, purchase_date, supplier_name, supplier_id, total_amount, payment_term_days, due_date, status, created_at, created_by) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'ACTIVE', $9, $10)`
 
 	_, err = tx.Exec(
 		headerQuery,
@@ -53,13 +66,16 @@ func (r *PurchaseRepository) Create(purchase *models.PurchaseFull) error {
 		purchase.Header.PurchaseInvoiceNum,
 		purchase.Header.PurchaseDate,
 		purchase.Header.SupplierName,
+		purchase.Header.SupplierID,
 		purchase.Header.TotalAmount,
+		purchase.Header.PaymentTermDays,
+		purchase.Header.DueDate,
 		purchase.Header.CreatedAt,
 		purchase.Header.CreatedBy,
 	)
 
 	if err != nil {
-		return err
+		return mapDBError(err)
 	}
 
 	// Insert details and update stock
@@ -68,6 +84,21 @@ func (r *PurchaseRepository) Create(purchase *models.PurchaseFull) error {
 		return err
 	}
 
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  purchase.Header.CreatedBy,
+		Channel: audit.ChannelPurchase,
+		Message: "Buat nota pembelian " + purchase.Header.PurchaseInvoiceNum,
+		After:   purchase,
+		Extra:   map[string]interface{}{"id": purchase.Header.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicPurchase, notify.TopicItems, notify.TopicPayable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -78,20 +109,46 @@ func (r *PurchaseRepository) Update(purchase *models.PurchaseFull) error {
 	}
 	defer tx.Rollback()
 
+	if err := lockActiveVersion(tx, "purchase_headers", "Nota pembelian", purchase.Header.ID, purchase.Header.Version); err != nil {
+		return err
+	}
+
+	before, err := r.getByID(tx, purchase.Header.ID)
+	if err != nil {
+		return err
+	}
+
+	// Total nota tidak boleh turun di bawah yang sudah dibayar dan diretur
+	var settled float64
+	err = tx.Get(&settled, `SELECT COALESCE(SUM(paid_amount + retur_amount), 0) FROM (`+payableSelect+`) p WHERE purchase_id = $1`, purchase.Header.ID)
+	if err != nil {
+		return err
+	}
+	if purchase.Header.TotalAmount < settled-0.005 {
+		return ErrTotalBelowPaid
+	}
+
 	// 1. Get old details to revert stock
 	var oldDetails []models.PurchaseDetail
-	err = tx.Select(&oldDetails, `SELECT item_id, qty FROM purchase_details WHERE header_id = $1`, purchase.Header.ID)
+	err = tx.Select(&oldDetails, `SELECT item_id, qty, price_amount FROM purchase_details WHERE header_id = $1`, purchase.Header.ID)
 	if err != nil {
 		return err
 	}
 
-	// 2. Revert stock and delete old mutations
+	// 2. Revert stock and cost, then delete old mutations
 	for _, d := range oldDetails {
-		_, err = tx.Exec(`UPDATE items SET qty = qty - $1 WHERE id = $2`, d.Qty, d.ItemID)
+		err = applyAvgCost(tx, d.ItemID, -d.Qty, d.PriceAmount, before.Header.PurchaseDate, purchase.Header.ID, "purchase")
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
 		if err != nil {
 			return err
 		}
 	}
+	if err := r.costing.CloseLayers(tx, purchase.Header.ID, "purchase"); err != nil {
+		return err
+	}
 	_, err = tx.Exec(`DELETE FROM stock_mutations WHERE model_id = $1 AND model_type = 'purchase'`, purchase.Header.ID)
 	if err != nil {
 		return err
@@ -106,21 +163,28 @@ func (r *PurchaseRepository) Update(purchase *models.PurchaseFull) error {
 	// 4. Update header
 	now := time.Now()
 	purchase.Header.UpdatedAt = &now
+	purchase.Header.DueDate = purchase.Header.PurchaseDate.AddDate(0, 0, purchase.Header.PaymentTermDays)
 	headerQuery := `UPDATE purchase_headers SET 
 		purchase_invoice_num = $1,
 		purchase_date        = $2,
 		supplier_name        = $3,
-		total_amount         = $4,
-		updated_at           = $5,
-		updated_by           = $6
-		WHERE id = $7`
+		supplier_id          = $4,
+		total_amount         = $5,
+		payment_term_days    = $6,
+		due_date             = $7,
+		updated_at           = $8,
+		updated_by           = $9,
+		version              = version + 1
+		WHERE id = $10`
 
 	_, err = tx.Exec(headerQuery, purchase.Header.PurchaseInvoiceNum, purchase.Header.PurchaseDate,
-		purchase.Header.SupplierName, purchase.Header.TotalAmount, purchase.Header.UpdatedAt,
+		purchase.Header.SupplierName, purchase.Header.SupplierID, purchase.Header.TotalAmount,
+		purchase.Header.PaymentTermDays, purchase.Header.DueDate, purchase.Header.UpdatedAt,
 		purchase.Header.UpdatedBy, purchase.Header.ID)
 	if err != nil {
-		return err
+		return mapDBError(err)
 	}
+	purchase.Header.Version++
 
 	// 5. Insert new details and update stock
 	err = r.insertDetails(tx, &purchase.Header, purchase.Details)
@@ -128,6 +192,22 @@ func (r *PurchaseRepository) Update(purchase *models.PurchaseFull) error {
 		return err
 	}
 
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  purchase.Header.UpdatedBy,
+		Channel: audit.ChannelPurchase,
+		Message: "Ubah nota pembelian " + purchase.Header.PurchaseInvoiceNum,
+		Before:  before,
+		After:   purchase,
+		Extra:   map[string]interface{}{"id": purchase.Header.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicPurchase, notify.TopicItems, notify.TopicPayable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -148,8 +228,18 @@ func (r *PurchaseRepository) insertDetails(tx *sqlx.Tx, header *models.PurchaseH
 			return err
 		}
 
-		// Update item quantity (add for purchases)
-		_, err = tx.Exec(`UPDATE items SET qty = qty + $1 WHERE id = $2`, detail.Qty, detail.ItemID)
+		// Update moving-average cost and item quantity (add for purchases)
+		err = applyAvgCost(tx, detail.ItemID, detail.Qty, detail.PriceAmount, header.PurchaseDate, header.ID, "purchase")
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, detail.Qty, detail.ItemID)
+		if err != nil {
+			return err
+		}
+
+		// Every purchase line opens a FIFO cost layer
+		err = r.costing.OpenLayer(tx, detail.ItemID, detail.Qty, detail.PriceAmount, header.PurchaseDate, header.ID, "purchase")
 		if err != nil {
 			return err
 		}
@@ -172,8 +262,8 @@ func (r *PurchaseRepository) insertDetails(tx *sqlx.Tx, header *models.PurchaseH
 
 func (r *PurchaseRepository) GetAll() ([]models.PurchaseHeader, error) {
 	var headers []models.PurchaseHeader
-	query := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, total_amount,
-			status, created_at, updated_at, created_by, updated_by
+	query := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, supplier_id, total_amount, payment_term_days, due_date,
+			status, created_at, updated_at, created_by, updated_by, version
 			FROM purchase_headers
 			ORDER BY purchase_date DESC`
 
@@ -182,15 +272,20 @@ func (r *PurchaseRepository) GetAll() ([]models.PurchaseHeader, error) {
 }
 
 func (r *PurchaseRepository) GetByID(id uuid.UUID) (*models.PurchaseFull, error) {
+	return r.getByID(r.db, id)
+}
+
+// getByID reads the document through q so it can run inside a transaction
+func (r *PurchaseRepository) getByID(q sqlx.Queryer, id uuid.UUID) (*models.PurchaseFull, error) {
 	var purchase models.PurchaseFull
 
 	// Get header
-	headerQuery := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, total_amount,
-					status, created_at, updated_at, created_by, updated_by 
+	headerQuery := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, supplier_id, total_amount, payment_term_days, due_date,
+					status, created_at, updated_at, created_by, updated_by, version 
 					FROM purchase_headers 
 					WHERE id = $1`
 
-	err := r.db.Get(&purchase.Header, headerQuery, id)
+	err := sqlx.Get(q, &purchase.Header, headerQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -201,7 +296,7 @@ func (r *PurchaseRepository) GetByID(id uuid.UUID) (*models.PurchaseFull, error)
 					FROM purchase_details 
 					WHERE header_id = $1`
 
-	err = r.db.Select(&purchase.Details, detailQuery, id)
+	err = sqlx.Select(q, &purchase.Details, detailQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -211,8 +306,8 @@ func (r *PurchaseRepository) GetByID(id uuid.UUID) (*models.PurchaseFull, error)
 
 func (r *PurchaseRepository) Search(keyword string) ([]models.PurchaseHeader, error) {
 	var headers []models.PurchaseHeader
-	query := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, total_amount, 
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, purchase_invoice_num, purchase_date, supplier_name, supplier_id, total_amount, payment_term_days, due_date, 
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM purchase_headers 
 			  WHERE LOWER(purchase_invoice_num) LIKE LOWER($1) 
 			  OR LOWER(supplier_name) LIKE LOWER($1)
@@ -231,23 +326,69 @@ func (r *PurchaseRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 	}
 	defer tx.Rollback()
 
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM purchase_headers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if status == "VOID" {
+		return errors.New("nota pembelian sudah di-void")
+	}
+
+	before, err := r.getByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	// Pembayaran harus dibatalkan dulu supaya hutang supplier tetap benar
+	var payments int
+	err = tx.Get(&payments, `SELECT COUNT(*) FROM purchase_payments WHERE purchase_id = $1 AND status = 'ACTIVE'`, id)
+	if err != nil {
+		return err
+	}
+	if payments > 0 {
+		return ErrHasPayments
+	}
+
+	// Retur yang merujuk nota ini harus di-void dulu, stok dan layer HPP-nya ikut nota ini
+	var returs int
+	err = tx.Get(&returs, `SELECT COUNT(*) FROM retur_headers WHERE purchase_id = $1 AND status = 'ACTIVE'`, id)
+	if err != nil {
+		return err
+	}
+	if returs > 0 {
+		return ErrHasPurchaseReturs
+	}
+
 	// 1. Dapatkan detail item yang dibeli
 	var details []models.PurchaseDetail
-	err = tx.Select(&details, `SELECT item_id, qty FROM purchase_details WHERE header_id = $1`, id)
+	err = tx.Select(&details, `SELECT item_id, qty, price_amount FROM purchase_details WHERE header_id = $1`, id)
 	if err != nil {
 		return err
 	}
 
-	// 2. Kembalikan stok (kurangi stok gudang karena pembelian batal)
+	// 2. Kembalikan stok dan HPP (kurangi stok gudang karena pembelian batal)
+	now := time.Now()
 	for _, d := range details {
-		_, err = tx.Exec(`UPDATE items SET qty = qty - $1 WHERE id = $2`, d.Qty, d.ItemID)
+		err = applyAvgCost(tx, d.ItemID, -d.Qty, d.PriceAmount, now, id, "void_purchase")
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
 		if err != nil {
 			return err
 		}
 	}
 
+	if err := r.costing.CloseLayers(tx, id, "purchase"); err != nil {
+		return err
+	}
+
 	// 3. Catat mutasi pembatalan
-	now := time.Now()
 	period := now.Format("2006-01")
 	for _, d := range details {
 		mutationID := uuid.New()
@@ -263,10 +404,98 @@ func (r *PurchaseRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 	}
 
 	// 4. Update status header menjadi VOID
-	_, err = tx.Exec(`UPDATE purchase_headers SET status = 'VOID', updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	_, err = tx.Exec(`UPDATE purchase_headers SET status = 'VOID', version = version + 1, updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
 	if err != nil {
 		return err
 	}
 
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &updatedBy,
+		Channel: audit.ChannelPurchase,
+		Message: "Void nota pembelian " + before.Header.PurchaseInvoiceNum,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		After:   map[string]string{"status": "VOID"},
+		Extra:   map[string]interface{}{"id": id},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicPurchase, notify.TopicItems, notify.TopicPayable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
+
+// purchaseReportLines lists every nota pembelian line in $1..$2, filtered on
+// status $3 (empty for all)
+const purchaseReportLines = `SELECT h.purchase_date, h.id AS header_id, h.purchase_invoice_num,
+		COALESCE(h.supplier_id::text, h.supplier_name) AS supplier_key, h.supplier_name, h.status,
+		d.item_id, i.code AS item_code, i."name" AS item_name,
+		d.qty, d.price_amount, d.total_amount, d.created_at
+	FROM purchase_details d
+	JOIN purchase_headers h ON h.id = d.header_id
+	JOIN items i ON i.id = d.item_id
+	WHERE h.purchase_date >= $1::date AND h.purchase_date <= $2::date
+	AND ($3 = '' OR h.status = $3)`
+
+// purchaseGroup is how one grouping of the laporan pembelian names and orders its rows
+type purchaseGroup struct {
+	key   string
+	name  string
+	order string
+}
+
+var purchaseGroups = map[string]purchaseGroup{
+	models.PurchaseByDay:      {key: `to_char(purchase_date, 'YYYY-MM-DD')`, name: `to_char(purchase_date, 'YYYY-MM-DD')`, order: `group_key DESC`},
+	models.PurchaseBySupplier: {key: `supplier_key`, name: `MAX(supplier_name)`, order: `group_name`},
+	models.PurchaseByItem:     {key: `item_id::text`, name: `MAX(item_code || ' - ' || item_name)`, order: `group_name`},
+}
+
+func lookupPurchaseGroup(groupBy string) (purchaseGroup, error) {
+	g, ok := purchaseGroups[groupBy]
+	if !ok {
+		return purchaseGroup{}, fmt.Errorf("pengelompokan laporan pembelian %q tidak dikenal", groupBy)
+	}
+	return g, nil
+}
+
+// GetReportSummary returns the number of notas, qty and amount purchased per day,
+// supplier or item between startDate and endDate. status is "ACTIVE", "VOID" or
+// empty for both.
+func (r *PurchaseRepository) GetReportSummary(groupBy, status string, startDate, endDate time.Time) ([]models.PurchaseSummary, error) {
+	g, err := lookupPurchaseGroup(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.PurchaseSummary
+	query := `SELECT ` + g.key + ` AS group_key, ` + g.name + ` AS group_name,
+			  COUNT(DISTINCT header_id) AS nota_count, SUM(qty) AS qty, SUM(total_amount) AS total_amount
+			  FROM (` + purchaseReportLines + `) l
+			  GROUP BY ` + g.key + `
+			  ORDER BY ` + g.order
+
+	err = r.db.Select(&rows, query, startDate, endDate, status)
+	return rows, err
+}
+
+// GetReportLines returns the nota lines behind one row of GetReportSummary
+func (r *PurchaseRepository) GetReportLines(groupBy, groupKey, status string, startDate, endDate time.Time) ([]models.PurchaseReportLine, error) {
+	g, err := lookupPurchaseGroup(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []models.PurchaseReportLine
+	query := `SELECT purchase_date, header_id, purchase_invoice_num, supplier_name, status,
+			  item_code, item_name, qty, price_amount, total_amount
+			  FROM (` + purchaseReportLines + `) l
+			  WHERE ` + g.key + ` = $4
+			  ORDER BY purchase_date, purchase_invoice_num, created_at`
+
+	err = r.db.Select(&lines, query, startDate, endDate, status, groupKey)
+	return lines, err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"time"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// purgeablePurchases lists the notas pembelian dated before $1 that can go:
+// no open hutang, and no payment or retur dated on or after $1 that still
+// refers to them
+const purgeablePurchases = `SELECT h.id::text FROM purchase_headers h
+	WHERE h.purchase_date < $1
+	AND h.id NOT IN (SELECT purchase_id FROM (` + payableSelect + `) p WHERE p.balance > 0.005)
+	AND NOT EXISTS (SELECT 1 FROM purchase_payments pp WHERE pp.purchase_id = h.id AND pp.payment_date >= $1)
+	AND NOT EXISTS (SELECT 1 FROM retur_headers rh WHERE rh.purchase_id = h.id AND rh.retur_date >= $1)`
+
+// purgeableSells lists the notas penjualan dated before $1 that can go: no
+// open piutang, and no payment or retur penjualan dated on or after $1
+const purgeableSells = `SELECT h.id::text FROM sell_headers h
+	WHERE h.sell_date < $1
+	AND h.id NOT IN (SELECT sell_id FROM (` + receivableSelect + `) r WHERE r.balance > 0.005)
+	AND NOT EXISTS (SELECT 1 FROM sell_payments sp WHERE sp.sell_id = h.id AND sp.payment_date >= $1)
+	AND NOT EXISTS (SELECT 1 FROM sell_retur_headers sr WHERE sr.sell_id = h.id AND sr.retur_date >= $1)`
+
+// PurgeRepository removes old transaction history while keeping the stock
+// ledger balanced
+type PurgeRepository struct {
+	db    *sqlx.DB
+	audit *audit.Logger
+}
+
+func NewPurgeRepository(db *sqlx.DB, auditLogger *audit.Logger) *PurgeRepository {
+	return &PurgeRepository{db: db, audit: auditLogger}
+}
+
+// Purge deletes stock mutations and the notas pembelian, penjualan and their
+// returs dated before cutoff. The deleted mutations are carried forward as one
+// "opening" mutation per item so the ledger still sums to items.qty. Notas
+// with an open balance or with payments or returs on or after cutoff are
+// kept, together with their own returs; the number kept is returned.
+func (r *PurgeRepository) Purge(cutoff time.Time, purgedBy uuid.UUID) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, purgedBy, models.PermPurgeData); err != nil {
+		return 0, err
+	}
+
+	// Documents are picked before anything is deleted: a retur counts towards
+	// the balance of its nota
+	var purchaseIDs, sellIDs []string
+	if err := tx.Select(&purchaseIDs, purgeablePurchases, cutoff); err != nil {
+		return 0, err
+	}
+	if err := tx.Select(&sellIDs, purgeableSells, cutoff); err != nil {
+		return 0, err
+	}
+
+	var kept int
+	err = tx.Get(&kept, `SELECT
+		(SELECT COUNT(*) FROM purchase_headers WHERE purchase_date < $1) +
+		(SELECT COUNT(*) FROM sell_headers WHERE sell_date < $1) - $2`, cutoff, len(purchaseIDs)+len(sellIDs))
+	if err != nil {
+		return 0, err
+	}
+
+	type carryForward struct {
+		ItemID uuid.UUID `db:"item_id"`
+		Qty    float64   `db:"qty"`
+	}
+	var balances []carryForward
+	err = tx.Select(&balances, `SELECT item_id, SUM(qty) AS qty FROM stock_mutations
+		WHERE trx_date < $1 GROUP BY item_id`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM stock_mutations WHERE trx_date < $1`, cutoff); err != nil {
+		return 0, err
+	}
+
+	purgeID := uuid.New()
+	now := time.Now()
+	for _, b := range balances {
+		_, err = tx.Exec(`INSERT INTO stock_mutations
+			(id, item_id, period, trx_date, qty, model_id, model_type, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			uuid.New(), b.ItemID, cutoff.Format("2006-01"), cutoff, b.Qty, purgeID, "opening", now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Returs pembelian go with their nota, or on their own when not linked to one.
+	// Their recorded layer consumptions go too: a purged retur cannot be voided.
+	queries := []string{
+		`DELETE FROM cost_consumptions WHERE model_type = 'retur' AND model_id IN (
+			SELECT id FROM retur_headers WHERE retur_date < $1
+			AND (purchase_id IS NULL OR purchase_id = ANY($2::uuid[]))
+		)`,
+		`DELETE FROM retur_headers WHERE retur_date < $1
+			AND (purchase_id IS NULL OR purchase_id = ANY($2::uuid[]))`,
+		`DELETE FROM purchase_headers WHERE id = ANY($2::uuid[])`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, cutoff, pq.Array(purchaseIDs)); err != nil {
+			return 0, err
+		}
+	}
+
+	// Returs penjualan, payments and layer consumptions of a sale cascade with it
+	if _, err := tx.Exec(`DELETE FROM sell_headers WHERE id = ANY($1::uuid[])`, pq.Array(sellIDs)); err != nil {
+		return 0, err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &purgedBy,
+		Channel: audit.ChannelPurge,
+		Message: "Hapus data transaksi sebelum " + cutoff.Format("2006-01-02"),
+		Level:   audit.LevelWarning,
+		Before: map[string]interface{}{
+			"carried_forward_items": len(balances),
+			"purchases":             len(purchaseIDs),
+			"sells":                 len(sellIDs),
+			"kept":                  kept,
+		},
+		Extra: map[string]interface{}{"cutoff": cutoff.Format("2006-01-02"), "model_id": purgeID},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := notify.Publish(tx, notify.AllTopics...); err != nil {
+		return 0, err
+	}
+
+	return kept, tx.Commit()
+}
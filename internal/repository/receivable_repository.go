@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// receivableSelect lists every active credit sale with its paid and returned
+// amounts; callers wrap it to filter on the computed balance
+const receivableSelect = `SELECT h.id AS sell_id, h.sell_invoice_num, h.sell_date, h.due_date,
+		h.customer_id, h.customer_name, h.total_amount,
+		COALESCE(p.paid, 0) AS paid_amount,
+		COALESCE(r.retur, 0) AS retur_amount,
+		h.total_amount - COALESCE(p.paid, 0) - COALESCE(r.retur, 0) AS balance
+	FROM sell_headers h
+	LEFT JOIN (
+		SELECT sell_id, SUM(amount) AS paid FROM sell_payments
+		WHERE status = 'ACTIVE' GROUP BY sell_id
+	) p ON p.sell_id = h.id
+	LEFT JOIN (
+		SELECT sell_id, SUM(total_amount) AS retur FROM sell_retur_headers
+		WHERE status = 'ACTIVE' GROUP BY sell_id
+	) r ON r.sell_id = h.id
+	WHERE h.status = 'ACTIVE' AND h.payment_method = 'credit'`
+
+// ReceivableRepository keeps the piutang customer: payments of credit sales
+// and the open balances derived from them
+type ReceivableRepository struct {
+	db    *sqlx.DB
+	audit *audit.Logger
+}
+
+func NewReceivableRepository(db *sqlx.DB, auditLogger *audit.Logger) *ReceivableRepository {
+	return &ReceivableRepository{db: db, audit: auditLogger}
+}
+
+// GetOutstanding returns the credit sales that are not fully paid, oldest due date first
+func (r *ReceivableRepository) GetOutstanding() ([]models.ReceivableInvoice, error) {
+	var invoices []models.ReceivableInvoice
+	query := `SELECT * FROM (` + receivableSelect + `) b
+			  WHERE balance > 0.005
+			  ORDER BY due_date, sell_invoice_num`
+
+	err := r.db.Select(&invoices, query)
+	return invoices, err
+}
+
+// GetCustomerBalance returns the open receivables of one customer
+func (r *ReceivableRepository) GetCustomerBalance(customerID uuid.UUID) (float64, error) {
+	var balance float64
+	query := `SELECT COALESCE(SUM(balance), 0) FROM (` + receivableSelect + `) b WHERE customer_id = $1`
+
+	err := r.db.Get(&balance, query, customerID)
+	return balance, err
+}
+
+func (r *ReceivableRepository) GetInvoice(sellID uuid.UUID) (*models.ReceivableInvoice, error) {
+	return r.getInvoice(r.db, sellID)
+}
+
+func (r *ReceivableRepository) getInvoice(q sqlx.Queryer, sellID uuid.UUID) (*models.ReceivableInvoice, error) {
+	var invoice models.ReceivableInvoice
+	query := `SELECT * FROM (` + receivableSelect + `) b WHERE sell_id = $1`
+
+	err := sqlx.Get(q, &invoice, query, sellID)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// GetAging returns the open balance per customer split into the aging buckets as of asOf
+func (r *ReceivableRepository) GetAging(asOf time.Time) ([]models.AgingRow, error) {
+	invoices, err := r.GetOutstanding()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.AgingRow
+	index := map[string]int{}
+	for _, inv := range invoices {
+		key := inv.CustomerName
+		if inv.CustomerID != nil {
+			key = inv.CustomerID.String()
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, models.AgingRow{PartyID: inv.CustomerID, PartyName: inv.CustomerName})
+		}
+		rows[i].Add(inv.Balance, inv.DueDate, asOf)
+	}
+	return rows, nil
+}
+
+// statementEntries lists the credit sales of a customer as debits and their
+// payments and retur penjualan as credits
+const statementEntries = `SELECT h.sell_date AS entry_date, h.sell_invoice_num AS document_num,
+		'Penjualan kredit' AS description, h.total_amount AS debit, 0 AS credit, h.created_at
+	FROM sell_headers h
+	WHERE h.customer_id = $1 AND h.status = 'ACTIVE' AND h.payment_method = 'credit'
+	UNION ALL
+	SELECT p.payment_date, h.sell_invoice_num, 'Pembayaran ' || p.method, 0, p.amount, p.created_at
+	FROM sell_payments p
+	JOIN sell_headers h ON h.id = p.sell_id
+	WHERE h.customer_id = $1 AND h.status = 'ACTIVE' AND h.payment_method = 'credit' AND p.status = 'ACTIVE'
+	UNION ALL
+	SELECT r.retur_date, r.retur_invoice_num, 'Retur nota ' || h.sell_invoice_num, 0, r.total_amount, r.created_at
+	FROM sell_retur_headers r
+	JOIN sell_headers h ON h.id = r.sell_id
+	WHERE h.customer_id = $1 AND h.status = 'ACTIVE' AND h.payment_method = 'credit' AND r.status = 'ACTIVE'`
+
+// GetStatement builds the account statement of a customer: opening balance
+// before startDate, every sale and payment in the range and the running balance
+func (r *ReceivableRepository) GetStatement(customer models.Customer, startDate, endDate time.Time) (*models.CustomerStatement, error) {
+	var opening float64
+	err := r.db.Get(&opening, `SELECT COALESCE(SUM(debit - credit), 0) FROM (`+statementEntries+`) e
+		WHERE entry_date < $2`, customer.ID, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.StatementEntry
+	err = r.db.Select(&entries, `SELECT * FROM (`+statementEntries+`) e
+		WHERE entry_date >= $2 AND entry_date <= $3
+		ORDER BY entry_date, created_at`, customer.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := opening
+	for i := range entries {
+		balance += entries[i].Debit - entries[i].Credit
+		entries[i].Balance = balance
+	}
+
+	return &models.CustomerStatement{
+		Customer:       customer,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		OpeningBalance: opening,
+		Entries:        entries,
+		ClosingBalance: balance,
+	}, nil
+}
+
+// GetPayments returns every payment of the nota, including voided ones
+func (r *ReceivableRepository) GetPayments(sellID uuid.UUID) ([]models.SellPayment, error) {
+	var payments []models.SellPayment
+	query := `SELECT id, sell_id, payment_date, amount, method, reference, note, status,
+			  created_at, updated_at, created_by, updated_by
+			  FROM sell_payments
+			  WHERE sell_id = $1
+			  ORDER BY payment_date, created_at`
+
+	err := r.db.Select(&payments, query, sellID)
+	return payments, err
+}
+
+// AddPayment records a (partial) payment of a credit sale. The nota row is
+// locked so two workstations cannot receive the same balance twice.
+func (r *ReceivableRepository) AddPayment(payment *models.SellPayment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if payment.CreatedBy == nil {
+		return ErrForbidden
+	}
+	if err := RequirePermission(tx, *payment.CreatedBy, models.PermSales); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM sell_headers WHERE id = $1 FOR UPDATE`, payment.SellID)
+	if err != nil {
+		return err
+	}
+	if status != "ACTIVE" {
+		return errors.New("nota penjualan sudah di-void")
+	}
+
+	invoice, err := r.getInvoice(tx, payment.SellID)
+	if err != nil {
+		return err
+	}
+	if payment.Amount > invoice.Balance+0.005 {
+		return ErrOverpayment
+	}
+
+	payment.ID = uuid.New()
+	payment.Status = "ACTIVE"
+	payment.CreatedAt = time.Now()
+
+	query := `INSERT INTO sell_payments
+			  (id, sell_id, payment_date, amount, method, reference, note, status, created_at, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = tx.Exec(query, payment.ID, payment.SellID, payment.PaymentDate, payment.Amount, payment.Method,
+		payment.Reference, payment.Note, payment.Status, payment.CreatedAt, payment.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  payment.CreatedBy,
+		Channel: audit.ChannelReceivable,
+		Message: "Terima pembayaran nota penjualan " + invoice.SellInvoiceNum,
+		After:   payment,
+		Extra:   map[string]interface{}{"id": payment.ID, "sell_id": payment.SellID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicReceivable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// VoidPayment cancels a payment, putting its amount back on the nota balance
+func (r *ReceivableRepository) VoidPayment(id uuid.UUID, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var before models.SellPayment
+	err = tx.Get(&before, `SELECT id, sell_id, payment_date, amount, method, reference, note, status,
+		created_at, updated_at, created_by, updated_by
+		FROM sell_payments WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+
+	var invoiceNum string
+	err = tx.Get(&invoiceNum, `SELECT sell_invoice_num FROM sell_headers WHERE id = $1`, before.SellID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE sell_payments SET status = 'VOID', updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &updatedBy,
+		Channel: audit.ChannelReceivable,
+		Message: "Void pembayaran nota penjualan " + invoiceNum,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		After:   map[string]string{"status": "VOID"},
+		Extra:   map[string]interface{}{"id": id, "sell_id": before.SellID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicReceivable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"time"
+
+	"fyne-app/internal/costing"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ledgerTolerance absorbs float rounding when comparing items.qty with the ledger
+const ledgerTolerance = 0.0001
+
+// ReconciliationRepository compares items.qty with the stock_mutations ledger and
+// repairs drift in either direction.
+type ReconciliationRepository struct {
+	db      *sqlx.DB
+	costing *costing.Service
+}
+
+func NewReconciliationRepository(db *sqlx.DB, costingService *costing.Service) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db, costing: costingService}
+}
+
+// GetDiscrepancies returns every active item whose qty differs from its mutation sum
+func (r *ReconciliationRepository) GetDiscrepancies() ([]models.StockDiscrepancy, error) {
+	var rows []models.StockDiscrepancy
+	query := `SELECT i.id AS item_id, i.code, i."name", i.qty,
+			  COALESCE(SUM(sm.qty), 0) AS ledger_qty
+			  FROM items i
+			  LEFT JOIN stock_mutations sm ON sm.item_id = i.id
+			  WHERE i.deleted_at IS NULL
+			  GROUP BY i.id, i.code, i."name", i.qty
+			  HAVING ABS(i.qty - COALESCE(SUM(sm.qty), 0)) > $1
+			  ORDER BY i."name"`
+
+	err := r.db.Select(&rows, query, ledgerTolerance)
+	return rows, err
+}
+
+// RebuildQty overwrites items.qty with the ledger balance for the given items,
+// treating stock_mutations as the source of truth. The cost layers follow the
+// rebuilt qty under one model_id per call.
+func (r *ReconciliationRepository) RebuildQty(itemIDs []uuid.UUID, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermReconcile); err != nil {
+		return err
+	}
+
+	batchID := uuid.New()
+	now := time.Now()
+	query := `UPDATE items
+			  SET qty = (SELECT COALESCE(SUM(qty), 0) FROM stock_mutations WHERE item_id = $1),
+			      updated_at = $2, updated_by = $3, version = version + 1
+			  WHERE id = $1`
+
+	for _, id := range itemIDs {
+		var newQty float64
+		_, err = tx.Exec(query, id, now, updatedBy)
+		if err != nil {
+			return err
+		}
+		err = tx.Get(&newQty, `SELECT qty FROM items WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		if err := r.costing.Sync(tx, id, newQty, now, batchID, "adjustment"); err != nil {
+			return err
+		}
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PostAdjustments keeps items.qty as the source of truth and inserts one
+// 'adjustment' mutation per item so the ledger balance matches it again.
+// All mutations of one call share the same model_id.
+func (r *ReconciliationRepository) PostAdjustments(itemIDs []uuid.UUID, createdBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, createdBy, models.PermReconcile); err != nil {
+		return err
+	}
+
+	batchID := uuid.New()
+	now := time.Now()
+	period := now.Format("2006-01")
+
+	for _, id := range itemIDs {
+		var qty float64
+		err = tx.Get(&qty, `SELECT qty FROM items WHERE id = $1 FOR UPDATE`, id)
+		if err != nil {
+			return err
+		}
+
+		// items.qty stays, so the cost layers are brought in line with it
+		if err := r.costing.Sync(tx, id, qty, now, batchID, "adjustment"); err != nil {
+			return err
+		}
+
+		var ledgerQty float64
+		err = tx.Get(&ledgerQty, `SELECT COALESCE(SUM(qty), 0) FROM stock_mutations WHERE item_id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		diff := qty - ledgerQty
+		if diff < ledgerTolerance && diff > -ledgerTolerance {
+			continue
+		}
+
+		mutationQuery := `INSERT INTO stock_mutations 
+						  (id, item_id, period, trx_date, qty, model_id, model_type, created_at) 
+						  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+		_, err = tx.Exec(mutationQuery, uuid.New(), id, period, now, diff, batchID, "adjustment", now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := notify.Publish(tx, notify.TopicItems); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
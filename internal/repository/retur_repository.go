@@ -5,22 +5,30 @@ import (
 	"fmt"
 	"time"
 
+	"fyne-app/internal/audit"
+	"fyne-app/internal/costing"
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type ReturRepository struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	audit     *audit.Logger
+	numbering *numbering.Service
+	costing   *costing.Service
 }
 
-func NewReturRepository(db *sqlx.DB) *ReturRepository {
-	return &ReturRepository{db: db}
+func NewReturRepository(db *sqlx.DB, auditLogger *audit.Logger, numberingService *numbering.Service, costingService *costing.Service) *ReturRepository {
+	return &ReturRepository{db: db, audit: auditLogger, numbering: numberingService, costing: costingService}
 }
 
 // Create inserts a new ReturPembelian header and its details within a database transaction.
-// It also updates the stock quantity of the associated items (qty = qty - retur_qty).
+// It also updates the stock quantity of the associated items (qty = qty - retur_qty)
+// and records the matching 'retur' rows in stock_mutations.
 // Returns the newly created header ID, or an error if any step fails.
 func (r *ReturRepository) Create(header *models.ReturHeader, details []models.ReturDetail) (uuid.UUID, error) {
 	// Start transaction
@@ -32,13 +40,26 @@ func (r *ReturRepository) Create(header *models.ReturHeader, details []models.Re
 	// Defer rollback, it will be ignored if tx.Commit() succeeds
 	defer tx.Rollback()
 
+	if err := r.lockPurchase(tx, header); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Nomor nota dialokasikan di transaksi yang sama dengan penyimpanan nota
+	if header.ReturInvoiceNum == "" {
+		invoiceNum, err := r.numbering.Next(tx, numbering.DocRetur, header.ReturDate)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		header.ReturInvoiceNum = invoiceNum
+	}
+
 	// 1. Insert data ke tabel retur_headers
 	header.ID = uuid.New()
 	header.CreatedAt = time.Now()
 
 	headerQuery := `INSERT INTO retur_headers 
-		(id, retur_invoice_num, retur_date, supplier_name, total_amount, status, created_at, created_by) 
-		VALUES ($1, $2, $3, $4, $5, 'ACTIVE', $6, $7)`
+		(id, retur_invoice_num, retur_date, supplier_name, supplier_id, purchase_id, total_amount, status, created_at, created_by) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'ACTIVE', $8, $9)`
 
 	_, err = tx.Exec(
 		headerQuery,
@@ -46,61 +67,34 @@ func (r *ReturRepository) Create(header *models.ReturHeader, details []models.Re
 		header.ReturInvoiceNum,
 		header.ReturDate,
 		header.SupplierName,
+		header.SupplierID,
+		header.PurchaseID,
 		header.TotalAmount,
 		header.CreatedAt,
 		header.CreatedBy,
 	)
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, mapDBError(err)
 	}
 
-	// 2. Lakukan perulangan untuk INSERT retur_details
-	for i := range details {
-		detail := &details[i]
-		detail.ID = uuid.New()
-		detail.HeaderID = header.ID // menggunakan header_id dari langkah 1
-		detail.CreatedAt = header.CreatedAt
-
-		detailQuery := `INSERT INTO retur_details 
-			(id, header_id, item_id, qty, price_amount, total_amount, created_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7)`
-
-		_, err = tx.Exec(
-			detailQuery,
-			detail.ID,
-			detail.HeaderID,
-			detail.ItemID,
-			detail.Qty,
-			detail.PriceAmount,
-			detail.TotalAmount,
-			detail.CreatedAt,
-		)
-		if err != nil {
-			return uuid.Nil, err
-		}
-
-		// Validasi apakah qty retur melebihi qty stok saat ini
-		if detail.Qty <= 0 {
-			return uuid.Nil, errors.New("retur gagal: jumlah retur harus lebih besar dari 0")
-		}
-
-		var currentQty float64
-		err = tx.Get(&currentQty, `SELECT qty FROM items WHERE id = $1`, detail.ItemID)
-		if err != nil {
-			return uuid.Nil, fmt.Errorf("gagal mengecek stok barang: %v", err)
-		}
+	// 2. Insert retur_details, kurangi stok dan catat mutasi
+	if err := r.insertDetails(tx, header, details); err != nil {
+		return uuid.Nil, err
+	}
 
-		if detail.Qty > currentQty {
-			return uuid.Nil, errors.New("retur gagal: jumlah retur melebihi stok yang tersedia saat ini")
-		}
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  header.CreatedBy,
+		Channel: audit.ChannelRetur,
+		Message: "Buat nota retur pembelian " + header.ReturInvoiceNum,
+		After:   models.ReturFull{Header: *header, Details: details},
+		Extra:   map[string]interface{}{"id": header.ID},
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
 
-		// 3. Lakukan UPDATE ke tabel items untuk mengurangi qty
-		// Logika: retur pembelian berarti barang dikembalikan ke supplier, sehingga stock gudang berkurang
-		updateItemQuery := `UPDATE items SET qty = qty - $1 WHERE id = $2`
-		_, err = tx.Exec(updateItemQuery, detail.Qty, detail.ItemID)
-		if err != nil {
-			return uuid.Nil, err
-		}
+	if err := notify.Publish(tx, notify.TopicRetur, notify.TopicItems, notify.TopicPayable); err != nil {
+		return uuid.Nil, err
 	}
 
 	// Jika semua berhasil, commit transaksi
@@ -112,9 +106,47 @@ func (r *ReturRepository) Create(header *models.ReturHeader, details []models.Re
 	return header.ID, nil
 }
 
+// lockPurchase locks the nota pembelian a retur is linked to, so payments and
+// other returs against it are checked one after the other. The nota must be
+// ACTIVE, belong to the supplier of the retur and still owe at least the retur
+// total, otherwise the hutang of the nota would turn negative.
+func (r *ReturRepository) lockPurchase(tx *sqlx.Tx, header *models.ReturHeader) error {
+	if header.PurchaseID == nil {
+		return nil
+	}
+
+	var purchase models.PurchaseHeader
+	err := tx.Get(&purchase, `SELECT id, purchase_invoice_num, supplier_id, total_amount, status
+		FROM purchase_headers WHERE id = $1 FOR UPDATE`, *header.PurchaseID)
+	if err != nil {
+		return err
+	}
+	if purchase.Status != "ACTIVE" {
+		return errors.New("nota pembelian sudah di-void")
+	}
+	if header.SupplierID == nil || purchase.SupplierID == nil || *header.SupplierID != *purchase.SupplierID {
+		return errors.New("nota pembelian bukan milik supplier retur ini")
+	}
+
+	var settled float64
+	err = tx.Get(&settled, `SELECT
+		COALESCE((SELECT SUM(amount) FROM purchase_payments WHERE purchase_id = $1 AND status = 'ACTIVE'), 0) +
+		COALESCE((SELECT SUM(total_amount) FROM retur_headers WHERE purchase_id = $1 AND status = 'ACTIVE' AND id <> $2), 0)`,
+		purchase.ID, header.ID)
+	if err != nil {
+		return err
+	}
+
+	balance := purchase.TotalAmount - settled
+	if header.TotalAmount > balance+0.005 {
+		return &ReturExceedsPayableError{InvoiceNum: purchase.PurchaseInvoiceNum, Balance: balance, Requested: header.TotalAmount}
+	}
+	return nil
+}
+
 // Update modifies an existing ReturPembelian header and its details within a database transaction.
-// It reverts the old stock changes, deletes old details, updates the header, inserts new details,
-// and applies new stock changes. Returns an error if any step fails.
+// It reverts the old stock changes, deletes old details and 'retur' mutations, updates the header,
+// inserts new details, and applies new stock changes and mutations. Returns an error if any step fails.
 func (r *ReturRepository) Update(header *models.ReturHeader, details []models.ReturDetail) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
@@ -122,20 +154,43 @@ func (r *ReturRepository) Update(header *models.ReturHeader, details []models.Re
 	}
 	defer tx.Rollback()
 
+	if err := lockActiveVersion(tx, "retur_headers", "Nota retur", header.ID, header.Version); err != nil {
+		return err
+	}
+
+	before, err := r.getByID(tx, header.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.lockPurchase(tx, header); err != nil {
+		return err
+	}
+
 	// 1. Dapatkan detail lama untuk mengembalikan stok
 	var oldDetails []models.ReturDetail
-	err = tx.Select(&oldDetails, `SELECT item_id, qty FROM retur_details WHERE header_id = $1`, header.ID)
+	err = tx.Select(&oldDetails, `SELECT item_id, qty, price_amount FROM retur_details WHERE header_id = $1`, header.ID)
 	if err != nil {
 		return fmt.Errorf("gagal mengambil detail lama: %v", err)
 	}
 
-	// 2. Kembalikan stok lama (karena retur memotong stok, kita kembalikan dengan menambah)
+	// 2. Kembalikan stok dan HPP lama (karena retur memotong stok, kita kembalikan dengan menambah) dan hapus mutasi lama
+	if err := r.costing.Restore(tx, header.ID, "retur"); err != nil {
+		return err
+	}
 	for _, d := range oldDetails {
-		_, err = tx.Exec(`UPDATE items SET qty = qty + $1 WHERE id = $2`, d.Qty, d.ItemID)
+		if err := applyAvgCost(tx, d.ItemID, d.Qty, d.PriceAmount, before.Header.ReturDate, header.ID, "retur"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
 		if err != nil {
 			return fmt.Errorf("gagal mengembalikan stok lama: %v", err)
 		}
 	}
+	_, err = tx.Exec(`DELETE FROM stock_mutations WHERE model_id = $1 AND model_type = 'retur'`, header.ID)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus mutasi lama: %v", err)
+	}
 
 	// 3. Hapus detail lama
 	_, err = tx.Exec(`DELETE FROM retur_details WHERE header_id = $1`, header.ID)
@@ -144,20 +199,64 @@ func (r *ReturRepository) Update(header *models.ReturHeader, details []models.Re
 	}
 
 	// 4. Update header
+	now := time.Now()
+	header.UpdatedAt = &now
 	headerQuery := `UPDATE retur_headers 
-		SET retur_invoice_num = $1, retur_date = $2, supplier_name = $3, total_amount = $4, updated_at = $5, updated_by = $6 
-		WHERE id = $7`
-	_, err = tx.Exec(headerQuery, header.ReturInvoiceNum, header.ReturDate, header.SupplierName, header.TotalAmount, header.UpdatedAt, header.UpdatedBy, header.ID)
+		SET retur_invoice_num = $1, retur_date = $2, supplier_name = $3, supplier_id = $4, purchase_id = $5, total_amount = $6, updated_at = $7, updated_by = $8, version = version + 1 
+		WHERE id = $9`
+	_, err = tx.Exec(headerQuery, header.ReturInvoiceNum, header.ReturDate, header.SupplierName, header.SupplierID, header.PurchaseID, header.TotalAmount, header.UpdatedAt, header.UpdatedBy, header.ID)
 	if err != nil {
-		return fmt.Errorf("gagal memperbarui header: %v", err)
+		return fmt.Errorf("gagal memperbarui header: %w", mapDBError(err))
 	}
+	header.Version++
 
-	// 5. Insert detail baru dan update stok baru
+	// 5. Insert detail baru, update stok baru dan catat mutasi baru
+	if err := r.insertDetails(tx, header, details); err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  header.UpdatedBy,
+		Channel: audit.ChannelRetur,
+		Message: "Ubah nota retur pembelian " + header.ReturInvoiceNum,
+		Before:  before,
+		After:   models.ReturFull{Header: *header, Details: details},
+		Extra:   map[string]interface{}{"id": header.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicRetur, notify.TopicItems, notify.TopicPayable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertDetails inserts retur_details rows, validates and subtracts the returned qty
+// from items.qty and records a negative 'retur' stock mutation for every line.
+func (r *ReturRepository) insertDetails(tx *sqlx.Tx, header *models.ReturHeader, details []models.ReturDetail) error {
 	for i := range details {
 		detail := &details[i]
 		detail.ID = uuid.New()
 		detail.HeaderID = header.ID
-		detail.CreatedAt = *header.UpdatedAt // use updated_at as created_at for details since they are new
+		detail.CreatedAt = time.Now()
+
+		// Validasi apakah qty retur melebihi qty stok saat ini
+		if detail.Qty <= 0 {
+			return errors.New("retur gagal: jumlah retur harus lebih besar dari 0")
+		}
+
+		var currentQty float64
+		err := tx.Get(&currentQty, `SELECT qty FROM items WHERE id = $1`, detail.ItemID)
+		if err != nil {
+			return fmt.Errorf("gagal mengecek stok barang: %v", err)
+		}
+
+		if detail.Qty > currentQty {
+			return errors.New("retur gagal: jumlah retur melebihi stok yang tersedia saat ini")
+		}
 
 		detailQuery := `INSERT INTO retur_details 
 			(id, header_id, item_id, qty, price_amount, total_amount, created_at) 
@@ -174,40 +273,99 @@ func (r *ReturRepository) Update(header *models.ReturHeader, details []models.Re
 			detail.CreatedAt,
 		)
 		if err != nil {
-			return fmt.Errorf("gagal menyimpan detail baru: %v", err)
+			return fmt.Errorf("gagal menyimpan detail: %v", err)
 		}
 
-		// Validasi stok baru
-		if detail.Qty <= 0 {
-			return errors.New("retur gagal: jumlah retur harus lebih besar dari 0")
+		// Logika: retur pembelian berarti barang dikembalikan ke supplier, sehingga stock gudang berkurang
+		// dan keluar dari HPP seharga nota retur
+		if err := applyAvgCost(tx, detail.ItemID, -detail.Qty, detail.PriceAmount, header.ReturDate, header.ID, "retur"); err != nil {
+			return err
 		}
-
-		var currentQty float64
-		err = tx.Get(&currentQty, `SELECT qty FROM items WHERE id = $1`, detail.ItemID)
+		// Layer dari nota pembelian yang diretur dipakai lebih dulu
+		if err := r.costing.ConsumeFor(tx, detail.ItemID, detail.Qty, header.PurchaseID, header.ReturDate, header.ID, "retur"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, detail.Qty, detail.ItemID)
 		if err != nil {
-			return fmt.Errorf("gagal mengecek stok barang: %v", err)
+			return fmt.Errorf("gagal memperbarui stok barang: %v", err)
 		}
 
-		if detail.Qty > currentQty {
-			return errors.New("retur gagal: jumlah retur melebihi stok yang tersedia saat ini")
+		// Insert stock mutation (negative quantity for retur)
+		if err := insertReturMutation(tx, header, detail.ItemID, detail.Qty); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+func insertReturMutation(tx *sqlx.Tx, header *models.ReturHeader, itemID uuid.UUID, qty float64) error {
+	period := header.ReturDate.Format("2006-01")
+	mutationQuery := `INSERT INTO stock_mutations 
+					  (id, item_id, period, trx_date, qty, model_id, model_type, created_at) 
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := tx.Exec(mutationQuery, uuid.New(), itemID, period,
+		header.ReturDate, -qty, header.ID, "retur", time.Now())
+	if err != nil {
+		return fmt.Errorf("gagal mencatat mutasi stok: %v", err)
+	}
+	return nil
+}
 
-		// Update stok baru (kurangi stok)
-		updateItemQuery := `UPDATE items SET qty = qty - $1 WHERE id = $2`
-		_, err = tx.Exec(updateItemQuery, detail.Qty, detail.ItemID)
+// BackfillMutations is a one-off routine for retur documents created before retur
+// mutations were recorded. For every retur header (ACTIVE or VOID) that has no
+// 'retur' row in stock_mutations, it inserts the missing mutations from its details.
+// items.qty is left untouched because the stock was already reduced at the time.
+// Returns the number of documents that were backfilled.
+func (r *ReturRepository) BackfillMutations() (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var headers []models.ReturHeader
+	query := `SELECT rh.id, rh.retur_invoice_num, rh.retur_date, rh.supplier_name, rh.supplier_id, rh.purchase_id, rh.total_amount,
+			  rh.status, rh.created_at, rh.updated_at, rh.created_by, rh.updated_by, rh.version
+			  FROM retur_headers rh
+			  WHERE NOT EXISTS (
+				  SELECT 1 FROM stock_mutations sm
+				  WHERE sm.model_id = rh.id AND sm.model_type = 'retur'
+			  )
+			  ORDER BY rh.retur_date, rh.created_at`
+
+	err = tx.Select(&headers, query)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range headers {
+		header := &headers[i]
+
+		var details []models.ReturDetail
+		err = tx.Select(&details, `SELECT item_id, qty FROM retur_details WHERE header_id = $1`, header.ID)
 		if err != nil {
-			return fmt.Errorf("gagal memperbarui stok barang: %v", err)
+			return 0, err
+		}
+
+		for _, d := range details {
+			if err := insertReturMutation(tx, header, d.ItemID, d.Qty); err != nil {
+				return 0, err
+			}
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(headers), nil
 }
 
 // GetAll retrieves all Retur headers
 func (r *ReturRepository) GetAll() ([]models.ReturHeader, error) {
 	var headers []models.ReturHeader
-	query := `SELECT id, retur_invoice_num, retur_date, supplier_name, total_amount, 
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, retur_invoice_num, retur_date, supplier_name, supplier_id, purchase_id, total_amount, 
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM retur_headers 
 			  ORDER BY retur_date DESC`
 
@@ -217,15 +375,20 @@ func (r *ReturRepository) GetAll() ([]models.ReturHeader, error) {
 
 // GetByID retrieves a single Retur request including details
 func (r *ReturRepository) GetByID(id uuid.UUID) (*models.ReturFull, error) {
+	return r.getByID(r.db, id)
+}
+
+// getByID reads the document through q so it can run inside a transaction
+func (r *ReturRepository) getByID(q sqlx.Queryer, id uuid.UUID) (*models.ReturFull, error) {
 	var retur models.ReturFull
 
 	// Get header
-	headerQuery := `SELECT id, retur_invoice_num, retur_date, supplier_name, total_amount,
-					status, created_at, updated_at, created_by, updated_by 
+	headerQuery := `SELECT id, retur_invoice_num, retur_date, supplier_name, supplier_id, purchase_id, total_amount,
+					status, created_at, updated_at, created_by, updated_by, version 
 					FROM retur_headers 
 					WHERE id = $1`
 
-	err := r.db.Get(&retur.Header, headerQuery, id)
+	err := sqlx.Get(q, &retur.Header, headerQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -236,7 +399,7 @@ func (r *ReturRepository) GetByID(id uuid.UUID) (*models.ReturFull, error) {
 					FROM retur_details 
 					WHERE header_id = $1`
 
-	err = r.db.Select(&retur.Details, detailQuery, id)
+	err = sqlx.Select(q, &retur.Details, detailQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -247,8 +410,8 @@ func (r *ReturRepository) GetByID(id uuid.UUID) (*models.ReturFull, error) {
 // GetByInvoiceNum retrieves a Retur header by its invoice number
 func (r *ReturRepository) GetByInvoiceNum(invoiceNum string) (*models.ReturHeader, error) {
 	var header models.ReturHeader
-	query := `SELECT id, retur_invoice_num, retur_date, supplier_name, total_amount, 
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, retur_invoice_num, retur_date, supplier_name, supplier_id, purchase_id, total_amount, 
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM retur_headers 
 			  WHERE retur_invoice_num = $1`
 
@@ -262,8 +425,8 @@ func (r *ReturRepository) GetByInvoiceNum(invoiceNum string) (*models.ReturHeade
 // Search retrieves Retur headers by invoice number or supplier name
 func (r *ReturRepository) Search(keyword string) ([]models.ReturHeader, error) {
 	var headers []models.ReturHeader
-	query := `SELECT id, retur_invoice_num, retur_date, supplier_name, total_amount,
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, retur_invoice_num, retur_date, supplier_name, supplier_id, purchase_id, total_amount,
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM retur_headers 
 			  WHERE LOWER(retur_invoice_num) LIKE LOWER($1) 
 			  OR LOWER(supplier_name) LIKE LOWER($1)
@@ -282,23 +445,47 @@ func (r *ReturRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 	}
 	defer tx.Rollback()
 
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM retur_headers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if status == "VOID" {
+		return errors.New("nota retur pembelian sudah di-void")
+	}
+
+	before, err := r.getByID(tx, id)
+	if err != nil {
+		return err
+	}
+
 	// 1. Dapatkan detail item yang diretur
 	var details []models.ReturDetail
-	err = tx.Select(&details, `SELECT item_id, qty FROM retur_details WHERE header_id = $1`, id)
+	err = tx.Select(&details, `SELECT item_id, qty, price_amount FROM retur_details WHERE header_id = $1`, id)
 	if err != nil {
 		return err
 	}
 
-	// 2. Kembalikan stok (tambah stok gudang karena batal kembalikan ke supplier)
+	// 2. Kembalikan stok dan HPP (tambah stok gudang karena batal kembalikan ke supplier)
+	now := time.Now()
+	if err := r.costing.Restore(tx, id, "retur"); err != nil {
+		return err
+	}
 	for _, d := range details {
-		_, err = tx.Exec(`UPDATE items SET qty = qty + $1 WHERE id = $2`, d.Qty, d.ItemID)
+		if err := applyAvgCost(tx, d.ItemID, d.Qty, d.PriceAmount, now, id, "void_retur"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
 		if err != nil {
 			return err
 		}
 	}
 
 	// 3. Catat mutasi pembatalan
-	now := time.Now()
 	period := now.Format("2006-01")
 	for _, d := range details {
 		mutationID := uuid.New()
@@ -314,10 +501,27 @@ func (r *ReturRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 	}
 
 	// 4. Update status header menjadi VOID
-	_, err = tx.Exec(`UPDATE retur_headers SET status = 'VOID', updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	_, err = tx.Exec(`UPDATE retur_headers SET status = 'VOID', version = version + 1, updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &updatedBy,
+		Channel: audit.ChannelRetur,
+		Message: "Void nota retur pembelian " + before.Header.ReturInvoiceNum,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		After:   map[string]string{"status": "VOID"},
+		Extra:   map[string]interface{}{"id": id},
+	})
 	if err != nil {
 		return err
 	}
 
+	if err := notify.Publish(tx, notify.TopicRetur, notify.TopicItems, notify.TopicPayable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
@@ -1,20 +1,30 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"fyne-app/internal/audit"
+	"fyne-app/internal/costing"
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type SellRepository struct {
-	db *sqlx.DB
+	db        *sqlx.DB
+	audit     *audit.Logger
+	numbering *numbering.Service
+	costing   *costing.Service
 }
 
-func NewSellRepository(db *sqlx.DB) *SellRepository {
-	return &SellRepository{db: db}
+func NewSellRepository(db *sqlx.DB, auditLogger *audit.Logger, numberingService *numbering.Service, costingService *costing.Service) *SellRepository {
+	return &SellRepository{db: db, audit: auditLogger, numbering: numberingService, costing: costingService}
 }
 
 func (r *SellRepository) Delete(id uuid.UUID) error {
@@ -25,8 +35,8 @@ func (r *SellRepository) Delete(id uuid.UUID) error {
 // GetByInvoiceNum retrieves a Sell header by its invoice number
 func (r *SellRepository) GetByInvoiceNum(invoiceNum string) (*models.SellHeader, error) {
 	var header models.SellHeader
-	query := `SELECT id, sell_invoice_num, sell_date, customer_name, total_amount, 
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, sell_invoice_num, sell_date, customer_name, customer_id, total_amount, payment_method, payment_term_days, due_date, 
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM sell_headers 
 			  WHERE sell_invoice_num = $1`
 
@@ -44,13 +54,29 @@ func (r *SellRepository) Create(sell *models.SellFull) error {
 	}
 	defer tx.Rollback()
 
+	// Nomor nota dialokasikan di transaksi yang sama dengan penyimpanan nota
+	if sell.Header.SellInvoiceNum == "" {
+		invoiceNum, err := r.numbering.Next(tx, numbering.DocSell, sell.Header.SellDate)
+		if err != nil {
+			return err
+		}
+		sell.Header.SellInvoiceNum = invoiceNum
+	}
+
 	// Insert header
 	sell.Header.ID = uuid.New()
 	sell.Header.CreatedAt = time.Now()
+	setPaymentTerms(&sell.Header)
+
+	if sell.Header.PaymentMethod == models.SellPaymentCredit {
+		if err := r.checkCreditLimit(tx, &sell.Header, 0); err != nil {
+			return err
+		}
+	}
 
 	headerQuery := `INSERT INTO sell_headers 
-		(id, sell_invoice_num, sell_date, customer_name, total_amount, status, created_at, created_by) 
-		VALUES ($1, $2, $3, $4, $5, 'ACTIVE', $6, $7)`
+		(id, sell_invoice_num, sell_date, customer_name, customer_id, total_amount, payment_method, payment_term_days, due_date, status, created_at, created_by) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'ACTIVE', $10, $11)`
 
 	_, err = tx.Exec(
 		headerQuery,
@@ -58,12 +84,16 @@ func (r *SellRepository) Create(sell *models.SellFull) error {
 		sell.Header.SellInvoiceNum,
 		sell.Header.SellDate,
 		sell.Header.CustomerName,
+		sell.Header.CustomerID,
 		sell.Header.TotalAmount,
+		sell.Header.PaymentMethod,
+		sell.Header.PaymentTermDays,
+		sell.Header.DueDate,
 		sell.Header.CreatedAt,
 		sell.Header.CreatedBy,
 	)
 	if err != nil {
-		return err
+		return mapDBError(err)
 	}
 
 	// Insert details (may be empty — that's OK)
@@ -72,6 +102,21 @@ func (r *SellRepository) Create(sell *models.SellFull) error {
 	}
 
 	// ✅ COMMIT IS REQUIRED
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  sell.Header.CreatedBy,
+		Channel: audit.ChannelSell,
+		Message: "Buat nota penjualan " + sell.Header.SellInvoiceNum,
+		After:   sell,
+		Extra:   map[string]interface{}{"id": sell.Header.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSell, notify.TopicItems, notify.TopicReceivable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -82,20 +127,56 @@ func (r *SellRepository) Update(sell *models.SellFull) error {
 	}
 	defer tx.Rollback()
 
+	if err := lockActiveVersion(tx, "sell_headers", "Nota penjualan", sell.Header.ID, sell.Header.Version); err != nil {
+		return err
+	}
+
+	before, err := r.getByID(tx, sell.Header.ID)
+	if err != nil {
+		return err
+	}
+
+	// Retur penjualan mengacu ke qty nota ini, jadi nota harus tetap seperti saat diretur
+	if err := checkNoSellReturs(tx, sell.Header.ID); err != nil {
+		return err
+	}
+
+	// Pembayaran yang sudah masuk harus tetap tercakup oleh nota kredit ini
+	setPaymentTerms(&sell.Header)
+	var paid float64
+	err = tx.Get(&paid, `SELECT COALESCE(SUM(amount), 0) FROM sell_payments WHERE sell_id = $1 AND status = 'ACTIVE'`, sell.Header.ID)
+	if err != nil {
+		return err
+	}
+	if paid > 0 && sell.Header.PaymentMethod != models.SellPaymentCredit {
+		return ErrHasPayments
+	}
+	if sell.Header.TotalAmount < paid-0.005 {
+		return ErrTotalBelowPaid
+	}
+	if sell.Header.PaymentMethod == models.SellPaymentCredit {
+		if err := r.checkCreditLimit(tx, &sell.Header, paid); err != nil {
+			return err
+		}
+	}
+
 	// 1. Get old details to revert stock
 	var oldDetails []models.SellDetail
-	err = tx.Select(&oldDetails, `SELECT item_id, qty FROM sell_details WHERE header_id = $1`, sell.Header.ID)
+	err = tx.Select(&oldDetails, `SELECT item_id, qty FROM sell_details WHERE header_id = $1 ORDER BY item_id`, sell.Header.ID)
 	if err != nil {
 		return err
 	}
 
-	// 2. Revert stock and delete old mutations
+	// 2. Revert stock and cost layers, then delete old mutations
 	for _, d := range oldDetails {
-		_, err = tx.Exec(`UPDATE items SET qty = qty + $1 WHERE id = $2`, d.Qty, d.ItemID)
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
 		if err != nil {
 			return err
 		}
 	}
+	if err := r.costing.RestoreSale(tx, sell.Header.ID); err != nil {
+		return err
+	}
 	_, err = tx.Exec(`DELETE FROM stock_mutations WHERE model_id = $1 AND model_type = 'sell'`, sell.Header.ID)
 	if err != nil {
 		return err
@@ -111,16 +192,19 @@ func (r *SellRepository) Update(sell *models.SellFull) error {
 	now := time.Now()
 	sell.Header.UpdatedAt = &now
 	headerQuery := `UPDATE sell_headers SET 
-					sell_invoice_num = $1, sell_date = $2, customer_name = $3, total_amount = $4, 
-					updated_at = $5, updated_by = $6 
-					WHERE id = $7`
+					sell_invoice_num = $1, sell_date = $2, customer_name = $3, customer_id = $4, total_amount = $5, 
+					payment_method = $6, payment_term_days = $7, due_date = $8,
+					updated_at = $9, updated_by = $10, version = version + 1 
+					WHERE id = $11`
 
 	_, err = tx.Exec(headerQuery, sell.Header.SellInvoiceNum, sell.Header.SellDate,
-		sell.Header.CustomerName, sell.Header.TotalAmount,
+		sell.Header.CustomerName, sell.Header.CustomerID, sell.Header.TotalAmount,
+		sell.Header.PaymentMethod, sell.Header.PaymentTermDays, sell.Header.DueDate,
 		sell.Header.UpdatedAt, sell.Header.UpdatedBy, sell.Header.ID)
 	if err != nil {
-		return err
+		return mapDBError(err)
 	}
+	sell.Header.Version++
 
 	// 5. Insert new details and update stock
 	err = r.insertDetails(tx, &sell.Header, sell.Details)
@@ -128,10 +212,130 @@ func (r *SellRepository) Update(sell *models.SellFull) error {
 		return err
 	}
 
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  sell.Header.UpdatedBy,
+		Channel: audit.ChannelSell,
+		Message: "Ubah nota penjualan " + sell.Header.SellInvoiceNum,
+		Before:  before,
+		After:   sell,
+		Extra:   map[string]interface{}{"id": sell.Header.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSell, notify.TopicItems, notify.TopicReceivable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// setPaymentTerms defaults the payment method to cash and derives the due date;
+// only credit sales have a payment term
+func setPaymentTerms(header *models.SellHeader) {
+	if header.PaymentMethod == "" {
+		header.PaymentMethod = models.SellPaymentCash
+	}
+	if header.PaymentMethod != models.SellPaymentCredit {
+		header.PaymentTermDays = 0
+	}
+	header.DueDate = header.SellDate.AddDate(0, 0, header.PaymentTermDays)
+}
+
+// checkCreditLimit locks the customer row, so concurrent credit sales to the
+// same customer are checked one after the other, and rejects the nota when the
+// open receivables of the customer plus the unpaid part of this nota exceed the
+// credit limit
+func (r *SellRepository) checkCreditLimit(tx *sqlx.Tx, header *models.SellHeader, paid float64) error {
+	requested := header.TotalAmount - paid
+	if header.CustomerID == nil {
+		return &CreditLimitError{Customer: header.CustomerName, Requested: requested}
+	}
+
+	var customer models.Customer
+	err := tx.Get(&customer, `SELECT "name", credit_limit FROM customers WHERE id = $1 FOR UPDATE`, *header.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	var outstanding float64
+	err = tx.Get(&outstanding, `SELECT COALESCE(SUM(balance), 0) FROM (`+receivableSelect+`) b
+		WHERE customer_id = $1 AND sell_id <> $2`, *header.CustomerID, header.ID)
+	if err != nil {
+		return err
+	}
+
+	if outstanding+requested > customer.CreditLimit+0.005 {
+		return &CreditLimitError{
+			Customer:    customer.Name,
+			Limit:       customer.CreditLimit,
+			Outstanding: outstanding,
+			Requested:   requested,
+		}
+	}
+	return nil
+}
+
+// checkNoSellReturs returns ErrHasReturns when the nota still has active retur penjualan
+func checkNoSellReturs(tx *sqlx.Tx, sellID uuid.UUID) error {
+	var returs int
+	err := tx.Get(&returs, `SELECT COUNT(*) FROM sell_retur_headers WHERE sell_id = $1 AND status = 'ACTIVE'`, sellID)
+	if err != nil {
+		return err
+	}
+	if returs > 0 {
+		return ErrHasReturns
+	}
+	return nil
+}
+
+// reserveStock locks the item rows of a nota and checks that every item has
+// enough stock, unless the item allows negative stock. Rows are locked in id
+// order so concurrent sales of the same items cannot deadlock.
+func (r *SellRepository) reserveStock(tx *sqlx.Tx, details []models.SellDetail) error {
+	requested := map[uuid.UUID]float64{}
+	ids := make([]string, 0, len(details))
+	for _, d := range details {
+		if _, ok := requested[d.ItemID]; !ok {
+			ids = append(ids, d.ItemID.String())
+		}
+		requested[d.ItemID] += d.Qty
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var items []models.Item
+	err := tx.Select(&items, `SELECT id, code, "name", qty, allow_negative_stock 
+		FROM items WHERE id = ANY($1::uuid[]) ORDER BY id FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("gagal mengunci stok barang: %v", err)
+	}
+
+	var shortages []StockShortage
+	for _, it := range items {
+		if !it.AllowNegativeStock && requested[it.ID] > it.Qty {
+			shortages = append(shortages, StockShortage{
+				ItemID:    it.ID,
+				Code:      it.Code,
+				Name:      it.Name,
+				Available: it.Qty,
+				Requested: requested[it.ID],
+			})
+		}
+	}
+	if len(shortages) > 0 {
+		return &InsufficientStockError{Items: shortages}
+	}
+	return nil
+}
+
 func (r *SellRepository) insertDetails(tx *sqlx.Tx, header *models.SellHeader, details []models.SellDetail) error {
+	if err := r.reserveStock(tx, details); err != nil {
+		return err
+	}
+
 	for i := range details {
 		detail := &details[i]
 		detail.ID = uuid.New()
@@ -148,8 +352,18 @@ func (r *SellRepository) insertDetails(tx *sqlx.Tx, header *models.SellHeader, d
 			return err
 		}
 
+		// Consume cost layers and keep the HPP of the line
+		detail.CogsAmount, err = r.costing.IssueSale(tx, detail.ID, detail.ItemID, detail.Qty, header.SellDate)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE sell_details SET cogs_amount = $1 WHERE id = $2`, detail.CogsAmount, detail.ID)
+		if err != nil {
+			return err
+		}
+
 		// Update item quantity (subtract for sales)
-		_, err = tx.Exec(`UPDATE items SET qty = qty - $1 WHERE id = $2`, detail.Qty, detail.ItemID)
+		_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, detail.Qty, detail.ItemID)
 		if err != nil {
 			return err
 		}
@@ -172,8 +386,8 @@ func (r *SellRepository) insertDetails(tx *sqlx.Tx, header *models.SellHeader, d
 
 func (r *SellRepository) GetAll() ([]models.SellHeader, error) {
 	var headers []models.SellHeader
-	query := `SELECT id, sell_invoice_num, sell_date, customer_name, total_amount, 
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, sell_invoice_num, sell_date, customer_name, customer_id, total_amount, payment_method, payment_term_days, due_date, 
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM sell_headers 
 			  ORDER BY sell_date DESC`
 
@@ -182,26 +396,31 @@ func (r *SellRepository) GetAll() ([]models.SellHeader, error) {
 }
 
 func (r *SellRepository) GetByID(id uuid.UUID) (*models.SellFull, error) {
+	return r.getByID(r.db, id)
+}
+
+// getByID reads the document through q so it can run inside a transaction
+func (r *SellRepository) getByID(q sqlx.Queryer, id uuid.UUID) (*models.SellFull, error) {
 	var sell models.SellFull
 
 	// Get header
-	headerQuery := `SELECT id, sell_invoice_num, sell_date, customer_name, total_amount,
-					status, created_at, updated_at, created_by, updated_by 
+	headerQuery := `SELECT id, sell_invoice_num, sell_date, customer_name, customer_id, total_amount, payment_method, payment_term_days, due_date,
+					status, created_at, updated_at, created_by, updated_by, version 
 					FROM sell_headers 
 					WHERE id = $1`
 
-	err := r.db.Get(&sell.Header, headerQuery, id)
+	err := sqlx.Get(q, &sell.Header, headerQuery, id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get details
-	detailQuery := `SELECT id, header_id, item_id, qty, price_amount, total_amount, 
+	detailQuery := `SELECT id, header_id, item_id, qty, price_amount, total_amount, cogs_amount,
 					created_at, updated_at 
 					FROM sell_details 
 					WHERE header_id = $1`
 
-	err = r.db.Select(&sell.Details, detailQuery, id)
+	err = sqlx.Select(q, &sell.Details, detailQuery, id)
 	if err != nil {
 		return nil, err
 	}
@@ -211,8 +430,8 @@ func (r *SellRepository) GetByID(id uuid.UUID) (*models.SellFull, error) {
 
 func (r *SellRepository) Search(keyword string) ([]models.SellHeader, error) {
 	var headers []models.SellHeader
-	query := `SELECT id, sell_invoice_num, sell_date, customer_name, total_amount,
-			  status, created_at, updated_at, created_by, updated_by 
+	query := `SELECT id, sell_invoice_num, sell_date, customer_name, customer_id, total_amount, payment_method, payment_term_days, due_date,
+			  status, created_at, updated_at, created_by, updated_by, version 
 			  FROM sell_headers 
 			  WHERE LOWER(sell_invoice_num) LIKE LOWER($1) 
 			  OR LOWER(customer_name) LIKE LOWER($1)
@@ -231,6 +450,37 @@ func (r *SellRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 	}
 	defer tx.Rollback()
 
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM sell_headers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if status == "VOID" {
+		return errors.New("nota penjualan sudah di-void")
+	}
+
+	before, err := r.getByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	// Pembayaran piutang harus dibatalkan dulu
+	var payments int
+	err = tx.Get(&payments, `SELECT COUNT(*) FROM sell_payments WHERE sell_id = $1 AND status = 'ACTIVE'`, id)
+	if err != nil {
+		return err
+	}
+	if payments > 0 {
+		return ErrHasPayments
+	}
+	if err := checkNoSellReturs(tx, id); err != nil {
+		return err
+	}
+
 	// 1. Dapatkan detail item yang dijual
 	var details []models.SellDetail
 	err = tx.Select(&details, `SELECT item_id, qty FROM sell_details WHERE header_id = $1`, id)
@@ -238,13 +488,16 @@ func (r *SellRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 		return err
 	}
 
-	// 2. Kembalikan stok (tambah stok gudang karena pelanggan batal beli)
+	// 2. Kembalikan stok dan layer HPP (tambah stok gudang karena pelanggan batal beli)
 	for _, d := range details {
-		_, err = tx.Exec(`UPDATE items SET qty = qty + $1 WHERE id = $2`, d.Qty, d.ItemID)
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
 		if err != nil {
 			return err
 		}
 	}
+	if err := r.costing.RestoreSale(tx, id); err != nil {
+		return err
+	}
 
 	// 3. Catat mutasi pembatalan
 	now := time.Now()
@@ -263,24 +516,50 @@ func (r *SellRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
 	}
 
 	// 4. Update status header menjadi VOID
-	_, err = tx.Exec(`UPDATE sell_headers SET status = 'VOID', updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	_, err = tx.Exec(`UPDATE sell_headers SET status = 'VOID', version = version + 1, updated_at = $1, updated_by = $2 WHERE id = $3 AND status != 'VOID'`, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &updatedBy,
+		Channel: audit.ChannelSell,
+		Message: "Void nota penjualan " + before.Header.SellInvoiceNum,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		After:   map[string]string{"status": "VOID"},
+		Extra:   map[string]interface{}{"id": id},
+	})
 	if err != nil {
 		return err
 	}
 
+	if err := notify.Publish(tx, notify.TopicSell, notify.TopicItems, notify.TopicReceivable); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// GetDailyReport totals the active sales per day; retur penjualan count as
+// negative transactions on their retur date so total_amount is the net sales
 func (r *SellRepository) GetDailyReport(startDate, endDate time.Time) ([]models.DailySalesReport, error) {
 	var reports []models.DailySalesReport
-	query := `SELECT DATE(sell_date) as sell_date, 
+	query := `SELECT t.trx_date as sell_date, 
 			  COUNT(*) as transaction_count, 
-			  COALESCE(SUM(total_amount), 0) as total_amount
-			  FROM sell_headers 
-			  WHERE status = 'ACTIVE'
-			  AND sell_date >= $1 AND sell_date <= $2
-			  GROUP BY DATE(sell_date)
-			  ORDER BY DATE(sell_date) DESC`
+			  COALESCE(SUM(t.amount), 0) as total_amount,
+			  COALESCE(SUM(t.amount) FILTER (WHERE t.is_retur), 0) as retur_amount
+			  FROM (
+				  SELECT DATE(sell_date) AS trx_date, total_amount AS amount, false AS is_retur
+				  FROM sell_headers
+				  WHERE status = 'ACTIVE' AND sell_date >= $1 AND sell_date <= $2
+				  UNION ALL
+				  SELECT retur_date, -total_amount, true
+				  FROM sell_retur_headers
+				  WHERE status = 'ACTIVE' AND retur_date >= DATE($1) AND retur_date <= $2
+			  ) t
+			  GROUP BY t.trx_date
+			  ORDER BY t.trx_date DESC`
 
 	err := r.db.Select(&reports, query, startDate, endDate)
 	return reports, err
@@ -288,8 +567,8 @@ func (r *SellRepository) GetDailyReport(startDate, endDate time.Time) ([]models.
 
 func (r *SellRepository) GetByDate(date time.Time) ([]models.SellHeader, error) {
 	var headers []models.SellHeader
-	query := `SELECT id, sell_invoice_num, sell_date, customer_name, total_amount,
-			  status, created_at, updated_at, created_by, updated_by
+	query := `SELECT id, sell_invoice_num, sell_date, customer_name, customer_id, total_amount, payment_method, payment_term_days, due_date,
+			  status, created_at, updated_at, created_by, updated_by, version
 			  FROM sell_headers
 			  WHERE DATE(sell_date) = DATE($1)
 			  ORDER BY created_at DESC`
@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/costing"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// sellReturSelect reads retur penjualan headers together with the number of their nota penjualan
+const sellReturSelect = `SELECT rh.id, rh.retur_invoice_num, rh.retur_date, rh.sell_id, sh.sell_invoice_num,
+		rh.customer_name, rh.customer_id, rh.total_amount,
+		rh.status, rh.created_at, rh.updated_at, rh.created_by, rh.updated_by, rh.version
+	FROM sell_retur_headers rh
+	JOIN sell_headers sh ON sh.id = rh.sell_id`
+
+// SellReturRepository keeps the retur penjualan: goods customers bring back
+// against a nota penjualan
+type SellReturRepository struct {
+	db        *sqlx.DB
+	audit     *audit.Logger
+	numbering *numbering.Service
+	costing   *costing.Service
+}
+
+func NewSellReturRepository(db *sqlx.DB, auditLogger *audit.Logger, numberingService *numbering.Service, costingService *costing.Service) *SellReturRepository {
+	return &SellReturRepository{db: db, audit: auditLogger, numbering: numberingService, costing: costingService}
+}
+
+// GetReturnable lists the items of a nota penjualan with the quantity sold and
+// the quantity already returned by active returns other than excludeReturID
+func (r *SellReturRepository) GetReturnable(sellID uuid.UUID, excludeReturID uuid.UUID) ([]models.SellReturnable, error) {
+	return r.getReturnable(r.db, sellID, excludeReturID)
+}
+
+func (r *SellReturRepository) getReturnable(q sqlx.Queryer, sellID uuid.UUID, excludeReturID uuid.UUID) ([]models.SellReturnable, error) {
+	var lines []models.SellReturnable
+	query := `SELECT d.item_id, i.code, i."name",
+			  SUM(d.qty) AS sold_qty,
+			  COALESCE(MAX(rt.qty), 0) AS returned_qty,
+			  SUM(d.total_amount) / NULLIF(SUM(d.qty), 0) AS price_amount
+			  FROM sell_details d
+			  JOIN items i ON i.id = d.item_id
+			  LEFT JOIN (
+				  SELECT rd.item_id, SUM(rd.qty) AS qty
+				  FROM sell_retur_details rd
+				  JOIN sell_retur_headers rh ON rh.id = rd.header_id
+				  WHERE rh.sell_id = $1 AND rh.status = 'ACTIVE' AND rh.id <> $2
+				  GROUP BY rd.item_id
+			  ) rt ON rt.item_id = d.item_id
+			  WHERE d.header_id = $1
+			  GROUP BY d.item_id, i.code, i."name"
+			  ORDER BY i.code`
+
+	err := sqlx.Select(q, &lines, query, sellID, excludeReturID)
+	return lines, err
+}
+
+// lockSell locks the nota penjualan so two returns against it are checked one
+// after the other, and fills the customer of the retur from it
+func (r *SellReturRepository) lockSell(tx *sqlx.Tx, header *models.SellReturHeader) error {
+	var sell models.SellHeader
+	err := tx.Get(&sell, `SELECT id, sell_invoice_num, customer_name, customer_id, status
+		FROM sell_headers WHERE id = $1 FOR UPDATE`, header.SellID)
+	if err != nil {
+		return err
+	}
+	if sell.Status != "ACTIVE" {
+		return errors.New("nota penjualan sudah di-void")
+	}
+	header.SellInvoiceNum = sell.SellInvoiceNum
+	header.CustomerName = sell.CustomerName
+	header.CustomerID = sell.CustomerID
+	return nil
+}
+
+// checkReturnable rejects details that return more of an item than was sold
+// on the nota minus what other active returns already took back. Every detail
+// is priced at what the customer paid for the item on the nota, and the retur
+// total is computed from them instead of taken from the caller.
+func (r *SellReturRepository) checkReturnable(tx *sqlx.Tx, header *models.SellReturHeader, details []models.SellReturDetail) error {
+	lines, err := r.getReturnable(tx, header.SellID, header.ID)
+	if err != nil {
+		return err
+	}
+	available := make(map[uuid.UUID]models.SellReturnable, len(lines))
+	for _, l := range lines {
+		available[l.ItemID] = l
+	}
+
+	requested := map[uuid.UUID]float64{}
+	var order []uuid.UUID
+	for _, d := range details {
+		if d.Qty <= 0 {
+			return errors.New("retur gagal: jumlah retur harus lebih besar dari 0")
+		}
+		if _, ok := available[d.ItemID]; !ok {
+			return errors.New("retur gagal: barang tidak terdapat di nota penjualan")
+		}
+		if _, ok := requested[d.ItemID]; !ok {
+			order = append(order, d.ItemID)
+		}
+		requested[d.ItemID] += d.Qty
+	}
+
+	var excess []ReturExcess
+	for _, id := range order {
+		l := available[id]
+		if requested[id] > l.Available()+0.0001 {
+			excess = append(excess, ReturExcess{
+				ItemID:    id,
+				Code:      l.Code,
+				Name:      l.Name,
+				Available: l.Available(),
+				Requested: requested[id],
+			})
+		}
+	}
+	if len(excess) > 0 {
+		return &ReturExceedsSoldError{Items: excess}
+	}
+
+	header.TotalAmount = 0
+	for i := range details {
+		details[i].PriceAmount = available[details[i].ItemID].PriceAmount
+		details[i].TotalAmount = details[i].Qty * details[i].PriceAmount
+		header.TotalAmount += details[i].TotalAmount
+	}
+	return nil
+}
+
+// saleUnitCost is the HPP per unit the item left the warehouse at on the nota
+// penjualan; returned goods come back into stock at that cost
+func saleUnitCost(tx *sqlx.Tx, sellID, itemID uuid.UUID) (float64, error) {
+	var unitCost float64
+	err := tx.Get(&unitCost, `SELECT COALESCE(SUM(cogs_amount) / NULLIF(SUM(qty), 0), 0)
+		FROM sell_details WHERE header_id = $1 AND item_id = $2`, sellID, itemID)
+	if err != nil {
+		return 0, fmt.Errorf("gagal membaca HPP penjualan: %v", err)
+	}
+	return unitCost, nil
+}
+
+// Create inserts a new retur penjualan and its details within a database transaction.
+// The returned goods are added back to items.qty and recorded as positive
+// 'sell_retur' rows in stock_mutations.
+func (r *SellReturRepository) Create(header *models.SellReturHeader, details []models.SellReturDetail) (uuid.UUID, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	header.ID = uuid.New()
+	header.CreatedAt = time.Now()
+
+	if err := r.lockSell(tx, header); err != nil {
+		return uuid.Nil, err
+	}
+	if err := r.checkReturnable(tx, header, details); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Nomor nota dialokasikan di transaksi yang sama dengan penyimpanan nota
+	if header.ReturInvoiceNum == "" {
+		invoiceNum, err := r.numbering.Next(tx, numbering.DocSellRetur, header.ReturDate)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		header.ReturInvoiceNum = invoiceNum
+	}
+
+	headerQuery := `INSERT INTO sell_retur_headers
+		(id, retur_invoice_num, retur_date, sell_id, customer_name, customer_id, total_amount, status, created_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'ACTIVE', $8, $9)`
+
+	_, err = tx.Exec(
+		headerQuery,
+		header.ID,
+		header.ReturInvoiceNum,
+		header.ReturDate,
+		header.SellID,
+		header.CustomerName,
+		header.CustomerID,
+		header.TotalAmount,
+		header.CreatedAt,
+		header.CreatedBy,
+	)
+	if err != nil {
+		return uuid.Nil, mapDBError(err)
+	}
+
+	if err := r.insertDetails(tx, header, details); err != nil {
+		return uuid.Nil, err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  header.CreatedBy,
+		Channel: audit.ChannelSellRetur,
+		Message: "Buat nota retur penjualan " + header.ReturInvoiceNum + " atas " + header.SellInvoiceNum,
+		After:   models.SellReturFull{Header: *header, Details: details},
+		Extra:   map[string]interface{}{"id": header.ID, "sell_id": header.SellID},
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSellRetur, notify.TopicItems, notify.TopicReceivable); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, err
+	}
+	return header.ID, nil
+}
+
+// Update replaces the details of a retur penjualan: the old stock and
+// 'sell_retur' mutations are reverted before the new details are applied.
+// The linked nota penjualan cannot be changed.
+func (r *SellReturRepository) Update(header *models.SellReturHeader, details []models.SellReturDetail) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockActiveVersion(tx, "sell_retur_headers", "Nota retur penjualan", header.ID, header.Version); err != nil {
+		return err
+	}
+
+	before, err := r.getByID(tx, header.ID)
+	if err != nil {
+		return err
+	}
+
+	header.SellID = before.Header.SellID
+	if err := r.lockSell(tx, header); err != nil {
+		return err
+	}
+	if err := r.checkReturnable(tx, header, details); err != nil {
+		return err
+	}
+
+	// 1. Kembalikan stok dan HPP lama (retur penjualan menambah stok, jadi dikurangi lagi) dan hapus mutasi lama
+	for _, d := range before.Details {
+		unitCost, err := saleUnitCost(tx, header.SellID, d.ItemID)
+		if err != nil {
+			return err
+		}
+		if err := applyAvgCost(tx, d.ItemID, -d.Qty, unitCost, before.Header.ReturDate, header.ID, "sell_retur"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
+		if err != nil {
+			return fmt.Errorf("gagal mengembalikan stok lama: %v", err)
+		}
+	}
+	if err := r.costing.CloseLayers(tx, header.ID, "sell_retur"); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM stock_mutations WHERE model_id = $1 AND model_type = 'sell_retur'`, header.ID)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus mutasi lama: %v", err)
+	}
+
+	// 2. Hapus detail lama
+	_, err = tx.Exec(`DELETE FROM sell_retur_details WHERE header_id = $1`, header.ID)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus detail lama: %v", err)
+	}
+
+	// 3. Update header
+	now := time.Now()
+	header.UpdatedAt = &now
+	headerQuery := `UPDATE sell_retur_headers
+		SET retur_invoice_num = $1, retur_date = $2, total_amount = $3, updated_at = $4, updated_by = $5, version = version + 1
+		WHERE id = $6`
+	_, err = tx.Exec(headerQuery, header.ReturInvoiceNum, header.ReturDate, header.TotalAmount, header.UpdatedAt, header.UpdatedBy, header.ID)
+	if err != nil {
+		return fmt.Errorf("gagal memperbarui header: %w", mapDBError(err))
+	}
+	header.Version++
+
+	// 4. Insert detail baru, tambah stok dan catat mutasi baru
+	if err := r.insertDetails(tx, header, details); err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  header.UpdatedBy,
+		Channel: audit.ChannelSellRetur,
+		Message: "Ubah nota retur penjualan " + header.ReturInvoiceNum,
+		Before:  before,
+		After:   models.SellReturFull{Header: *header, Details: details},
+		Extra:   map[string]interface{}{"id": header.ID, "sell_id": header.SellID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSellRetur, notify.TopicItems, notify.TopicReceivable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertDetails inserts sell_retur_details rows, adds the returned qty back to
+// items.qty and records a positive 'sell_retur' stock mutation for every line.
+func (r *SellReturRepository) insertDetails(tx *sqlx.Tx, header *models.SellReturHeader, details []models.SellReturDetail) error {
+	for i := range details {
+		detail := &details[i]
+		detail.ID = uuid.New()
+		detail.HeaderID = header.ID
+		detail.CreatedAt = time.Now()
+
+		detailQuery := `INSERT INTO sell_retur_details
+			(id, header_id, item_id, qty, price_amount, total_amount, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+		_, err := tx.Exec(detailQuery, detail.ID, detail.HeaderID, detail.ItemID,
+			detail.Qty, detail.PriceAmount, detail.TotalAmount, detail.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan detail: %v", err)
+		}
+
+		// Barang kembali dari customer, sehingga stok gudang bertambah seharga HPP saat dijual
+		unitCost, err := saleUnitCost(tx, header.SellID, detail.ItemID)
+		if err != nil {
+			return err
+		}
+		if err := applyAvgCost(tx, detail.ItemID, detail.Qty, unitCost, header.ReturDate, header.ID, "sell_retur"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty + $1, version = version + 1 WHERE id = $2`, detail.Qty, detail.ItemID)
+		if err != nil {
+			return fmt.Errorf("gagal memperbarui stok barang: %v", err)
+		}
+
+		// Barang retur masuk lagi sebagai layer seharga HPP saat dijual
+		err = r.costing.OpenLayer(tx, detail.ItemID, detail.Qty, unitCost, header.ReturDate, header.ID, "sell_retur")
+		if err != nil {
+			return err
+		}
+
+		mutationQuery := `INSERT INTO stock_mutations
+						  (id, item_id, period, trx_date, qty, model_id, model_type, created_at)
+						  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+		_, err = tx.Exec(mutationQuery, uuid.New(), detail.ItemID, header.ReturDate.Format("2006-01"),
+			header.ReturDate, detail.Qty, header.ID, "sell_retur", time.Now())
+		if err != nil {
+			return fmt.Errorf("gagal mencatat mutasi stok: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetAll retrieves all retur penjualan headers
+func (r *SellReturRepository) GetAll() ([]models.SellReturHeader, error) {
+	var headers []models.SellReturHeader
+	query := sellReturSelect + ` ORDER BY rh.retur_date DESC, rh.created_at DESC`
+
+	err := r.db.Select(&headers, query)
+	return headers, err
+}
+
+// Search retrieves retur penjualan headers by retur number, nota penjualan number or customer
+func (r *SellReturRepository) Search(keyword string) ([]models.SellReturHeader, error) {
+	var headers []models.SellReturHeader
+	query := sellReturSelect + `
+			  WHERE LOWER(rh.retur_invoice_num) LIKE LOWER($1)
+			  OR LOWER(sh.sell_invoice_num) LIKE LOWER($1)
+			  OR LOWER(rh.customer_name) LIKE LOWER($1)
+			  ORDER BY rh.retur_date DESC, rh.created_at DESC`
+
+	err := r.db.Select(&headers, query, "%"+keyword+"%")
+	return headers, err
+}
+
+// GetByDate returns the retur penjualan of one day, for the daily sales report
+func (r *SellReturRepository) GetByDate(date time.Time) ([]models.SellReturHeader, error) {
+	var headers []models.SellReturHeader
+	query := sellReturSelect + `
+			  WHERE rh.retur_date = DATE($1)
+			  ORDER BY rh.created_at DESC`
+
+	err := r.db.Select(&headers, query, date)
+	return headers, err
+}
+
+// GetBySell returns every retur penjualan of a nota penjualan
+func (r *SellReturRepository) GetBySell(sellID uuid.UUID) ([]models.SellReturHeader, error) {
+	var headers []models.SellReturHeader
+	query := sellReturSelect + `
+			  WHERE rh.sell_id = $1
+			  ORDER BY rh.retur_date, rh.created_at`
+
+	err := r.db.Select(&headers, query, sellID)
+	return headers, err
+}
+
+// GetByID retrieves a single retur penjualan including details
+func (r *SellReturRepository) GetByID(id uuid.UUID) (*models.SellReturFull, error) {
+	return r.getByID(r.db, id)
+}
+
+// getByID reads the document through q so it can run inside a transaction
+func (r *SellReturRepository) getByID(q sqlx.Queryer, id uuid.UUID) (*models.SellReturFull, error) {
+	var retur models.SellReturFull
+
+	err := sqlx.Get(q, &retur.Header, sellReturSelect+` WHERE rh.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	detailQuery := `SELECT id, header_id, item_id, qty, price_amount, total_amount,
+					created_at, updated_at
+					FROM sell_retur_details
+					WHERE header_id = $1`
+
+	err = sqlx.Select(q, &retur.Details, detailQuery, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &retur, nil
+}
+
+// Void membatalkan retur penjualan secara soft-delete; barang yang diretur
+// keluar lagi dari stok
+func (r *SellReturRepository) Void(id uuid.UUID, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermVoid); err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM sell_retur_headers WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return err
+	}
+	if status == "VOID" {
+		return errors.New("nota retur penjualan sudah di-void")
+	}
+
+	before, err := r.getByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	// 1. Kurangi lagi stok, HPP dan layer HPP yang sempat dikembalikan customer
+	now := time.Now()
+	for _, d := range before.Details {
+		unitCost, err := saleUnitCost(tx, before.Header.SellID, d.ItemID)
+		if err != nil {
+			return err
+		}
+		if err := applyAvgCost(tx, d.ItemID, -d.Qty, unitCost, now, id, "void_sell_retur"); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET qty = qty - $1, version = version + 1 WHERE id = $2`, d.Qty, d.ItemID)
+		if err != nil {
+			return err
+		}
+	}
+	if err := r.costing.CloseLayers(tx, id, "sell_retur"); err != nil {
+		return err
+	}
+
+	// 2. Catat mutasi pembatalan
+	period := now.Format("2006-01")
+	for _, d := range before.Details {
+		mutationQuery := `INSERT INTO stock_mutations
+						  (id, item_id, period, trx_date, qty, model_id, model_type, created_at)
+						  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+		// qty diset negatif karena membatalkan barang masuk dari customer
+		_, err = tx.Exec(mutationQuery, uuid.New(), d.ItemID, period, now, -d.Qty, id, "void_sell_retur", now)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 3. Update status header menjadi VOID
+	_, err = tx.Exec(`UPDATE sell_retur_headers SET status = 'VOID', version = version + 1, updated_at = $1, updated_by = $2 WHERE id = $3`, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &updatedBy,
+		Channel: audit.ChannelSellRetur,
+		Message: "Void nota retur penjualan " + before.Header.ReturInvoiceNum,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		After:   map[string]string{"status": "VOID"},
+		Extra:   map[string]interface{}{"id": id, "sell_id": before.Header.SellID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSellRetur, notify.TopicItems, notify.TopicReceivable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
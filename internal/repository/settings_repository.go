@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Keys of app_settings
+const (
+	SettingCostingMethod = "costing_method"
+)
+
+// SettingsRepository reads the settings shared by every workstation
+type SettingsRepository struct {
+	db *sqlx.DB
+}
+
+func NewSettingsRepository(db *sqlx.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// GetOrInit returns the stored value of key. A key that is not stored yet is
+// saved with value first, so the first workstation to start decides it and
+// the others follow.
+func (r *SettingsRepository) GetOrInit(key, value string) (string, error) {
+	_, err := r.db.Exec(`INSERT INTO app_settings ("key", value, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT ("key") DO NOTHING`, key, value, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	var stored string
+	err = r.db.Get(&stored, `SELECT value FROM app_settings WHERE "key" = $1`, key)
+	return stored, err
+}
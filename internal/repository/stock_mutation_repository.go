@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"time"
+
+	"fyne-app/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type StockMutationRepository struct {
+	db *sqlx.DB
+}
+
+func NewStockMutationRepository(db *sqlx.DB) *StockMutationRepository {
+	return &StockMutationRepository{db: db}
+}
+
+// GetBalanceBefore returns the sum of all mutations of an item dated before the given date
+func (r *StockMutationRepository) GetBalanceBefore(itemID uuid.UUID, date time.Time) (float64, error) {
+	var balance float64
+	query := `SELECT COALESCE(SUM(qty), 0)
+			  FROM stock_mutations
+			  WHERE item_id = $1 AND trx_date < $2`
+
+	err := r.db.Get(&balance, query, itemID, date)
+	return balance, err
+}
+
+// GetEntries returns the mutations of an item within [startDate, endDate] with the
+// source document number resolved through model_id/model_type
+func (r *StockMutationRepository) GetEntries(itemID uuid.UUID, startDate, endDate time.Time) ([]models.StockCardEntry, error) {
+	var entries []models.StockCardEntry
+	query := `SELECT sm.trx_date, sm.model_id, sm.model_type, sm.qty, sm.created_at,
+			  COALESCE(ph.purchase_invoice_num, sh.sell_invoice_num, rh.retur_invoice_num, srh.retur_invoice_num, oh.opname_num, '-') AS document_num
+			  FROM stock_mutations sm
+			  LEFT JOIN purchase_headers ph ON sm.model_type IN ('purchase', 'void_purchase') AND ph.id = sm.model_id
+			  LEFT JOIN sell_headers sh ON sm.model_type IN ('sell', 'void_sell') AND sh.id = sm.model_id
+			  LEFT JOIN retur_headers rh ON sm.model_type IN ('retur', 'void_retur') AND rh.id = sm.model_id
+			  LEFT JOIN sell_retur_headers srh ON sm.model_type IN ('sell_retur', 'void_sell_retur') AND srh.id = sm.model_id
+			  LEFT JOIN opname_headers oh ON sm.model_type IN ('opname', 'void_opname') AND oh.id = sm.model_id
+			  WHERE sm.item_id = $1 AND sm.trx_date >= $2 AND sm.trx_date <= $3
+			  ORDER BY sm.trx_date, sm.created_at`
+
+	err := r.db.Select(&entries, query, itemID, startDate, endDate)
+	return entries, err
+}
+
+// GetStockCard builds the kartu stok of an item: opening balance before startDate,
+// every mutation in the range and the running balance after each line
+func (r *StockMutationRepository) GetStockCard(item models.Item, startDate, endDate time.Time) (*models.StockCard, error) {
+	opening, err := r.GetBalanceBefore(item.ID, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.GetEntries(item.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := opening
+	for i := range entries {
+		balance += entries[i].Qty
+		entries[i].Balance = balance
+	}
+
+	return &models.StockCard{
+		Item:           item,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		OpeningBalance: opening,
+		Entries:        entries,
+		ClosingBalance: balance,
+	}, nil
+}
+
+// itemCost is a unit cost of an item looked up for the valuation
+type itemCost struct {
+	ItemID   uuid.UUID `db:"item_id"`
+	UnitCost float64   `db:"unit_cost"`
+}
+
+func (r *StockMutationRepository) costMap(query string, args ...interface{}) (map[uuid.UUID]float64, error) {
+	var rows []itemCost
+	if err := r.db.Select(&rows, query, args...); err != nil {
+		return nil, err
+	}
+	costs := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		costs[row.ItemID] = row.UnitCost
+	}
+	return costs, nil
+}
+
+// latestPurchaseCosts returns the last active purchase price of every item up to asOf
+func (r *StockMutationRepository) latestPurchaseCosts(asOf time.Time) (map[uuid.UUID]float64, error) {
+	return r.costMap(`SELECT DISTINCT ON (pd.item_id) pd.item_id, pd.price_amount AS unit_cost
+		FROM purchase_details pd
+		JOIN purchase_headers ph ON ph.id = pd.header_id
+		WHERE ph.status = 'ACTIVE' AND ph.purchase_date <= $1
+		ORDER BY pd.item_id, ph.purchase_date DESC, pd.created_at DESC`, asOf)
+}
+
+// averageCosts returns the moving-average cost of every item as it stood on asOf
+func (r *StockMutationRepository) averageCosts(asOf time.Time) (map[uuid.UUID]float64, error) {
+	return r.costMap(`SELECT DISTINCT ON (item_id) item_id, cost_after AS unit_cost
+		FROM item_cost_history
+		WHERE trx_date <= $1
+		ORDER BY item_id, trx_date DESC, created_at DESC`, asOf)
+}
+
+// fifoValue values qty units with the layers still open on the valuation date,
+// newest first, since under FIFO those are the units still on hand. Units not
+// covered by a layer are valued at fallback.
+func fifoValue(qty float64, layers []fifoLayer, fallback float64) float64 {
+	var value float64
+	left := qty
+	for _, l := range layers {
+		if left <= 0 {
+			break
+		}
+		take := l.Qty
+		if take > left {
+			take = left
+		}
+		value += take * l.UnitCost
+		left -= take
+	}
+	return value + left*fallback
+}
+
+type fifoLayer struct {
+	ItemID   uuid.UUID `db:"item_id"`
+	Qty      float64   `db:"qty"`
+	UnitCost float64   `db:"unit_cost"`
+}
+
+// fifoLayers returns the cost layers of every item that existed on asOf,
+// newest first, with what was left on them that day. A layer closed by a void
+// or edit counts until it was closed; a replacement layer opened by a restore
+// carries the date of the layer it replaces, so it only counts from the day
+// it was created. Units sold or consumed by any other document up to asOf are
+// left out; backlog layers hold no units and are skipped.
+func (r *StockMutationRepository) fifoLayers(asOf time.Time) (map[uuid.UUID][]fifoLayer, error) {
+	var rows []fifoLayer
+	err := r.db.Select(&rows, `SELECT l.item_id, l.qty - COALESCE(s.qty, 0) - COALESCE(c.qty, 0) AS qty, l.unit_cost
+		FROM cost_layers l
+		LEFT JOIN (
+			SELECT sc.layer_id, SUM(sc.qty) AS qty
+			FROM sell_cost_consumptions sc
+			JOIN sell_details sd ON sd.id = sc.sell_detail_id
+			JOIN sell_headers sh ON sh.id = sd.header_id
+			WHERE sh.sell_date <= $1
+			GROUP BY sc.layer_id
+		) s ON s.layer_id = l.id
+		LEFT JOIN (
+			SELECT layer_id, SUM(qty) AS qty
+			FROM cost_consumptions
+			WHERE trx_date <= $1
+			GROUP BY layer_id
+		) c ON c.layer_id = l.id
+		WHERE l.model_type <> 'backlog' AND l.trx_date <= $1
+		AND (l.model_type <> 'restore' OR l.created_at::date <= $1)
+		AND (l.closed_at IS NULL OR l.closed_at::date > $1)
+		AND l.qty - COALESCE(s.qty, 0) - COALESCE(c.qty, 0) > 0
+		ORDER BY l.item_id, l.trx_date DESC, l.created_at DESC`, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := map[uuid.UUID][]fifoLayer{}
+	for _, l := range rows {
+		layers[l.ItemID] = append(layers[l.ItemID], l)
+	}
+	return layers, nil
+}
+
+// GetValuation rebuilds the qty of every item as of asOf from stock_mutations
+// and values it with the given cost basis (models.Valuation*). An item without
+// a cost on that basis falls back to its average, then its latest purchase price.
+// FIFO values the qty with the cost layers on hand at asOf, the same layers the
+// HPP penjualan is taken from.
+func (r *StockMutationRepository) GetValuation(asOf time.Time, method string) (*models.InventoryValuation, error) {
+	var rows []models.ValuationRow
+	err := r.db.Select(&rows, `SELECT i.id AS item_id, i.code, i."name", COALESCE(SUM(sm.qty), 0) AS qty
+		FROM items i
+		LEFT JOIN stock_mutations sm ON sm.item_id = i.id AND sm.trx_date <= $1
+		WHERE i.deleted_at IS NULL
+		GROUP BY i.id, i.code, i."name"
+		HAVING ABS(COALESCE(SUM(sm.qty), 0)) > $2
+		ORDER BY i."name"`, asOf, ledgerTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := r.latestPurchaseCosts(asOf)
+	if err != nil {
+		return nil, err
+	}
+	average, err := r.averageCosts(asOf)
+	if err != nil {
+		return nil, err
+	}
+	fallback := func(id uuid.UUID) float64 {
+		if c, ok := average[id]; ok {
+			return c
+		}
+		return latest[id]
+	}
+
+	var layers map[uuid.UUID][]fifoLayer
+	if method == models.ValuationFIFO {
+		layers, err = r.fifoLayers(asOf)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	valuation := &models.InventoryValuation{AsOf: asOf, Method: method}
+	for i := range rows {
+		row := &rows[i]
+		switch method {
+		case models.ValuationFIFO:
+			if row.Qty > 0 {
+				row.Value = fifoValue(row.Qty, layers[row.ItemID], fallback(row.ItemID))
+				row.UnitCost = row.Value / row.Qty
+			} else {
+				row.UnitCost = fallback(row.ItemID)
+				row.Value = row.Qty * row.UnitCost
+			}
+		case models.ValuationLatestPurchase:
+			cost, ok := latest[row.ItemID]
+			if !ok {
+				cost = average[row.ItemID]
+			}
+			row.UnitCost = cost
+			row.Value = row.Qty * cost
+		default:
+			row.UnitCost = fallback(row.ItemID)
+			row.Value = row.Qty * row.UnitCost
+		}
+		valuation.TotalQty += row.Qty
+		valuation.TotalValue += row.Value
+	}
+	valuation.Rows = rows
+	return valuation, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFifoValue(t *testing.T) {
+	// Newest layer first, as fifoLayers returns them
+	layers := []fifoLayer{
+		{Qty: 5, UnitCost: 12000},
+		{Qty: 10, UnitCost: 10000},
+	}
+
+	tests := []struct {
+		name     string
+		qty      float64
+		layers   []fifoLayer
+		fallback float64
+		want     float64
+	}{
+		{"within newest layer", 3, layers, 9000, 36000},
+		{"spans two layers", 8, layers, 9000, 5*12000 + 3*10000},
+		{"all layers", 15, layers, 9000, 5*12000 + 10*10000},
+		{"beyond layers uses fallback", 18, layers, 9000, 5*12000 + 10*10000 + 3*9000},
+		{"no layers", 4, nil, 9000, 36000},
+		{"zero qty", 0, layers, 9000, 0},
+		{"negative qty", -2, layers, 9000, -18000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fifoValue(tt.qty, tt.layers, tt.fallback)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("fifoValue(%v) = %v, want %v", tt.qty, got, tt.want)
+			}
+		})
+	}
+}
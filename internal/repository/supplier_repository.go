@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"time"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type SupplierRepository struct {
+	db    *sqlx.DB
+	audit *audit.Logger
+}
+
+func NewSupplierRepository(db *sqlx.DB, auditLogger *audit.Logger) *SupplierRepository {
+	return &SupplierRepository{db: db, audit: auditLogger}
+}
+
+// GetAll returns the active suppliers ordered by name
+func (r *SupplierRepository) GetAll() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	query := `SELECT id, code, "name", address, phone, npwp,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM suppliers
+			  WHERE deleted_at IS NULL
+			  ORDER BY "name"`
+
+	err := r.db.Select(&suppliers, query)
+	return suppliers, err
+}
+
+func (r *SupplierRepository) Search(keyword string) ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	query := `SELECT id, code, "name", address, phone, npwp,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM suppliers
+			  WHERE deleted_at IS NULL
+			  AND (code ILIKE $1 OR "name" ILIKE $1 OR phone ILIKE $1)
+			  ORDER BY "name"`
+
+	err := r.db.Select(&suppliers, query, "%"+keyword+"%")
+	return suppliers, err
+}
+
+// GetByID also returns deleted suppliers so old notas can still show theirs
+func (r *SupplierRepository) GetByID(id uuid.UUID) (*models.Supplier, error) {
+	return r.getByID(r.db, id)
+}
+
+func (r *SupplierRepository) getByID(q sqlx.Queryer, id uuid.UUID) (*models.Supplier, error) {
+	var supplier models.Supplier
+	query := `SELECT id, code, "name", address, phone, npwp,
+			  created_at, updated_at, deleted_at, created_by, updated_by, version
+			  FROM suppliers
+			  WHERE id = $1`
+
+	err := sqlx.Get(q, &supplier, query, id)
+	if err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *SupplierRepository) Create(supplier *models.Supplier) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	supplier.ID = uuid.New()
+	supplier.CreatedAt = time.Now()
+	supplier.Version = 1
+
+	query := `INSERT INTO suppliers (id, code, "name", address, phone, npwp, created_at, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = tx.Exec(query, supplier.ID, supplier.Code, supplier.Name, supplier.Address,
+		supplier.Phone, supplier.NPWP, supplier.CreatedAt, supplier.CreatedBy)
+	if err != nil {
+		return mapDBError(err)
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  supplier.CreatedBy,
+		Channel: audit.ChannelSupplier,
+		Message: "Tambah supplier " + supplier.Code,
+		After:   supplier,
+		Extra:   map[string]interface{}{"id": supplier.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSupplier); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SupplierRepository) Update(supplier *models.Supplier) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockVersion(tx, "suppliers", "Supplier", supplier.ID, supplier.Version); err != nil {
+		return err
+	}
+
+	before, err := r.getByID(tx, supplier.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	supplier.UpdatedAt = &now
+
+	query := `UPDATE suppliers
+			  SET code = $1, "name" = $2, address = $3, phone = $4, npwp = $5,
+			      updated_at = $6, updated_by = $7, version = version + 1
+			  WHERE id = $8`
+
+	_, err = tx.Exec(query, supplier.Code, supplier.Name, supplier.Address, supplier.Phone,
+		supplier.NPWP, supplier.UpdatedAt, supplier.UpdatedBy, supplier.ID)
+	if err != nil {
+		return mapDBError(err)
+	}
+	supplier.Version++
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  supplier.UpdatedBy,
+		Channel: audit.ChannelSupplier,
+		Message: "Ubah supplier " + before.Code,
+		Before:  before,
+		After:   supplier,
+		Extra:   map[string]interface{}{"id": supplier.ID},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSupplier); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete hides the supplier from the picker; notas that reference it keep the link
+func (r *SupplierRepository) Delete(id uuid.UUID, deletedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := r.getByID(tx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := `UPDATE suppliers
+			  SET deleted_at = $1, updated_by = $2, updated_at = $3, version = version + 1
+			  WHERE id = $4`
+
+	_, err = tx.Exec(query, now, deletedBy, now, id)
+	if err != nil {
+		return err
+	}
+
+	err = r.audit.Log(tx, audit.Entry{
+		UserID:  &deletedBy,
+		Channel: audit.ChannelSupplier,
+		Message: "Hapus supplier " + before.Code,
+		Level:   audit.LevelNotice,
+		Before:  before,
+		Extra:   map[string]interface{}{"id": id},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := notify.Publish(tx, notify.TopicSupplier); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,49 +1,207 @@
 package repository
 
 import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"sync"
 	"time"
 
+	"fyne-app/internal/audit"
 	"fyne-app/internal/models"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrWrongPassword is returned by ChangePassword when the current password does not match
+var ErrWrongPassword = errors.New("password lama salah")
+
+// ErrUserDisabled is returned by ChangePassword for an account that has been disabled
+var ErrUserDisabled = errors.New("akun sudah dinonaktifkan")
+
+// ErrLastAdmin is returned when a change would leave no active admin account
+var ErrLastAdmin = errors.New("minimal harus ada satu admin yang aktif")
+
 type UserRepository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	audit *audit.Logger
 }
 
-func NewUserRepository(db *sqlx.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *sqlx.DB, auditLogger *audit.Logger) *UserRepository {
+	return &UserRepository{db: db, audit: auditLogger}
 }
 
-func (r *UserRepository) Authenticate(username, password string) (*models.User, error) {
-	var user models.User
-	query := `SELECT id, username, "name", password, created_at, updated_at 
-			  FROM users 
-			  WHERE username = $1 AND password = $2`
+// HashPassword returns the bcrypt hash stored in users.password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// isHashed reports whether a stored password is a bcrypt hash rather than a legacy plaintext value
+func isHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// burnPasswordCheck does the bcrypt work of a real password check, so a login
+// with an unknown username takes as long as one with a wrong password
+func burnPasswordCheck(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// checkPassword compares a password against the stored value, hashed or legacy plaintext
+func checkPassword(stored, password string) bool {
+	if isHashed(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
 
-	err := r.db.Get(&user, query, username, password)
+// Authenticate returns sql.ErrNoRows when the username or password is wrong.
+// Legacy plaintext passwords are replaced with a hash after a successful login.
+func (r *UserRepository) Authenticate(username, password string) (*models.User, error) {
+	user, err := r.GetByUsername(username)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			burnPasswordCheck(password)
+		}
 		return nil, err
 	}
 
-	return &user, nil
+	// User yang dinonaktifkan diperlakukan sama seperti username yang tidak ada;
+	// password tetap dicek supaya waktunya tidak membedakan keduanya
+	if !checkPassword(user.Password, password) || !user.IsActive() {
+		// The caller must see the same ErrNoRows as for a wrong username, so an
+		// audit failure is only logged instead of replacing the login error
+		err = r.audit.Log(nil, audit.Entry{
+			UserID:  &user.ID,
+			Channel: audit.ChannelAuth,
+			Message: "Login gagal: " + user.Username,
+			Level:   audit.LevelWarning,
+		})
+		if err != nil {
+			log.Printf("Gagal mencatat login gagal %s: %v", user.Username, err)
+		}
+		return nil, sql.ErrNoRows
+	}
+
+	if !isHashed(user.Password) {
+		hash, err := HashPassword(password)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		_, err = r.db.Exec(`UPDATE users SET password = $1, updated_at = $2 WHERE id = $3`, hash, now, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hash
+		user.UpdatedAt = &now
+	}
+
+	// The credentials are valid, so a failing audit write does not lock the user out
+	err = r.audit.Log(nil, audit.Entry{
+		UserID:  &user.ID,
+		Channel: audit.ChannelAuth,
+		Message: "Login: " + user.Username,
+	})
+	if err != nil {
+		log.Printf("Gagal mencatat login %s: %v", user.Username, err)
+	}
+
+	return user, nil
+}
+
+// RecordLogout writes the logout entry of the audit trail
+func (r *UserRepository) RecordLogout(user *models.User) error {
+	return r.audit.Log(nil, audit.Entry{
+		UserID:  &user.ID,
+		Channel: audit.ChannelAuth,
+		Message: "Logout: " + user.Username,
+	})
 }
 
-func (r *UserRepository) Create(user *models.User) error {
+// Create adds an account on behalf of createdBy, who needs the manage users permission
+func (r *UserRepository) Create(user *models.User, createdBy uuid.UUID) error {
+	hash, err := HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, createdBy, models.PermManageUsers); err != nil {
+		return err
+	}
+
 	user.ID = uuid.New()
+	user.Password = hash
 	user.CreatedAt = time.Now()
+	user.CreatedBy = &createdBy
+
+	if user.Role == "" {
+		user.Role = models.RoleKasir
+	}
+
+	query := `INSERT INTO users (id, username, "name", password, role, created_at, created_by) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = tx.Exec(query, user.ID, user.Username, user.Name, user.Password, user.Role, user.CreatedAt, user.CreatedBy)
+	if err != nil {
+		return err
+	}
 
-	query := `INSERT INTO users (id, username, "name", password, created_at) 
-			  VALUES ($1, $2, $3, $4, $5)`
+	return tx.Commit()
+}
+
+// ChangePassword verifies the current password before storing the hash of the new one
+func (r *UserRepository) ChangePassword(userID uuid.UUID, oldPassword, newPassword string) error {
+	var stored struct {
+		Password  string     `db:"password"`
+		DeletedAt *time.Time `db:"deleted_at"`
+	}
+	err := r.db.Get(&stored, `SELECT password, deleted_at FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	// A session opened before the account was disabled cannot set a new password
+	if stored.DeletedAt != nil {
+		return ErrUserDisabled
+	}
+	if !checkPassword(stored.Password, oldPassword) {
+		return ErrWrongPassword
+	}
 
-	_, err := r.db.Exec(query, user.ID, user.Username, user.Name, user.Password, user.CreatedAt)
+	hash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`UPDATE users SET password = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`, hash, time.Now(), userID)
 	return err
 }
 
 func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 	var user models.User
-	query := `SELECT id, username, "name", password, created_at, updated_at 
+	query := `SELECT id, username, "name", password, role, created_at, updated_at, deleted_at, updated_by 
 			  FROM users 
 			  WHERE username = $1`
 
@@ -54,3 +212,179 @@ func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
 
 	return &user, nil
 }
+
+// GetPermissions returns the permission codes granted to a role
+func (r *UserRepository) GetPermissions(role string) (map[string]bool, error) {
+	var codes []string
+	err := r.db.Select(&codes, `SELECT permission FROM role_permissions WHERE role = $1`, role)
+	if err != nil {
+		return nil, err
+	}
+
+	perms := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		perms[c] = true
+	}
+	return perms, nil
+}
+
+// GetAll returns every user including disabled ones
+func (r *UserRepository) GetAll() ([]models.User, error) {
+	var users []models.User
+	query := `SELECT id, username, "name", password, role, created_at, updated_at, deleted_at, updated_by 
+			  FROM users 
+			  ORDER BY username`
+
+	err := r.db.Select(&users, query)
+	return users, err
+}
+
+func (r *UserRepository) Search(keyword string) ([]models.User, error) {
+	var users []models.User
+	query := `SELECT id, username, "name", password, role, created_at, updated_at, deleted_at, updated_by 
+			  FROM users 
+			  WHERE username ILIKE $1 OR "name" ILIKE $1 OR role ILIKE $1 
+			  ORDER BY username`
+
+	err := r.db.Select(&users, query, "%"+keyword+"%")
+	return users, err
+}
+
+func (r *UserRepository) GetByID(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	query := `SELECT id, username, "name", password, role, created_at, updated_at, deleted_at, updated_by 
+			  FROM users 
+			  WHERE id = $1`
+
+	err := r.db.Get(&user, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) GetRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Select(&roles, `SELECT code, "name" FROM roles ORDER BY "name"`)
+	return roles, err
+}
+
+// Update changes the name and role of a user
+func (r *UserRepository) Update(user *models.User, updatedBy uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermManageUsers); err != nil {
+		return err
+	}
+
+	if err := lockAdmins(tx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.UpdatedAt = &now
+	user.UpdatedBy = &updatedBy
+
+	_, err = tx.Exec(`UPDATE users SET "name" = $1, role = $2, updated_at = $3, updated_by = $4 WHERE id = $5`,
+		user.Name, user.Role, now, updatedBy, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureActiveAdmin(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetActive disables (soft-delete) or re-enables a user. A user cannot disable themselves.
+func (r *UserRepository) SetActive(id uuid.UUID, active bool, updatedBy uuid.UUID) error {
+	if !active && id == updatedBy {
+		return errors.New("tidak dapat menonaktifkan user yang sedang login")
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermManageUsers); err != nil {
+		return err
+	}
+
+	if err := lockAdmins(tx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deletedAt *time.Time
+	if !active {
+		deletedAt = &now
+	}
+
+	_, err = tx.Exec(`UPDATE users SET deleted_at = $1, updated_at = $2, updated_by = $3 WHERE id = $4`,
+		deletedAt, now, updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureActiveAdmin(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword sets a new password without asking for the old one
+func (r *UserRepository) ResetPassword(id uuid.UUID, newPassword string, updatedBy uuid.UUID) error {
+	hash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := RequirePermission(tx, updatedBy, models.PermManageUsers); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE users SET password = $1, updated_at = $2, updated_by = $3 WHERE id = $4`,
+		hash, time.Now(), updatedBy, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockAdmins locks the active admin rows before a change that may demote or
+// disable one, so two such changes run one after the other and the second one
+// counts the admins the first one left
+func lockAdmins(tx *sqlx.Tx) error {
+	var admins []uuid.UUID
+	return tx.Select(&admins, `SELECT id FROM users WHERE role = $1 AND deleted_at IS NULL ORDER BY id FOR UPDATE`, models.RoleAdmin)
+}
+
+// ensureActiveAdmin fails when no active admin would remain after the change in tx
+func ensureActiveAdmin(tx *sqlx.Tx) error {
+	var count int
+	err := tx.Get(&count, `SELECT COUNT(*) FROM users WHERE role = $1 AND deleted_at IS NULL`, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
@@ -1,30 +1,98 @@
 package state
 
 import (
+	"fmt"
+	"log"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/config"
+	"fyne-app/internal/costing"
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
 	"fyne-app/internal/repository"
 	"github.com/jmoiron/sqlx"
 )
 
 type Session struct {
-	IsLoggedIn   bool
-	Username     string
-	User         *models.User
-	DB           *sqlx.DB
-	UserRepo     *repository.UserRepository
-	ItemRepo     *repository.ItemRepository
-	PurchaseRepo *repository.PurchaseRepository
-	SellRepo     *repository.SellRepository
-	ReturRepo    *repository.ReturRepository
+	IsLoggedIn     bool
+	Username       string
+	User           *models.User
+	Permissions    map[string]bool
+	DB             *sqlx.DB
+	Config         *config.AppConfig
+	Audit          *audit.Logger
+	Numbering      *numbering.Service
+	Costing        *costing.Service
+	Notify         *notify.Hub
+	UserRepo       *repository.UserRepository
+	ItemRepo       *repository.ItemRepository
+	PurchaseRepo   *repository.PurchaseRepository
+	SellRepo       *repository.SellRepository
+	ReturRepo      *repository.ReturRepository
+	SellReturRepo  *repository.SellReturRepository
+	SupplierRepo   *repository.SupplierRepository
+	CustomerRepo   *repository.CustomerRepository
+	PayableRepo    *repository.PayableRepository
+	ReceivableRepo *repository.ReceivableRepository
+	OpnameRepo     *repository.OpnameRepository
+	MutationRepo   *repository.StockMutationRepository
+	ReconRepo      *repository.ReconciliationRepository
+	ProfitRepo     *repository.ProfitRepository
+	PurgeRepo      *repository.PurgeRepository
+	AuditRepo      *repository.AuditLogRepository
 }
 
-func NewSession(db *sqlx.DB) *Session {
-	return &Session{
-		DB:           db,
-		UserRepo:     repository.NewUserRepository(db),
-		ItemRepo:     repository.NewItemRepository(db),
-		PurchaseRepo: repository.NewPurchaseRepository(db),
-		SellRepo:     repository.NewSellRepository(db),
-		ReturRepo:    repository.NewReturRepository(db),
+// NewSession wires the services and repositories. The costing method is read
+// from the database so every workstation values HPP the same way.
+func NewSession(db *sqlx.DB, cfg *config.AppConfig) (*Session, error) {
+	auditLogger := audit.NewLogger(db)
+	numberingService := numbering.NewService(map[string]string{
+		numbering.DocSell:      cfg.Numbering.Sell,
+		numbering.DocRetur:     cfg.Numbering.Retur,
+		numbering.DocSellRetur: cfg.Numbering.SellRetur,
+	})
+	settingsRepo := repository.NewSettingsRepository(db)
+	method, err := settingsRepo.GetOrInit(repository.SettingCostingMethod, costing.NormalizeMethod(cfg.Costing.Method))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca metode HPP: %w", err)
+	}
+	if cfg.Costing.Method != "" && costing.NormalizeMethod(cfg.Costing.Method) != costing.NormalizeMethod(method) {
+		log.Printf("Metode HPP %q dari database dipakai, [costing] method di config.toml diabaikan", method)
+	}
+	costingService := costing.NewService(method)
+	// Live refresh is optional: without a listener pages only reload on their own actions
+	hub, err := notify.NewHub(cfg.Database.ConnectionString())
+	if err != nil {
+		log.Printf("Live refresh tidak aktif: %v", err)
 	}
+	return &Session{
+		DB:             db,
+		Config:         cfg,
+		Audit:          auditLogger,
+		Numbering:      numberingService,
+		Costing:        costingService,
+		Notify:         hub,
+		UserRepo:       repository.NewUserRepository(db, auditLogger),
+		ItemRepo:       repository.NewItemRepository(db, auditLogger, costingService),
+		PurchaseRepo:   repository.NewPurchaseRepository(db, auditLogger, costingService),
+		SellRepo:       repository.NewSellRepository(db, auditLogger, numberingService, costingService),
+		ReturRepo:      repository.NewReturRepository(db, auditLogger, numberingService, costingService),
+		SellReturRepo:  repository.NewSellReturRepository(db, auditLogger, numberingService, costingService),
+		SupplierRepo:   repository.NewSupplierRepository(db, auditLogger),
+		CustomerRepo:   repository.NewCustomerRepository(db, auditLogger),
+		PayableRepo:    repository.NewPayableRepository(db, auditLogger),
+		ReceivableRepo: repository.NewReceivableRepository(db, auditLogger),
+		OpnameRepo:     repository.NewOpnameRepository(db, costingService),
+		MutationRepo:   repository.NewStockMutationRepository(db),
+		ReconRepo:      repository.NewReconciliationRepository(db, costingService),
+		ProfitRepo:     repository.NewProfitRepository(db),
+		PurgeRepo:      repository.NewPurgeRepository(db, auditLogger),
+		AuditRepo:      repository.NewAuditLogRepository(db),
+	}, nil
+}
+
+// Can reports whether the logged-in user's role grants the permission
+func (s *Session) Can(permission string) bool {
+	return s.User != nil && s.Permissions[permission]
 }
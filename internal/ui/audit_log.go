@@ -0,0 +1,352 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/audit"
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// prettyJSON indents a stored JSON column for display, falling back to the raw text
+func prettyJSON(raw string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(raw), "", "  "); err != nil {
+		return raw
+	}
+	return buf.String()
+}
+
+func showAuditLogDetailDialog(w fyne.Window, entry models.AuditLog, onClose func()) {
+	username := "-"
+	if entry.Username != nil {
+		username = *entry.Username
+	}
+
+	info := widget.NewForm(
+		widget.NewFormItem("Waktu", widget.NewLabel(entry.CreatedAt.Format("2006-01-02 15:04:05"))),
+		widget.NewFormItem("User", widget.NewLabel(username)),
+		widget.NewFormItem("Channel", widget.NewLabel(entry.Channel)),
+		widget.NewFormItem("Level", widget.NewLabel(entry.LevelName)),
+		widget.NewFormItem("Pesan", widget.NewLabel(entry.Message)),
+	)
+
+	context := widget.NewLabel(prettyJSON(entry.Context) + "\n\n" + prettyJSON(entry.Extra))
+	context.TextStyle = fyne.TextStyle{Monospace: true}
+	context.Selectable = true
+
+	var d dialog.Dialog
+
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+
+	content := container.NewBorder(info, closeBtn, nil, nil, container.NewScroll(context))
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom("Detail Audit", "", dialogContent, w)
+	d.Resize(fyne.NewSize(750, 600))
+	d.Show()
+}
+
+// AuditLogPage is the read-only viewer of the user_logs audit trail
+func AuditLogPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("AUDIT TRAIL", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), widget.NewLabel(""))
+
+	// ===== FILTER =====
+	const allUsers = "Semua User"
+	const allChannels = "Semua Channel"
+
+	userOptions := []string{allUsers}
+	userIDs := map[string]uuid.UUID{}
+	users, _ := s.UserRepo.GetAll()
+	for _, u := range users {
+		userOptions = append(userOptions, u.Username)
+		userIDs[u.Username] = u.ID
+	}
+	userSelect := widget.NewSelect(userOptions, nil)
+	userSelect.SetSelected(allUsers)
+
+	channelSelect := widget.NewSelect(append([]string{allChannels}, audit.Channels...), nil)
+	channelSelect.SetSelected(allChannels)
+
+	now := time.Now()
+	startLabel := widget.NewLabel(now.AddDate(0, 0, -7).Format("2006-01-02"))
+	endLabel := widget.NewLabel(now.Format("2006-01-02"))
+
+	startBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, startLabel.Text, func(selectedDate string) {
+			startLabel.SetText(selectedDate)
+		})
+	})
+	endBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, endLabel.Text, func(selectedDate string) {
+			endLabel.SetText(selectedDate)
+		})
+	})
+
+	// ===== TABLE =====
+	headers := []string{"Waktu", "User", "Channel", "Level", "Pesan"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []models.AuditLog
+	var selectedRow int = -1
+
+	loadData := func() {
+		selectedRow = -1
+
+		startDate, err1 := time.Parse("2006-01-02", startLabel.Text)
+		endDate, err2 := time.Parse("2006-01-02", endLabel.Text)
+		if err1 != nil || err2 != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+
+		filter := models.AuditLogFilter{StartDate: startDate, EndDate: endDate}
+		if id, ok := userIDs[userSelect.Selected]; ok {
+			filter.UserID = &id
+		}
+		if channelSelect.Selected != allChannels {
+			filter.Channel = channelSelect.Selected
+		}
+
+		logs, err := s.AuditRepo.Search(filter)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat audit trail: %v", err), w)
+			return
+		}
+		data = logs
+	}
+
+	loadData()
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				entry := data[id.Row-1]
+
+				if entry.Level >= int16(audit.LevelWarning) && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = entry.CreatedAt.Format("2006-01-02 15:04:05")
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = "-"
+					if entry.Username != nil {
+						text.Text = *entry.Username
+					}
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = entry.Channel
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = entry.LevelName
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = entry.Message
+					text.Alignment = fyne.TextAlignLeading
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 170)
+	table.SetColumnWidth(1, 130)
+	table.SetColumnWidth(2, 100)
+	table.SetColumnWidth(3, 100)
+	table.SetColumnWidth(4, 430)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable := func() {
+		loadData()
+		table.Refresh()
+		table.ScrollToTop()
+		safeFocus()
+	}
+
+	showBtn := widget.NewButtonWithIcon("Tampilkan", theme.SearchIcon(), refreshTable)
+	showBtn.Importance = widget.HighImportance
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+		case fyne.KeyV, fyne.KeyReturn:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showAuditLogDetailDialog(w, data[selectedRow], func() { isDialogOpen = false; safeFocus() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih baris terlebih dahulu!", w)
+			}
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	filterForm := container.NewVBox(
+		container.NewHBox(
+			widget.NewLabel("User"), userSelect,
+			widget.NewLabel("Channel"), channelSelect,
+		),
+		container.NewHBox(
+			widget.NewLabel("Dari"), startLabel, startBtn,
+			widget.NewLabel("s/d"), endLabel, endBtn,
+			showBtn,
+		),
+	)
+
+	filterBg := canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	filterBg.CornerRadius = 6
+	filterPanel := container.NewMax(filterBg, container.NewPadded(filterForm))
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 420), focusWrapper))
+
+	footer := canvas.NewText("[V] Detail perubahan (before/after)  |  ↑↓ = Navigate", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(
+		container.NewVBox(header, filterPanel),
+		footer,
+		nil,
+		nil,
+		tableWrapper,
+	)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	return container.NewMax(bg, centeredPanel)
+}
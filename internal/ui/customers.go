@@ -0,0 +1,578 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/repository"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// customerPicker is the searchable customer field of the penjualan dialog,
+// working like supplierPicker
+type customerPicker struct {
+	Entry     *widget.SelectEntry
+	customers []models.Customer
+	options   []string
+	syncing   bool
+
+	// OnSelected is called when typing changes which customer is selected,
+	// with nil when the text no longer matches one
+	OnSelected func(*models.Customer)
+	lastID     uuid.UUID
+}
+
+func newCustomerPicker(s *state.Session) *customerPicker {
+	p := &customerPicker{}
+	p.customers, _ = s.CustomerRepo.GetAll()
+	for _, c := range p.customers {
+		p.options = append(p.options, customerOption(c))
+	}
+
+	p.Entry = widget.NewSelectEntry(p.options)
+	p.Entry.PlaceHolder = "Cari Customer..."
+	p.Entry.OnChanged = func(text string) {
+		if p.syncing {
+			return
+		}
+		selected := p.Selected()
+		if p.OnSelected != nil {
+			var id uuid.UUID
+			if selected != nil {
+				id = selected.ID
+			}
+			if id != p.lastID {
+				p.lastID = id
+				p.OnSelected(selected)
+			}
+		}
+		if text == "" || selected != nil {
+			p.Entry.SetOptions(p.options)
+			return
+		}
+		var filtered []string
+		for _, opt := range p.options {
+			if containsCI(opt, text) {
+				filtered = append(filtered, opt)
+			}
+		}
+		p.Entry.SetOptions(filtered)
+	}
+	return p
+}
+
+func customerOption(c models.Customer) string {
+	return c.Code + " - " + c.Name
+}
+
+// Selected resolves the typed or chosen text to a customer, or nil
+func (p *customerPicker) Selected() *models.Customer {
+	text := strings.TrimSpace(p.Entry.Text)
+	for i, c := range p.customers {
+		if text == customerOption(c) || strings.EqualFold(text, c.Code) || strings.EqualFold(text, c.Name) {
+			return &p.customers[i]
+		}
+	}
+	return nil
+}
+
+// SelectDefault preselects the walk-in customer for a new nota
+func (p *customerPicker) SelectDefault() {
+	for _, c := range p.customers {
+		if c.IsDefault() {
+			p.syncing = true
+			p.Entry.SetText(customerOption(c))
+			p.syncing = false
+			p.lastID = c.ID
+			return
+		}
+	}
+}
+
+// Select shows the customer of an existing nota, including a deleted one
+func (p *customerPicker) Select(s *state.Session, id *uuid.UUID, name string) {
+	p.syncing = true
+	defer func() { p.syncing = false }()
+
+	if id == nil {
+		p.Entry.SetText(name)
+		return
+	}
+	p.lastID = *id
+	for _, c := range p.customers {
+		if c.ID == *id {
+			p.Entry.SetText(customerOption(c))
+			return
+		}
+	}
+	if c, err := s.CustomerRepo.GetByID(*id); err == nil {
+		p.customers = append(p.customers, *c)
+		p.Entry.SetText(customerOption(*c))
+		return
+	}
+	p.Entry.SetText(name)
+}
+
+// priceTierLabel is the display name of a models.PriceTier* value
+func priceTierLabel(tier string) string {
+	switch tier {
+	case models.PriceTierMember:
+		return "Member"
+	case models.PriceTierGrosir:
+		return "Grosir"
+	}
+	return "Umum"
+}
+
+// showCustomerDialog creates a new customer when customer is nil, otherwise edits it
+func showCustomerDialog(w fyne.Window, s *state.Session, customer *models.Customer, onClose func()) {
+	isNew := customer == nil
+
+	tierLabels := make([]string, len(models.PriceTiers))
+	tierCodes := make(map[string]string, len(models.PriceTiers))
+	for i, t := range models.PriceTiers {
+		tierLabels[i] = priceTierLabel(t)
+		tierCodes[tierLabels[i]] = t
+	}
+
+	kode := widget.NewEntry()
+	nama := widget.NewEntry()
+	alamat := widget.NewMultiLineEntry()
+	alamat.SetMinRowsVisible(3)
+	telepon := widget.NewEntry()
+	email := widget.NewEntry()
+	tier := widget.NewSelect(tierLabels, nil)
+	limit := widget.NewEntry()
+	limit.SetPlaceHolder("0 = tanpa kredit")
+
+	if isNew {
+		tier.SetSelected(priceTierLabel(models.PriceTierUmum))
+	} else {
+		kode.SetText(customer.Code)
+		if customer.IsDefault() {
+			kode.Disable()
+		}
+		nama.SetText(customer.Name)
+		alamat.SetText(customer.Address)
+		telepon.SetText(customer.Phone)
+		email.SetText(customer.Email)
+		tier.SetSelected(priceTierLabel(customer.PriceTier))
+		limit.SetText(FormatCurrency(customer.CreditLimit))
+	}
+
+	kode.OnSubmitted = func(string) { w.Canvas().Focus(nama) }
+	nama.OnSubmitted = func(string) { w.Canvas().Focus(alamat) }
+	telepon.OnSubmitted = func(string) { w.Canvas().Focus(email) }
+	email.OnSubmitted = func(string) { w.Canvas().Focus(limit) }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Kode", kode),
+		widget.NewFormItem("Nama", nama),
+		widget.NewFormItem("Alamat", alamat),
+		widget.NewFormItem("Telepon", telepon),
+		widget.NewFormItem("Email", email),
+		widget.NewFormItem("Tier Harga", tier),
+		widget.NewFormItem("Limit Kredit", limit),
+	)
+
+	var d dialog.Dialog
+
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Simpan", func() {
+		if strings.TrimSpace(kode.Text) == "" || strings.TrimSpace(nama.Text) == "" {
+			dialog.ShowInformation("Error", "Kode dan nama customer harus diisi!", w)
+			return
+		}
+
+		creditLimit := 0.0
+		if strings.TrimSpace(limit.Text) != "" {
+			v, err := ParseCurrencyString(limit.Text)
+			if err != nil || v < 0 {
+				dialog.ShowInformation("Error", "Limit kredit harus berupa angka!", w)
+				return
+			}
+			creditLimit = v
+		}
+
+		var err error
+		if isNew {
+			err = s.CustomerRepo.Create(&models.Customer{
+				Code:        strings.TrimSpace(kode.Text),
+				Name:        strings.TrimSpace(nama.Text),
+				Address:     alamat.Text,
+				Phone:       telepon.Text,
+				Email:       strings.TrimSpace(email.Text),
+				PriceTier:   tierCodes[tier.Selected],
+				CreditLimit: creditLimit,
+				CreatedBy:   &s.User.ID,
+			})
+		} else {
+			updated := *customer
+			updated.Code = strings.TrimSpace(kode.Text)
+			updated.Name = strings.TrimSpace(nama.Text)
+			updated.Address = alamat.Text
+			updated.Phone = telepon.Text
+			updated.Email = strings.TrimSpace(email.Text)
+			updated.PriceTier = tierCodes[tier.Selected]
+			updated.CreditLimit = creditLimit
+			updated.UpdatedBy = &s.User.ID
+			err = s.CustomerRepo.Update(&updated)
+		}
+
+		switch {
+		case errors.Is(err, repository.ErrDuplicateCode):
+			dialog.ShowInformation("Error", "Kode customer sudah terdaftar, silakan gunakan kode lain!", w)
+			return
+		case errors.Is(err, repository.ErrVersionConflict):
+			showConflictDialog(w, func() {
+				latest, err := s.CustomerRepo.GetByID(customer.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				d.Hide()
+				showCustomerDialog(w, s, latest, onClose)
+			}, func() {
+				latest, err := s.CustomerRepo.GetByID(customer.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				customer.Version = latest.Version
+				submitBtn.OnTapped()
+			})
+			return
+		case err != nil:
+			dialog.ShowError(fmt.Errorf("Gagal menyimpan customer: %v", err), w)
+			return
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", "Data customer berhasil disimpan!", w)
+		if onClose != nil {
+			onClose()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	limit.OnSubmitted = func(string) { submitBtn.OnTapped() }
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	dialogTitle := "Customer Baru"
+	if !isNew {
+		dialogTitle = "Edit Customer"
+	}
+	d = dialog.NewCustom(dialogTitle, "", dialogContent, w)
+	d.Resize(fyne.NewSize(480, 480))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			if kode.Disabled() {
+				w.Canvas().Focus(nama)
+			} else {
+				w.Canvas().Focus(kode)
+			}
+		})
+	})
+}
+
+// CustomerPage maintains the customer master data used by penjualan
+func CustomerPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("MASTER CUSTOMER", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search kode, nama atau telepon...")
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), container.NewMax(search))
+
+	headers := []string{"Kode", "Nama", "Telepon", "Email", "Tier", "Limit Kredit"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []models.Customer
+	var selectedRow int = -1
+
+	loadData := func(keyword string) {
+		var customers []models.Customer
+		var err error
+
+		if keyword == "" {
+			customers, err = s.CustomerRepo.GetAll()
+		} else {
+			customers, err = s.CustomerRepo.Search(keyword)
+		}
+
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+		data = customers
+	}
+
+	loadData("")
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				c := data[id.Row-1]
+
+				switch id.Col {
+				case 0:
+					text.Text = c.Code
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = c.Name
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = c.Phone
+					text.Alignment = fyne.TextAlignLeading
+				case 3:
+					text.Text = c.Email
+					text.Alignment = fyne.TextAlignLeading
+				case 4:
+					text.Text = priceTierLabel(c.PriceTier)
+					text.Alignment = fyne.TextAlignCenter
+				case 5:
+					text.Text = FormatCurrency(c.CreditLimit)
+					text.Alignment = fyne.TextAlignTrailing
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 110)
+	table.SetColumnWidth(1, 250)
+	table.SetColumnWidth(2, 140)
+	table.SetColumnWidth(3, 200)
+	table.SetColumnWidth(4, 90)
+	table.SetColumnWidth(5, 150)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable := func() {
+		loadData(search.Text)
+		table.Refresh()
+		safeFocus()
+	}
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		// Customer baru
+		case fyne.KeyInsert:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showCustomerDialog(w, s, nil, func() { isDialogOpen = false; refreshTable() })
+
+		// Edit customer
+		case fyne.KeyE, fyne.KeyReturn:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				selected := data[selectedRow]
+				showCustomerDialog(w, s, &selected, func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih customer terlebih dahulu!", w)
+			}
+
+		// Hapus customer
+		case fyne.KeyDelete:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				selected := data[selectedRow]
+				if selected.IsDefault() {
+					dialog.ShowInformation("Info", "Customer Umum tidak dapat dihapus!", w)
+					return
+				}
+				dialog.ShowConfirm("Hapus Customer",
+					fmt.Sprintf("Hapus customer '%s'? Nota lama tetap menyimpan customer ini.", selected.Name),
+					func(b bool) {
+						if !b {
+							return
+						}
+						if err := s.CustomerRepo.Delete(selected.ID, s.User.ID); err != nil {
+							dialog.ShowError(err, w)
+							return
+						}
+						selectedRow = -1
+						ShowSuccessToast("Success", "Customer berhasil dihapus!", w)
+						refreshTable()
+					}, w)
+			} else {
+				dialog.ShowInformation("Info", "Pilih customer terlebih dahulu!", w)
+			}
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 480), focusWrapper))
+
+	footer := canvas.NewText("[Insert] Customer Baru  [E] Edit  [Del] Hapus  |  ↑↓ = Navigate", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, footer, nil, nil, tableWrapper)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicCustomer)
+
+	return page
+}
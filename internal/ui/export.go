@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fyne-app/internal/models"
+)
+
+// writeCSV saves the records as a CSV file in the temp folder and opens it
+func writeCSV(name string, records [][]string) error {
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return fmt.Errorf("gagal membuat folder temp: %v", err)
+	}
+
+	fileName := filepath.Join(tempDir, name)
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(f)
+	if err := cw.WriteAll(records); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return openFile(fileName)
+}
+
+// ExportInventoryValuationCSV writes the nilai persediaan as CSV with plain
+// numbers so it can be imported into a spreadsheet or the accounting system
+func ExportInventoryValuationCSV(v *models.InventoryValuation) error {
+	records := [][]string{
+		{"Per Tanggal", v.AsOf.Format("2006-01-02")},
+		{"Dasar Harga", valuationMethodLabel(v.Method)},
+		{},
+		{"Kode", "Nama Barang", "Qty", "Harga Pokok", "Nilai"},
+	}
+	for _, row := range v.Rows {
+		records = append(records, []string{
+			row.Code,
+			row.Name,
+			fmt.Sprintf("%.2f", row.Qty),
+			fmt.Sprintf("%.2f", row.UnitCost),
+			fmt.Sprintf("%.2f", row.Value),
+		})
+	}
+	records = append(records, []string{"", "TOTAL", fmt.Sprintf("%.2f", v.TotalQty), "", fmt.Sprintf("%.2f", v.TotalValue)})
+
+	return writeCSV(fmt.Sprintf("Nilai_Persediaan_%s_%s.csv", v.AsOf.Format("20060102"), v.Method), records)
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/state"
+)
+
+// costTypeLabel names the document that moved the HPP of an item
+func costTypeLabel(modelType string) string {
+	if modelType == "opening_cost" {
+		return "HPP Awal"
+	}
+	return MutationTypeLabel(modelType)
+}
+
+// showItemCostHistoryDialog lists every change of the moving-average cost (HPP) of an item
+func showItemCostHistoryDialog(w fyne.Window, s *state.Session, item InventoryItem, onClose func()) {
+	history, err := s.ItemRepo.GetCostHistory(item.ID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat riwayat HPP: %v", err), w)
+		if onClose != nil {
+			onClose()
+		}
+		return
+	}
+
+	colHeaders := []string{"Tanggal", "Transaksi", "Qty", "Harga", "Stok Sebelum", "HPP Sebelum", "HPP Sesudah"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	table := widget.NewTable(
+		func() (int, int) { return len(history) + 1, len(colHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			bg.FillColor = rowBg
+			text.Color = color.Black
+			text.TextStyle = fyne.TextStyle{}
+
+			if id.Row-1 < len(history) {
+				h := history[id.Row-1]
+				switch id.Col {
+				case 0:
+					text.Text = h.TrxDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = costTypeLabel(h.ModelType)
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = fmt.Sprintf("%.0f", h.Qty)
+					text.Alignment = fyne.TextAlignTrailing
+				case 3:
+					text.Text = FormatCurrency(h.UnitCost)
+					text.Alignment = fyne.TextAlignTrailing
+				case 4:
+					text.Text = fmt.Sprintf("%.0f", h.QtyBefore)
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = FormatCurrency(h.CostBefore)
+					text.Alignment = fyne.TextAlignTrailing
+				case 6:
+					text.Text = FormatCurrency(h.CostAfter)
+					text.Alignment = fyne.TextAlignTrailing
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 100)
+	table.SetColumnWidth(1, 170)
+	table.SetColumnWidth(2, 70)
+	table.SetColumnWidth(3, 120)
+	table.SetColumnWidth(4, 100)
+	table.SetColumnWidth(5, 120)
+	table.SetColumnWidth(6, 120)
+
+	var d dialog.Dialog
+
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	closeBtn.Importance = widget.HighImportance
+
+	summary := fmt.Sprintf("%s - %s\nHPP saat ini %s, stok %s", item.Code, item.Name, item.HargaModal, item.Qty)
+
+	content := container.NewBorder(
+		container.NewVBox(widget.NewLabel(summary), widget.NewSeparator()),
+		container.NewHBox(layout.NewSpacer(), closeBtn),
+		nil, nil,
+		table,
+	)
+
+	d = dialog.NewCustom("Riwayat HPP", "", container.NewPadded(content), w)
+	d.Resize(fyne.NewSize(900, 450))
+	d.Show()
+}
@@ -1,16 +1,22 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"image/color"
 	"strconv"
 	"strings"
 	"time"
 
 	"fyne-app/internal/models"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -146,6 +152,206 @@ func LoadReturDisplayItems(s *state.Session, details []models.ReturDetail) []Dis
 	return items
 }
 
+// LoadSellReturDisplayItems loads retur penjualan items with full item information
+func LoadSellReturDisplayItems(s *state.Session, details []models.SellReturDetail) []DisplayItem {
+	items := make([]DisplayItem, len(details))
+
+	for i, detail := range details {
+		item, err := s.ItemRepo.GetByID(detail.ItemID)
+
+		if err != nil {
+			// Fallback if item not found
+			items[i] = DisplayItem{
+				Code:  "N/A",
+				Name:  "Item tidak ditemukan",
+				Qty:   fmt.Sprintf("%.0f", detail.Qty),
+				Price: FormatCurrency(detail.PriceAmount),
+				Total: FormatCurrency(detail.TotalAmount),
+			}
+		} else {
+			items[i] = DisplayItem{
+				Code:  item.Code,
+				Name:  item.Name,
+				Qty:   fmt.Sprintf("%.0f", detail.Qty),
+				Price: FormatCurrency(detail.PriceAmount),
+				Total: FormatCurrency(detail.TotalAmount),
+			}
+		}
+	}
+
+	return items
+}
+
+// MutationTypeLabel returns the display name of a stock_mutations.model_type
+func MutationTypeLabel(modelType string) string {
+	switch modelType {
+	case "purchase":
+		return "Pembelian"
+	case "void_purchase":
+		return "Void Pembelian"
+	case "sell":
+		return "Penjualan"
+	case "void_sell":
+		return "Void Penjualan"
+	case "retur":
+		return "Retur Pembelian"
+	case "void_retur":
+		return "Void Retur"
+	case "sell_retur":
+		return "Retur Penjualan"
+	case "void_sell_retur":
+		return "Void Retur Penjualan"
+	case "opname":
+		return "Stock Opname"
+	case "void_opname":
+		return "Void Opname"
+	case "adjustment":
+		return "Penyesuaian"
+	case "opening":
+		return "Saldo Awal (Purge)"
+	}
+	return modelType
+}
+
+// previewInvoiceNum shows the number a new nota will most likely get; the real
+// number is allocated when the nota is saved
+func previewInvoiceNum(s *state.Session, docType, dateText string) string {
+	date, err := time.Parse("2006-01-02", dateText)
+	if err != nil {
+		return "(otomatis)"
+	}
+	num, err := s.Numbering.Preview(s.DB, docType, date)
+	if err != nil {
+		return "(otomatis)"
+	}
+	return num + " (otomatis)"
+}
+
+// showAccessDenied tells the user their role does not allow the action
+func showAccessDenied(w fyne.Window) {
+	dialog.ShowInformation("Akses Ditolak", "Anda tidak memiliki hak akses untuk aksi ini.", w)
+}
+
+// showSaveError reports a failed save, translating the typed repository errors
+// into a message the user can act on instead of the raw driver text
+func showSaveError(w fyne.Window, err error) {
+	var stockErr *repository.InsufficientStockError
+	var creditErr *repository.CreditLimitError
+	var returErr *repository.ReturExceedsSoldError
+
+	switch {
+	case errors.As(err, &stockErr):
+		lines := make([]string, len(stockErr.Items))
+		for i, it := range stockErr.Items {
+			lines[i] = fmt.Sprintf("- %s %s: stok %.0f, diminta %.0f", it.Code, it.Name, it.Available, it.Requested)
+		}
+		dialog.ShowInformation("Stok Tidak Mencukupi",
+			"Stok barang berikut tidak mencukupi:\n"+strings.Join(lines, "\n"), w)
+	case errors.As(err, &returErr):
+		lines := make([]string, len(returErr.Items))
+		for i, it := range returErr.Items {
+			lines[i] = fmt.Sprintf("- %s %s: sisa %.0f, diretur %.0f", it.Code, it.Name, it.Available, it.Requested)
+		}
+		dialog.ShowInformation("Qty Retur Berlebih",
+			"Qty retur melebihi qty yang dijual di nota (dikurangi retur sebelumnya):\n"+strings.Join(lines, "\n"), w)
+	case errors.Is(err, repository.ErrHasReturns):
+		dialog.ShowInformation("Nota Sudah Diretur", "Nota ini sudah memiliki retur penjualan. Void returnya terlebih dahulu di menu Retur Penjualan.", w)
+	case errors.As(err, &creditErr):
+		msg := fmt.Sprintf("Penjualan kredit ke %s melebihi limit kredit.\n\nLimit: %s\nPiutang berjalan: %s\nNota ini: %s",
+			creditErr.Customer, FormatCurrency(creditErr.Limit), FormatCurrency(creditErr.Outstanding), FormatCurrency(creditErr.Requested))
+		if creditErr.Limit <= 0 {
+			msg = fmt.Sprintf("Customer %s tidak memiliki limit kredit. Gunakan pembayaran tunai atau transfer.", creditErr.Customer)
+		}
+		dialog.ShowInformation("Limit Kredit Terlampaui", msg, w)
+	case errors.Is(err, repository.ErrVersionConflict):
+		dialog.ShowInformation("Data Sudah Berubah", "Data ini sudah diubah oleh user lain. Silakan buka ulang data tersebut.", w)
+	case errors.Is(err, repository.ErrDuplicateInvoiceNum):
+		dialog.ShowInformation("No. Nota Sudah Ada", "No. Nota tersebut sudah terdaftar. Silakan gunakan nomor nota lain.", w)
+	case errors.Is(err, repository.ErrTotalBelowPaid):
+		dialog.ShowInformation("Total Tidak Valid", "Total nota lebih kecil dari jumlah yang sudah dibayar dan diretur. Void pembayarannya terlebih dahulu.", w)
+	case errors.Is(err, repository.ErrReturExceedsPayable):
+		dialog.ShowInformation("Retur Melebihi Hutang",
+			"Total retur melebihi sisa hutang nota pembelian yang dipilih (setelah pembayaran dan retur lain).\n"+
+				"Pilih nota lain atau \""+noPurchaseLink+"\".", w)
+	case errors.Is(err, repository.ErrOverpayment):
+		dialog.ShowInformation("Pembayaran Berlebih", "Jumlah pembayaran melebihi sisa tagihan nota.", w)
+	case errors.Is(err, repository.ErrForbidden):
+		showAccessDenied(w)
+	default:
+		dialog.ShowError(fmt.Errorf("Gagal menyimpan data: %v", err), w)
+	}
+}
+
+// showConflictDialog is shown when a save fails because another user changed the
+// same data first. Reload discards the local edit and shows the latest data,
+// overwrite saves the local edit on top of the other user's change. A nil
+// onOverwrite is for a nota voided in the meantime, which can only be reloaded.
+func showConflictDialog(w fyne.Window, onReload, onOverwrite func()) {
+	if onOverwrite == nil {
+		showReloadOnlyDialog(w, "Nota ini sudah di-void oleh user lain sejak Anda membukanya.\n"+
+			"Perubahan Anda tidak dapat disimpan, muat ulang untuk melihat data terbaru.", onReload)
+		return
+	}
+
+	showChangedDialog(w, "Data ini sudah diubah oleh user lain sejak Anda membukanya.\n"+
+		"Muat ulang untuk melihat data terbaru, atau timpa dengan perubahan Anda.", onReload, onOverwrite)
+}
+
+// showReloadOnlyDialog is the conflict dialog for changes that must not be
+// overwritten: the message explains why and the user can only reload
+func showReloadOnlyDialog(w fyne.Window, message string, onReload func()) {
+	showChangedDialog(w, message, onReload, nil)
+}
+
+func showChangedDialog(w fyne.Window, message string, onReload, onOverwrite func()) {
+	msg := widget.NewLabel(message)
+	msg.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+
+	cancelBtn := widget.NewButton("Batal", func() { d.Hide() })
+	cancelBtn.Importance = widget.DangerImportance
+
+	reloadBtn := widget.NewButton("Muat Ulang", func() {
+		d.Hide()
+		onReload()
+	})
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, reloadBtn)
+	if onOverwrite != nil {
+		overwriteBtn := widget.NewButton("Timpa", func() {
+			d.Hide()
+			onOverwrite()
+		})
+		overwriteBtn.Importance = widget.HighImportance
+		buttons = container.NewGridWithColumns(3, cancelBtn, reloadBtn, overwriteBtn)
+	}
+	content := container.NewBorder(nil, buttons, nil, nil, msg)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom("Data Sudah Berubah", "", dialogContent, w)
+	d.Resize(fyne.NewSize(460, 200))
+	d.Show()
+}
+
+// subscribeChanges reloads an open page whenever a workstation commits a change
+// to one of the topics. The page calls the returned func when navigating away;
+// notifications that still arrive for a page no longer shown are ignored.
+func subscribeChanges(w fyne.Window, s *state.Session, page fyne.CanvasObject, reload func(), topics ...string) (unsubscribe func()) {
+	return s.Notify.Subscribe(func() {
+		fyne.Do(func() {
+			if w.Content() != page {
+				return
+			}
+			reload()
+		})
+	}, topics...)
+}
+
 // containsCI performs a case-insensitive substring search
 func containsCI(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
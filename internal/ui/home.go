@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
+	"log"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -11,6 +13,8 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"fyne-app/internal/models"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 )
 
@@ -23,7 +27,7 @@ func showDeleteDataDialog(w fyne.Window, s *state.Session) {
 	warning.Wrapping = fyne.TextWrapWord
 	warning.TextStyle = fyne.TextStyle{Bold: true}
 
-	details := widget.NewLabel("Data yang akan dihapus:\n• Stock Mutations (> 3 tahun)\n• Purchase Headers & Details (> 3 tahun)\n• Sell Headers & Details (> 3 tahun)")
+	details := widget.NewLabel("Data yang akan dihapus:\n• Stock Mutations (> 3 tahun, saldo dipindahkan ke saldo awal)\n• Purchase Headers & Details (> 3 tahun)\n• Sell Headers & Details (> 3 tahun)")
 	details.Wrapping = fyne.TextWrapWord
 
 	content := container.NewVBox(
@@ -63,72 +67,13 @@ func showDeleteDataDialog(w fyne.Window, s *state.Session) {
 			var deleteErr error
 			var successMsg string
 
-			// Start transaction
-			tx, err := s.DB.Beginx()
-			if err != nil {
-				deleteErr = fmt.Errorf("Gagal memulai transaksi: %v", err)
+			if kept, err := s.PurgeRepo.Purge(threeYearsAgo, s.User.ID); err != nil {
+				deleteErr = fmt.Errorf("Gagal menghapus data transaksi: %v", err)
 			} else {
-				defer tx.Rollback()
-
-				// Delete stock mutations older than 3 years
-				if deleteErr == nil {
-					_, err = tx.Exec(`DELETE FROM stock_mutations WHERE trx_date < $1`, threeYearsAgo)
-					if err != nil {
-						deleteErr = fmt.Errorf("Gagal menghapus stock mutations: %v", err)
-					}
-				}
-
-				// Delete purchase details for old purchases
-				if deleteErr == nil {
-					_, err = tx.Exec(`
-						DELETE FROM purchase_details 
-						WHERE header_id IN (
-							SELECT id FROM purchase_headers WHERE purchase_date < $1
-						)
-					`, threeYearsAgo)
-					if err != nil {
-						deleteErr = fmt.Errorf("Gagal menghapus purchase details: %v", err)
-					}
-				}
-
-				// Delete purchase headers older than 3 years
-				if deleteErr == nil {
-					_, err = tx.Exec(`DELETE FROM purchase_headers WHERE purchase_date < $1`, threeYearsAgo)
-					if err != nil {
-						deleteErr = fmt.Errorf("Gagal menghapus purchase headers: %v", err)
-					}
-				}
-
-				// Delete sell details for old sells
-				if deleteErr == nil {
-					_, err = tx.Exec(`
-						DELETE FROM sell_details 
-						WHERE header_id IN (
-							SELECT id FROM sell_headers WHERE sell_date < $1
-						)
-					`, threeYearsAgo)
-					if err != nil {
-						deleteErr = fmt.Errorf("Gagal menghapus sell details: %v", err)
-					}
-				}
-
-				// Delete sell headers older than 3 years
-				if deleteErr == nil {
-					_, err = tx.Exec(`DELETE FROM sell_headers WHERE sell_date < $1`, threeYearsAgo)
-					if err != nil {
-						deleteErr = fmt.Errorf("Gagal menghapus sell headers: %v", err)
-					}
-				}
-
-				// Commit transaction
-				if deleteErr == nil {
-					err = tx.Commit()
-					if err != nil {
-						deleteErr = fmt.Errorf("Gagal menyimpan perubahan: %v", err)
-					} else {
-						successMsg = fmt.Sprintf("Data transaksi sebelum tanggal %s berhasil dihapus!",
-							threeYearsAgo.Format("2006-01-02"))
-					}
+				successMsg = fmt.Sprintf("Data transaksi sebelum tanggal %s berhasil dihapus!",
+					threeYearsAgo.Format("2006-01-02"))
+				if kept > 0 {
+					successMsg += fmt.Sprintf("\n%d nota tidak dihapus karena masih memiliki sisa hutang/piutang atau pembayaran/retur setelah tanggal tersebut.", kept)
 				}
 			}
 
@@ -176,6 +121,82 @@ func showDeleteDataDialog(w fyne.Window, s *state.Session) {
 	d.Show()
 }
 
+// minPasswordLength is the shortest password accepted when changing passwords
+const minPasswordLength = 6
+
+func showChangePasswordDialog(w fyne.Window, s *state.Session) {
+	oldPassword := widget.NewPasswordEntry()
+	newPassword := widget.NewPasswordEntry()
+	confirmPassword := widget.NewPasswordEntry()
+
+	oldPassword.OnSubmitted = func(string) { w.Canvas().Focus(newPassword) }
+	newPassword.OnSubmitted = func(string) { w.Canvas().Focus(confirmPassword) }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Password Lama", oldPassword),
+		widget.NewFormItem("Password Baru", newPassword),
+		widget.NewFormItem("Ulangi Password", confirmPassword),
+	)
+
+	var d dialog.Dialog
+
+	submitBtn := widget.NewButton("Simpan", func() {
+		if oldPassword.Text == "" || newPassword.Text == "" {
+			dialog.ShowInformation("Error", "Password lama dan password baru harus diisi!", w)
+			return
+		}
+		if len(newPassword.Text) < minPasswordLength {
+			dialog.ShowInformation("Error", fmt.Sprintf("Password baru minimal %d karakter!", minPasswordLength), w)
+			return
+		}
+		if newPassword.Text != confirmPassword.Text {
+			dialog.ShowInformation("Error", "Konfirmasi password tidak sama!", w)
+			return
+		}
+
+		err := s.UserRepo.ChangePassword(s.User.ID, oldPassword.Text, newPassword.Text)
+		if err != nil {
+			if errors.Is(err, repository.ErrWrongPassword) {
+				dialog.ShowInformation("Error", "Password lama salah!", w)
+			} else if errors.Is(err, repository.ErrUserDisabled) {
+				dialog.ShowInformation("Error", "Akun Anda sudah dinonaktifkan.", w)
+			} else {
+				dialog.ShowError(fmt.Errorf("Gagal mengganti password: %v", err), w)
+			}
+			return
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", "Password berhasil diganti!", w)
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	confirmPassword.OnSubmitted = func(string) { submitBtn.OnTapped() }
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom("Ganti Password", "", dialogContent, w)
+	d.Resize(fyne.NewSize(420, 260))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			w.Canvas().Focus(oldPassword)
+		})
+	})
+}
+
 func HomePage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 
 	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
@@ -195,23 +216,63 @@ func HomePage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	btnInventory := widget.NewButton("Inventory / Opname", func() {
 		w.SetContent(InventoryPage(w, s))
 	})
+	btnOpname := widget.NewButton("Stock Opname", func() {
+		w.SetContent(OpnamePage(w, s))
+	})
+	btnKartuStok := widget.NewButton("Kartu Stok", func() {
+		w.SetContent(KartuStokPage(w, s))
+	})
+	btnRekonsiliasi := widget.NewButton("Rekonsiliasi Stok", func() {
+		w.SetContent(RekonsiliasiStokPage(w, s))
+	})
 	btnRetur := widget.NewButton("Retur Pembelian", func() {
 		w.SetContent(ReturPage(w, s))
 	})
-	btnLaporan := widget.NewButton("Laporan Penjualan Harian", func() {
-		w.SetContent(LaporanPenjualanPage(w, s))
+	btnSupplier := widget.NewButton("Master Supplier", func() {
+		w.SetContent(SupplierPage(w, s))
+	})
+	btnHutang := widget.NewButton("Hutang Supplier", func() {
+		w.SetContent(HutangPage(w, s))
+	})
+	btnCustomer := widget.NewButton("Master Customer", func() {
+		w.SetContent(CustomerPage(w, s))
+	})
+	btnReturJual := widget.NewButton("Retur Penjualan", func() {
+		w.SetContent(ReturPenjualanPage(w, s))
+	})
+	btnPiutang := widget.NewButton("Piutang Customer", func() {
+		w.SetContent(PiutangPage(w, s))
+	})
+	btnLaporan := widget.NewButton("Laporan", func() {
+		w.SetContent(LaporanMenuPage(w, s))
 	})
 	btnLaporan.Importance = widget.SuccessImportance
 
+	btnUsers := widget.NewButton("Manajemen User", func() {
+		w.SetContent(UserManagementPage(w, s))
+	})
+
+	btnAudit := widget.NewButton("Audit Trail", func() {
+		w.SetContent(AuditLogPage(w, s))
+	})
+
 	btnHapus := widget.NewButton("Hapus Data", func() {
 		showDeleteDataDialog(w, s)
 	})
 	btnHapus.Importance = widget.WarningImportance
 
+	btnPassword := widget.NewButton("Ganti Password", func() {
+		showChangePasswordDialog(w, s)
+	})
+
 	logout := widget.NewButton("Logout", func() {
+		if err := s.UserRepo.RecordLogout(s.User); err != nil {
+			log.Printf("Gagal mencatat logout: %v", err)
+		}
 		s.IsLoggedIn = false
 		s.Username = ""
 		s.User = nil
+		s.Permissions = nil
 		w.SetContent(LoginPage(w, s))
 	})
 	logout.Importance = widget.DangerImportance
@@ -219,17 +280,32 @@ func HomePage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	separator := canvas.NewLine(color.Gray{Y: 120})
 	separator.StrokeWidth = 2
 
-	menu := container.NewVBox(
-		title,
-		btnPenjualan,
-		btnPembelian,
-		btnRetur,
-		btnLaporan,
-		btnInventory,
-		btnHapus,
-		separator,
-		logout,
-	)
+	// Menu yang tidak diizinkan untuk role user tidak ditampilkan
+	menu := container.NewVBox(title)
+	addMenu := func(btn *widget.Button, permission string) {
+		if s.Can(permission) {
+			menu.Add(btn)
+		}
+	}
+	addMenu(btnPenjualan, models.PermSales)
+	addMenu(btnReturJual, models.PermSales)
+	addMenu(btnCustomer, models.PermSales)
+	addMenu(btnPiutang, models.PermSales)
+	addMenu(btnPembelian, models.PermPurchase)
+	addMenu(btnRetur, models.PermPurchase)
+	addMenu(btnSupplier, models.PermPurchase)
+	addMenu(btnHutang, models.PermPurchase)
+	addMenu(btnLaporan, models.PermReports)
+	addMenu(btnInventory, models.PermInventory)
+	addMenu(btnOpname, models.PermInventory)
+	addMenu(btnKartuStok, models.PermInventory)
+	addMenu(btnRekonsiliasi, models.PermReconcile)
+	addMenu(btnUsers, models.PermManageUsers)
+	addMenu(btnAudit, models.PermAuditView)
+	addMenu(btnHapus, models.PermPurgeData)
+	menu.Add(separator)
+	menu.Add(btnPassword)
+	menu.Add(logout)
 
 	// Create a semi-transparent dark gray rectangle for the panel (matching login)
 	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
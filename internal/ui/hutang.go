@@ -0,0 +1,406 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// purchaseLinkSelect picks the nota pembelian a retur reduces the payable of
+type purchaseLinkSelect struct {
+	Select   *widget.Select
+	invoices []models.PayableInvoice
+}
+
+const noPurchaseLink = "- Tidak ditautkan -"
+
+func newPurchaseLinkSelect() *purchaseLinkSelect {
+	p := &purchaseLinkSelect{Select: widget.NewSelect([]string{noPurchaseLink}, nil)}
+	p.Select.SetSelected(noPurchaseLink)
+	return p
+}
+
+// Load lists the notas of the supplier and selects purchaseID when it is one of them
+func (p *purchaseLinkSelect) Load(s *state.Session, supplierID *uuid.UUID, purchaseID *uuid.UUID) {
+	p.invoices = nil
+	if supplierID != nil {
+		p.invoices, _ = s.PayableRepo.GetBySupplier(*supplierID)
+	}
+
+	options := []string{noPurchaseLink}
+	selected := noPurchaseLink
+	for _, inv := range p.invoices {
+		opt := fmt.Sprintf("%s (%s) sisa %s", inv.PurchaseInvoiceNum, inv.PurchaseDate.Format("2006-01-02"), FormatCurrency(inv.Balance))
+		options = append(options, opt)
+		if purchaseID != nil && inv.PurchaseID == *purchaseID {
+			selected = opt
+		}
+	}
+	p.Select.SetOptions(options)
+	p.Select.SetSelected(selected)
+}
+
+// Selected returns the linked nota, or nil when the retur is not linked
+func (p *purchaseLinkSelect) Selected() *uuid.UUID {
+	i := p.Select.SelectedIndex() - 1
+	if i < 0 || i >= len(p.invoices) {
+		return nil
+	}
+	id := p.invoices[i].PurchaseID
+	return &id
+}
+
+// showPurchasePaymentDialog records a (partial) payment of one nota pembelian
+func showPurchasePaymentDialog(w fyne.Window, s *state.Session, invoice models.PayableInvoice, onClose func()) {
+	info := []*widget.FormItem{
+		widget.NewFormItem("No. Nota", widget.NewLabel(invoice.PurchaseInvoiceNum)),
+		widget.NewFormItem("Supplier", widget.NewLabel(invoice.SupplierName)),
+		widget.NewFormItem("Jatuh Tempo", widget.NewLabel(invoice.DueDate.Format("2006-01-02"))),
+		widget.NewFormItem("Total Nota", widget.NewLabel(FormatCurrency(invoice.TotalAmount))),
+		widget.NewFormItem("Sudah Dibayar", widget.NewLabel(FormatCurrency(invoice.PaidAmount))),
+		widget.NewFormItem("Retur", widget.NewLabel(FormatCurrency(invoice.ReturAmount))),
+	}
+
+	showPaymentDialog(w, "Bayar Hutang", info, invoice.Balance, func(in paymentInput) error {
+		return s.PayableRepo.AddPayment(&models.PurchasePayment{
+			PurchaseID:  invoice.PurchaseID,
+			PaymentDate: in.Date,
+			Amount:      in.Amount,
+			Method:      in.Method,
+			Reference:   in.Reference,
+			Note:        in.Note,
+			CreatedBy:   &s.User.ID,
+		})
+	}, onClose)
+}
+
+// showPurchasePaymentsDialog lists the payments of a nota pembelian
+func showPurchasePaymentsDialog(w fyne.Window, s *state.Session, invoice models.PayableInvoice, onClose func()) {
+	payments, err := s.PayableRepo.GetPayments(invoice.PurchaseID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+		return
+	}
+
+	rows := make([]paymentRow, len(payments))
+	for i, p := range payments {
+		rows[i] = paymentRow{ID: p.ID, Date: p.PaymentDate, Amount: p.Amount, Method: p.Method,
+			Reference: p.Reference, Note: p.Note, Status: p.Status}
+	}
+
+	summary := fmt.Sprintf("%s - %s\nTotal %s, dibayar %s, retur %s, sisa %s",
+		invoice.PurchaseInvoiceNum, invoice.SupplierName, FormatCurrency(invoice.TotalAmount),
+		FormatCurrency(invoice.PaidAmount), FormatCurrency(invoice.ReturAmount), FormatCurrency(invoice.Balance))
+
+	showPaymentsDialog(w, s, summary, rows, func(id uuid.UUID) error {
+		return s.PayableRepo.VoidPayment(id, s.User.ID)
+	}, onClose)
+}
+
+// HutangPage lists the notas pembelian that are not fully paid
+func HutangPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("HUTANG SUPPLIER", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search no. nota atau supplier...")
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), container.NewMax(search))
+
+	headers := []string{"No. Nota", "Supplier", "Tgl. Nota", "Jatuh Tempo", "Total", "Dibayar", "Retur", "Sisa", "Telat"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []models.PayableInvoice
+	var selectedRow int = -1
+	var totalBalance float64
+
+	totalLabel := canvas.NewText("", color.White)
+	totalLabel.TextStyle = fyne.TextStyle{Bold: true}
+	totalLabel.Alignment = fyne.TextAlignTrailing
+
+	loadData := func(keyword string) {
+		invoices, err := s.PayableRepo.GetOutstanding()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+
+		data = nil
+		totalBalance = 0
+		for _, inv := range invoices {
+			if keyword != "" && !containsCI(inv.PurchaseInvoiceNum, keyword) && !containsCI(inv.SupplierName, keyword) {
+				continue
+			}
+			data = append(data, inv)
+			totalBalance += inv.Balance
+		}
+		totalLabel.Text = "Total Sisa Hutang: " + FormatCurrency(totalBalance)
+		totalLabel.Refresh()
+	}
+
+	loadData("")
+
+	now := time.Now()
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				inv := data[id.Row-1]
+				overdue := models.DaysOverdue(inv.DueDate, now)
+				if overdue > 0 && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = inv.PurchaseInvoiceNum
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = inv.SupplierName
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = inv.PurchaseDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = inv.DueDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = FormatCurrency(inv.TotalAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = FormatCurrency(inv.PaidAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 6:
+					text.Text = FormatCurrency(inv.ReturAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 7:
+					text.Text = FormatCurrency(inv.Balance)
+					text.Alignment = fyne.TextAlignTrailing
+				case 8:
+					text.Text = ""
+					if overdue > 0 {
+						text.Text = fmt.Sprintf("%d hari", overdue)
+					}
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 130)
+	table.SetColumnWidth(1, 170)
+	table.SetColumnWidth(2, 95)
+	table.SetColumnWidth(3, 100)
+	table.SetColumnWidth(4, 110)
+	table.SetColumnWidth(5, 110)
+	table.SetColumnWidth(6, 90)
+	table.SetColumnWidth(7, 110)
+	table.SetColumnWidth(8, 70)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable := func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+		safeFocus()
+	}
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		// Bayar nota terpilih
+		case fyne.KeyB, fyne.KeyReturn:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showPurchasePaymentDialog(w, s, data[selectedRow], func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Riwayat pembayaran
+		case fyne.KeyR:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showPurchasePaymentsDialog(w, s, data[selectedRow], func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Lihat nota pembelian
+		case fyne.KeyV:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				showViewPembelianDialog(w, s, data[selectedRow].PurchaseID)
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Umur hutang per supplier
+		case fyne.KeyA:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showAgingDialog(w, "UMUR HUTANG SUPPLIER", "Supplier", s.PayableRepo.GetAging, func() { isDialogOpen = false; safeFocus() })
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(980, 450), focusWrapper))
+
+	footer := canvas.NewText("[B] Bayar  [R] Riwayat Bayar  [V] Lihat Nota  [A] Umur Hutang  |  ↑↓ = Navigate", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, container.NewVBox(container.NewHBox(layout.NewSpacer(), totalLabel), footer), nil, nil, tableWrapper)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicPayable)
+
+	return page
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"sort"
@@ -16,6 +17,8 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 
 	"github.com/google/uuid"
@@ -24,12 +27,14 @@ import (
 )
 
 type InventoryItem struct {
-	ID         uuid.UUID
-	Code       string
-	Name       string
-	Qty        string
-	Price      string
-	HargaModal string
+	ID                 uuid.UUID
+	Code               string
+	Name               string
+	Qty                string
+	Price              string
+	HargaModal         string
+	AllowNegativeStock bool
+	Version            int
 }
 
 func showAddInventoryDialog(w fyne.Window, s *state.Session, dialogOpen *bool, refreshCallback func()) {
@@ -38,17 +43,23 @@ func showAddInventoryDialog(w fyne.Window, s *state.Session, dialogOpen *bool, r
 	nama := widget.NewEntry()
 	qty := widget.NewEntry()
 	price := widget.NewEntry()
+	hppAwal := widget.NewEntry()
+	hppAwal.SetPlaceHolder("Harga modal per unit stok awal")
+	allowNegative := widget.NewCheck("Boleh dijual melebihi stok", nil)
 
-	// Focus flow: kode → nama → qty → price → submit
+	// Focus flow: kode → nama → qty → price → hpp awal → submit
 	kode.OnSubmitted = func(string) { w.Canvas().Focus(nama) }
 	nama.OnSubmitted = func(string) { w.Canvas().Focus(qty) }
 	qty.OnSubmitted = func(string) { w.Canvas().Focus(price) }
+	price.OnSubmitted = func(string) { w.Canvas().Focus(hppAwal) }
 
 	form := widget.NewForm(
 		widget.NewFormItem("Kode", kode),
 		widget.NewFormItem("Nama", nama),
 		widget.NewFormItem("Qty", qty),
 		widget.NewFormItem("Harga", price),
+		widget.NewFormItem("HPP Awal", hppAwal),
+		widget.NewFormItem("Stok Minus", allowNegative),
 	)
 
 	bg := canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
@@ -75,12 +86,28 @@ func showAddInventoryDialog(w fyne.Window, s *state.Session, dialogOpen *bool, r
 			return
 		}
 
+		// Stok awal harus punya harga modal supaya tidak masuk HPP dengan nilai 0
+		var hppVal float64
+		if qtyVal > 0 {
+			if hppAwal.Text == "" {
+				dialog.ShowError(fmt.Errorf("HPP Awal harus diisi untuk stok awal!"), w)
+				return
+			}
+			hppVal, err = ParseCurrencyString(hppAwal.Text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("HPP Awal harus berupa angka!"), w)
+				return
+			}
+		}
+
 		item := &models.Item{
-			Code:      kode.Text,
-			Name:      nama.Text,
-			Qty:       qtyVal,
-			Price:     priceVal,
-			CreatedBy: &s.User.ID,
+			Code:               kode.Text,
+			Name:               nama.Text,
+			Qty:                qtyVal,
+			Price:              priceVal,
+			AvgCost:            hppVal,
+			AllowNegativeStock: allowNegative.Checked,
+			CreatedBy:          &s.User.ID,
 		}
 
 		err = s.ItemRepo.Create(item)
@@ -101,7 +128,7 @@ func showAddInventoryDialog(w fyne.Window, s *state.Session, dialogOpen *bool, r
 	submitBtn.Importance = widget.HighImportance
 
 	// Enter on last field triggers submit
-	price.OnSubmitted = func(string) { submitBtn.OnTapped() }
+	hppAwal.OnSubmitted = func(string) { submitBtn.OnTapped() }
 
 	cancelBtn := widget.NewButton("Cancel", func() {
 		d.Hide()
@@ -118,7 +145,7 @@ func showAddInventoryDialog(w fyne.Window, s *state.Session, dialogOpen *bool, r
 	)
 
 	d = dialog.NewCustom("Add new data", "", dialogContent, w)
-	d.Resize(fyne.NewSize(420, 320))
+	d.Resize(fyne.NewSize(420, 400))
 	d.Show()
 }
 
@@ -131,21 +158,26 @@ func showEditInventoryDialog(w fyne.Window, s *state.Session, item InventoryItem
 	nama := widget.NewEntry()
 	nama.SetText(item.Name)
 
+	// Stok hanya berubah lewat transaksi, opname atau rekonsiliasi
 	qty := widget.NewEntry()
 	qty.SetText(item.Qty)
+	qty.Disable()
 
 	price := widget.NewEntry()
 	price.SetText(item.Price)
 
-	// Focus flow: nama → qty → price → submit (kode is disabled)
-	nama.OnSubmitted = func(string) { w.Canvas().Focus(qty) }
-	qty.OnSubmitted = func(string) { w.Canvas().Focus(price) }
+	allowNegative := widget.NewCheck("Boleh dijual melebihi stok", nil)
+	allowNegative.SetChecked(item.AllowNegativeStock)
+
+	// Focus flow: nama → price → submit (kode and qty are disabled)
+	nama.OnSubmitted = func(string) { w.Canvas().Focus(price) }
 
 	form := widget.NewForm(
 		widget.NewFormItem("Kode", kode),
 		widget.NewFormItem("Nama", nama),
 		widget.NewFormItem("Qty", qty),
 		widget.NewFormItem("Harga", price),
+		widget.NewFormItem("Stok Minus", allowNegative),
 	)
 
 	bg := canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
@@ -154,18 +186,13 @@ func showEditInventoryDialog(w fyne.Window, s *state.Session, item InventoryItem
 
 	var d dialog.Dialog
 
-	submitBtn := widget.NewButton("Submit", func() {
-		if nama.Text == "" || qty.Text == "" || price.Text == "" {
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Submit", func() {
+		if nama.Text == "" || price.Text == "" {
 			dialog.ShowError(fmt.Errorf("Semua field harus diisi!"), w)
 			return
 		}
 
-		qtyVal, err := strconv.ParseFloat(qty.Text, 64)
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("Qty harus berupa angka!"), w)
-			return
-		}
-
 		priceVal, err := ParseCurrencyString(price.Text)
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("Harga harus berupa angka!"), w)
@@ -173,15 +200,43 @@ func showEditInventoryDialog(w fyne.Window, s *state.Session, item InventoryItem
 		}
 
 		updatedItem := &models.Item{
-			ID:        item.ID,
-			Code:      item.Code,
-			Name:      nama.Text,
-			Qty:       qtyVal,
-			Price:     priceVal,
-			UpdatedBy: &s.User.ID,
+			ID:                 item.ID,
+			Code:               item.Code,
+			Name:               nama.Text,
+			Price:              priceVal,
+			AllowNegativeStock: allowNegative.Checked,
+			Version:            item.Version,
+			UpdatedBy:          &s.User.ID,
 		}
 
 		err = s.ItemRepo.Update(updatedItem)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			latest, err := s.ItemRepo.GetByID(item.ID)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+				return
+			}
+			reload := func() {
+				d.Hide()
+				item.Name = latest.Name
+				item.Qty = fmt.Sprintf("%.0f", latest.Qty)
+				item.Price = fmt.Sprintf("%.0f", latest.Price)
+				item.AllowNegativeStock = latest.AllowNegativeStock
+				item.Version = latest.Version
+				showEditInventoryDialog(w, s, item, dialogOpen, refreshCallback)
+			}
+			// Stok yang berubah berarti ada transaksi di workstation lain: form ini sudah basi
+			if fmt.Sprintf("%.0f", latest.Qty) != item.Qty {
+				showReloadOnlyDialog(w, "Stok barang ini sudah berubah oleh transaksi lain sejak Anda membukanya.\n"+
+					"Muat ulang untuk melihat data terbaru sebelum mengubah barang.", reload)
+				return
+			}
+			showConflictDialog(w, reload, func() {
+				item.Version = latest.Version
+				submitBtn.OnTapped()
+			})
+			return
+		}
 		if err != nil {
 			dialog.ShowError(fmt.Errorf("Gagal mengupdate data: %v", err), w)
 			return
@@ -216,7 +271,7 @@ func showEditInventoryDialog(w fyne.Window, s *state.Session, item InventoryItem
 	)
 
 	d = dialog.NewCustom("Edit data", "", dialogContent, w)
-	d.Resize(fyne.NewSize(420, 320))
+	d.Resize(fyne.NewSize(420, 360))
 	d.Show()
 }
 
@@ -227,7 +282,10 @@ func InventoryPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	bg.FillMode = canvas.ImageFillStretch
 
 	// ===== HEADER ELEMENTS =====
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
 	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
 		w.SetContent(HomePage(w, s))
 	})
 
@@ -310,25 +368,23 @@ func InventoryPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 			return
 		}
 
-		// Fetch latest purchase prices (harga beli) for harga modal
-		purchasePrices, _ := s.ItemRepo.GetLatestPurchasePrices()
-
 		data = make([]InventoryItem, len(items))
 		for i, item := range items {
+			// Harga modal = HPP rata-rata bergerak yang dijaga oleh pembelian dan retur
 			hargaModal := "-"
-			if purchasePrices != nil {
-				if price, ok := purchasePrices[item.ID]; ok {
-					p := message.NewPrinter(language.Indonesian)
-					hargaModal = p.Sprintf("%.0f", price)
-				}
+			if item.AvgCost > 0 {
+				p := message.NewPrinter(language.Indonesian)
+				hargaModal = p.Sprintf("%.0f", item.AvgCost)
 			}
 			data[i] = InventoryItem{
-				ID:         item.ID,
-				Code:       item.Code,
-				Name:       item.Name,
-				Qty:        fmt.Sprintf("%.0f", item.Qty),
-				Price:      fmt.Sprintf("%.0f", item.Price),
-				HargaModal: hargaModal,
+				ID:                 item.ID,
+				Code:               item.Code,
+				Name:               item.Name,
+				Qty:                fmt.Sprintf("%.0f", item.Qty),
+				Price:              fmt.Sprintf("%.0f", item.Price),
+				HargaModal:         hargaModal,
+				AllowNegativeStock: item.AllowNegativeStock,
+				Version:            item.Version,
 			}
 		}
 	}
@@ -522,6 +578,17 @@ func InventoryPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 			} else {
 				dialog.ShowInformation("Info", "Pilih data terlebih dahulu!", w)
 			}
+		case fyne.KeyH:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				dialogOpen = true
+				showItemCostHistoryDialog(w, s, data[selectedRow], func() {
+					dialogOpen = false
+					safeFocus()
+				})
+			} else {
+				dialog.ShowInformation("Info", "Pilih data terlebih dahulu!", w)
+			}
 		case fyne.KeyDelete:
 			lastDialogTime = time.Now()
 			if selectedRow >= 0 && selectedRow < len(data) {
@@ -615,7 +682,7 @@ func InventoryPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	w.Canvas().SetOnTypedKey(handleKey)
 
 	// ===== FOOTER =====
-	footer := canvas.NewText("Insert = input data   |   E = Edit data   |   Del = Delete data   |   ↑↓ = Navigate", color.White)
+	footer := canvas.NewText("Insert = input data   |   E = Edit data   |   H = Riwayat HPP   |   Del = Delete data   |   ↑↓ = Navigate", color.White)
 	footer.Alignment = fyne.TextAlignCenter
 	footer.TextStyle = fyne.TextStyle{Italic: true}
 
@@ -659,8 +726,20 @@ func InventoryPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 		})
 	})
 
-	return container.NewMax(
+	page := container.NewMax(
 		bg,
 		centeredPanel,
 	)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		sortData()
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicItems, notify.TopicPurchase, notify.TopicRetur)
+
+	return page
 }
@@ -0,0 +1,273 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+)
+
+func KartuStokPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("KARTU STOK", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), widget.NewLabel(""))
+
+	// ===== FILTER =====
+	var itemOptions []string
+	allItems, _ := s.ItemRepo.GetAll()
+	for _, it := range allItems {
+		itemOptions = append(itemOptions, fmt.Sprintf("%s - %s", it.Code, it.Name))
+	}
+
+	itemSelect := widget.NewSelectEntry(itemOptions)
+	itemSelect.PlaceHolder = "Pilih Barang..."
+	itemSelect.OnChanged = func(value string) {
+		if value == "" {
+			itemSelect.SetOptions(itemOptions)
+			return
+		}
+		var filtered []string
+		for _, opt := range itemOptions {
+			if containsCI(opt, value) {
+				filtered = append(filtered, opt)
+			}
+		}
+		itemSelect.SetOptions(filtered)
+	}
+
+	now := time.Now()
+	startLabel := widget.NewLabel(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02"))
+	endLabel := widget.NewLabel(now.Format("2006-01-02"))
+
+	startBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, startLabel.Text, func(selectedDate string) {
+			startLabel.SetText(selectedDate)
+		})
+	})
+	endBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, endLabel.Text, func(selectedDate string) {
+			endLabel.SetText(selectedDate)
+		})
+	})
+
+	var card *models.StockCard
+
+	// ===== TABLE =====
+	colHeaders := []string{"Tanggal", "No. Dokumen", "Jenis", "Masuk", "Keluar", "Saldo"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	balanceBg := color.NRGBA{R: 210, G: 220, B: 240, A: 255}
+
+	// Row 1 is the opening balance, the last row the closing balance
+	rowCount := func() int {
+		if card == nil {
+			return 1
+		}
+		return len(card.Entries) + 3
+	}
+
+	table := widget.NewTable(
+		func() (int, int) { return rowCount(), len(colHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			text.Color = color.Black
+			text.TextSize = 13
+			text.TextStyle = fyne.TextStyle{}
+			text.Text = ""
+
+			if card == nil {
+				text.Refresh()
+				return
+			}
+
+			lastRow := len(card.Entries) + 2
+			switch {
+			case id.Row == 1 || id.Row == lastRow:
+				bg.FillColor = balanceBg
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				label, balance := "Saldo Awal", card.OpeningBalance
+				if id.Row == lastRow {
+					label, balance = "Saldo Akhir", card.ClosingBalance
+				}
+				switch id.Col {
+				case 1:
+					text.Text = label
+					text.Alignment = fyne.TextAlignLeading
+				case 5:
+					text.Text = fmt.Sprintf("%.0f", balance)
+					text.Alignment = fyne.TextAlignTrailing
+				}
+			default:
+				bg.FillColor = rowBg
+				e := card.Entries[id.Row-2]
+				switch id.Col {
+				case 0:
+					text.Text = e.TrxDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = e.DocumentNum
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = MutationTypeLabel(e.ModelType)
+					text.Alignment = fyne.TextAlignLeading
+					if strings.HasPrefix(e.ModelType, "void_") {
+						text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+					}
+				case 3:
+					if e.QtyIn() > 0 {
+						text.Text = fmt.Sprintf("%.0f", e.QtyIn())
+					}
+					text.Alignment = fyne.TextAlignTrailing
+				case 4:
+					if e.QtyOut() > 0 {
+						text.Text = fmt.Sprintf("%.0f", e.QtyOut())
+					}
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = fmt.Sprintf("%.0f", e.Balance)
+					text.Alignment = fyne.TextAlignTrailing
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 130)
+	table.SetColumnWidth(1, 240)
+	table.SetColumnWidth(2, 180)
+	table.SetColumnWidth(3, 120)
+	table.SetColumnWidth(4, 120)
+	table.SetColumnWidth(5, 140)
+
+	resolveItem := func() (*models.Item, error) {
+		parts := strings.SplitN(itemSelect.Text, " - ", 2)
+		if itemSelect.Text == "" || parts[0] == "" {
+			return nil, fmt.Errorf("Pilih barang terlebih dahulu!")
+		}
+		for i := range allItems {
+			if allItems[i].Code == parts[0] {
+				return &allItems[i], nil
+			}
+		}
+		return nil, fmt.Errorf("Barang tidak ditemukan!")
+	}
+
+	showBtn := widget.NewButtonWithIcon("Tampilkan", theme.SearchIcon(), func() {
+		item, err := resolveItem()
+		if err != nil {
+			dialog.ShowInformation("Info", err.Error(), w)
+			return
+		}
+
+		startDate, err1 := time.Parse("2006-01-02", startLabel.Text)
+		endDate, err2 := time.Parse("2006-01-02", endLabel.Text)
+		if err1 != nil || err2 != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+		if endDate.Before(startDate) {
+			dialog.ShowInformation("Info", "Tanggal akhir tidak boleh sebelum tanggal awal!", w)
+			return
+		}
+
+		card, err = s.MutationRepo.GetStockCard(*item, startDate, endDate)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat kartu stok: %v", err), w)
+			return
+		}
+		table.Refresh()
+		table.ScrollToTop()
+	})
+	showBtn.Importance = widget.HighImportance
+
+	printBtn := widget.NewButtonWithIcon("Cetak PDF", theme.DocumentPrintIcon(), func() {
+		if card == nil {
+			dialog.ShowInformation("Info", "Tampilkan kartu stok terlebih dahulu!", w)
+			return
+		}
+		if err := PrintKartuStok(card); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal mencetak kartu stok: %v", err), w)
+		}
+	})
+
+	filterForm := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Barang"), nil, itemSelect),
+		container.NewHBox(
+			widget.NewLabel("Dari"), startLabel, startBtn,
+			widget.NewLabel("s/d"), endLabel, endBtn,
+			showBtn, printBtn,
+		),
+	)
+
+	filterBg := canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	filterBg.CornerRadius = 6
+	filterPanel := container.NewMax(filterBg, container.NewPadded(filterForm))
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 420), table))
+
+	footer := canvas.NewText("Pilih barang dan periode, lalu tekan Tampilkan", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(
+		container.NewVBox(header, filterPanel),
+		footer,
+		nil,
+		nil,
+		tableWrapper,
+	)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	// Reset keyboard handler left behind by the previous page
+	w.Canvas().SetOnTypedKey(nil)
+
+	return container.NewMax(bg, centeredPanel)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/state"
+)
+
+// LaporanMenuPage groups every report behind the "Laporan" button of the home menu
+func LaporanMenuPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	title := canvas.NewText("Laporan", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	btnPenjualan := widget.NewButton("Laporan Penjualan Harian", func() {
+		w.SetContent(LaporanPenjualanPage(w, s))
+	})
+	btnPembelian := widget.NewButton("Laporan Pembelian", func() {
+		w.SetContent(LaporanPembelianPage(w, s))
+	})
+	btnLaba := widget.NewButton("Laporan Laba Kotor", func() {
+		w.SetContent(LaporanLabaPage(w, s))
+	})
+	btnPersediaan := widget.NewButton("Nilai Persediaan", func() {
+		w.SetContent(NilaiPersediaanPage(w, s))
+	})
+
+	backBtn := widget.NewButtonWithIcon("Kembali", theme.NavigateBackIcon(), func() {
+		w.SetContent(HomePage(w, s))
+	})
+
+	separator := canvas.NewLine(color.Gray{Y: 120})
+	separator.StrokeWidth = 2
+
+	menu := container.NewVBox(
+		title,
+		btnPenjualan,
+		btnPembelian,
+		btnLaba,
+		btnPersediaan,
+		separator,
+		backBtn,
+	)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(360, 200))
+
+	panel := container.NewMax(rect, container.NewPadded(menu))
+	centeredPanel := container.NewCenter(panel)
+
+	// Reset keyboard handler left behind by the previous page
+	w.Canvas().SetOnTypedKey(nil)
+
+	return container.NewMax(bg, centeredPanel)
+}
@@ -0,0 +1,380 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+)
+
+// purchaseGroupOptions are the choices of the "Kelompok" select, in display order
+var purchaseGroupOptions = []struct {
+	Label   string
+	GroupBy string
+	Column  string
+}{
+	{"Per Hari", models.PurchaseByDay, "Tanggal"},
+	{"Per Supplier", models.PurchaseBySupplier, "Supplier"},
+	{"Per Barang", models.PurchaseByItem, "Barang"},
+}
+
+// purchaseStatusFilter maps the "Status" select to the status passed to the repository
+func purchaseStatusFilter(selected string) string {
+	if selected == "Semua" {
+		return ""
+	}
+	return selected
+}
+
+// showLaporanPembelianDetailDialog lists the nota lines behind one row of the laporan pembelian
+func showLaporanPembelianDetailDialog(w fyne.Window, s *state.Session, groupBy, status string, row models.PurchaseSummary, startDate, endDate time.Time, onClose func()) {
+	lines, err := s.PurchaseRepo.GetReportLines(groupBy, row.GroupKey, status, startDate, endDate)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+		if onClose != nil {
+			onClose()
+		}
+		return
+	}
+
+	colHeaders := []string{"Tanggal", "No. Nota", "Supplier", "Barang", "Qty", "Harga", "Total", "Status"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	voidColor := color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+
+	table := widget.NewTable(
+		func() (int, int) { return len(lines) + 1, len(colHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			bg.FillColor = rowBg
+			text.Color = color.Black
+			text.TextStyle = fyne.TextStyle{}
+
+			if id.Row-1 < len(lines) {
+				l := lines[id.Row-1]
+				if l.Status == "VOID" {
+					text.Color = voidColor
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = l.PurchaseDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = l.PurchaseInvoiceNum
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = l.SupplierName
+					text.Alignment = fyne.TextAlignLeading
+				case 3:
+					text.Text = l.ItemCode + " - " + l.ItemName
+					text.Alignment = fyne.TextAlignLeading
+				case 4:
+					text.Text = fmt.Sprintf("%.0f", l.Qty)
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = FormatCurrency(l.PriceAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 6:
+					text.Text = FormatCurrency(l.TotalAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 7:
+					text.Text = l.Status
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 100)
+	table.SetColumnWidth(1, 150)
+	table.SetColumnWidth(2, 160)
+	table.SetColumnWidth(3, 220)
+	table.SetColumnWidth(4, 60)
+	table.SetColumnWidth(5, 110)
+	table.SetColumnWidth(6, 130)
+	table.SetColumnWidth(7, 80)
+
+	// Klik baris untuk membuka nota pembelian asalnya
+	table.OnSelected = func(id widget.TableCellID) {
+		table.UnselectAll()
+		if id.Row < 1 || id.Row-1 >= len(lines) {
+			return
+		}
+		showViewPembelianDialog(w, s, lines[id.Row-1].HeaderID)
+	}
+
+	var d dialog.Dialog
+
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	closeBtn.Importance = widget.HighImportance
+
+	summary := fmt.Sprintf("%s\n%d nota, qty %.0f, total pembelian %s",
+		row.GroupName, row.NotaCount, row.Qty, FormatCurrency(row.TotalAmount))
+
+	content := container.NewBorder(
+		container.NewVBox(widget.NewLabel(summary), widget.NewSeparator()),
+		container.NewHBox(layout.NewSpacer(), closeBtn),
+		nil, nil,
+		table,
+	)
+
+	d = dialog.NewCustom("Detail Pembelian", "", container.NewPadded(content), w)
+	d.Resize(fyne.NewSize(1080, 500))
+	d.Show()
+}
+
+// LaporanPembelianPage shows the number of notas, qty and amount purchased per day, supplier or item
+func LaporanPembelianPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(LaporanMenuPage(w, s))
+	})
+
+	title := canvas.NewText("LAPORAN PEMBELIAN", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), widget.NewLabel(""))
+
+	// ===== FILTER =====
+	var groupLabels []string
+	for _, g := range purchaseGroupOptions {
+		groupLabels = append(groupLabels, g.Label)
+	}
+	groupSelect := widget.NewSelect(groupLabels, nil)
+	groupSelect.SetSelectedIndex(0)
+
+	statusSelect := widget.NewSelect([]string{"Semua", "ACTIVE", "VOID"}, nil)
+	statusSelect.SetSelected("ACTIVE")
+
+	now := time.Now()
+	startLabel := widget.NewLabel(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02"))
+	endLabel := widget.NewLabel(now.Format("2006-01-02"))
+
+	startBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, startLabel.Text, func(selectedDate string) {
+			startLabel.SetText(selectedDate)
+		})
+	})
+	endBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, endLabel.Text, func(selectedDate string) {
+			endLabel.SetText(selectedDate)
+		})
+	})
+
+	// Filter yang dipakai saat data terakhir dimuat, untuk drill-down
+	var rows []models.PurchaseSummary
+	var total models.PurchaseSummary
+	groupIndex := 0
+	var status string
+	var startDate, endDate time.Time
+
+	// ===== TABLE =====
+	colHeaders := []string{"", "Jumlah Nota", "Qty", "Total Pembelian"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	totalBg := color.NRGBA{R: 210, G: 220, B: 240, A: 255}
+
+	// The last row is the grand total
+	table := widget.NewTable(
+		func() (int, int) {
+			if len(rows) == 0 {
+				return 1, len(colHeaders)
+			}
+			return len(rows) + 2, len(colHeaders)
+		},
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				if id.Col == 0 {
+					text.Text = purchaseGroupOptions[groupIndex].Column
+				}
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			text.Color = color.Black
+			text.TextSize = 13
+			text.TextStyle = fyne.TextStyle{}
+
+			r := total
+			bg.FillColor = totalBg
+			if id.Row-1 < len(rows) {
+				r = rows[id.Row-1]
+				bg.FillColor = rowBg
+			} else {
+				text.TextStyle = fyne.TextStyle{Bold: true}
+			}
+
+			switch id.Col {
+			case 0:
+				text.Text = r.GroupName
+				text.Alignment = fyne.TextAlignLeading
+			case 1:
+				text.Text = fmt.Sprintf("%d", r.NotaCount)
+				text.Alignment = fyne.TextAlignCenter
+				// Satu nota bisa berisi beberapa barang, jadi jumlah nota per barang tidak bisa dijumlahkan
+				if id.Row-1 >= len(rows) && purchaseGroupOptions[groupIndex].GroupBy == models.PurchaseByItem {
+					text.Text = "-"
+				}
+			case 2:
+				text.Text = fmt.Sprintf("%.0f", r.Qty)
+				text.Alignment = fyne.TextAlignTrailing
+			case 3:
+				text.Text = FormatCurrency(r.TotalAmount)
+				text.Alignment = fyne.TextAlignTrailing
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 360)
+	table.SetColumnWidth(1, 150)
+	table.SetColumnWidth(2, 150)
+	table.SetColumnWidth(3, 260)
+
+	var isDialogOpen bool
+
+	table.OnSelected = func(id widget.TableCellID) {
+		table.UnselectAll()
+		if isDialogOpen || id.Row < 1 || id.Row-1 >= len(rows) {
+			return
+		}
+		isDialogOpen = true
+		showLaporanPembelianDetailDialog(w, s, purchaseGroupOptions[groupIndex].GroupBy, status, rows[id.Row-1], startDate, endDate, func() {
+			isDialogOpen = false
+		})
+	}
+
+	showBtn := widget.NewButtonWithIcon("Tampilkan", theme.SearchIcon(), func() {
+		start, err1 := time.Parse("2006-01-02", startLabel.Text)
+		end, err2 := time.Parse("2006-01-02", endLabel.Text)
+		if err1 != nil || err2 != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+		if end.Before(start) {
+			dialog.ShowInformation("Info", "Tanggal akhir tidak boleh sebelum tanggal awal!", w)
+			return
+		}
+
+		index := groupSelect.SelectedIndex()
+		if index < 0 {
+			index = 0
+		}
+		selectedStatus := purchaseStatusFilter(statusSelect.Selected)
+		result, err := s.PurchaseRepo.GetReportSummary(purchaseGroupOptions[index].GroupBy, selectedStatus, start, end)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat laporan pembelian: %v", err), w)
+			return
+		}
+
+		rows = result
+		groupIndex = index
+		status = selectedStatus
+		startDate, endDate = start, end
+		total = models.PurchaseSummary{GroupName: "TOTAL"}
+		for _, r := range rows {
+			total.NotaCount += r.NotaCount
+			total.Qty += r.Qty
+			total.TotalAmount += r.TotalAmount
+		}
+		table.Refresh()
+		table.ScrollToTop()
+	})
+	showBtn.Importance = widget.HighImportance
+
+	filterForm := container.NewHBox(
+		widget.NewLabel("Kelompok"), groupSelect,
+		widget.NewLabel("Status"), statusSelect,
+		widget.NewLabel("Dari"), startLabel, startBtn,
+		widget.NewLabel("s/d"), endLabel, endBtn,
+		showBtn,
+	)
+
+	filterBg := canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	filterBg.CornerRadius = 6
+	filterPanel := container.NewMax(filterBg, container.NewPadded(filterForm))
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 440), table))
+
+	footer := canvas.NewText("Klik baris untuk melihat rincian, klik rincian untuk membuka nota pembelian", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(
+		container.NewVBox(header, filterPanel),
+		footer,
+		nil,
+		nil,
+		tableWrapper,
+	)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	// Reset keyboard handler left behind by the previous page
+	w.Canvas().SetOnTypedKey(nil)
+
+	return container.NewMax(bg, centeredPanel)
+}
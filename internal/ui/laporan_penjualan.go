@@ -14,12 +14,15 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
 )
 
 type LaporanRow struct {
 	Date             time.Time
 	DateStr          string
 	TransactionCount string
+	ReturAmount      string
 	TotalAmount      string
 }
 
@@ -31,8 +34,16 @@ func showLaporanDetailDialog(w fyne.Window, s *state.Session, date time.Time, on
 		return
 	}
 
+	// Retur penjualan pada tanggal ini tampil sebagai baris negatif
+	returs, err := s.SellReturRepo.GetByDate(date)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat data retur: %v", err), w)
+		return
+	}
+
 	type DetailRow struct {
-		ID       string
+		ID       uuid.UUID
+		IsRetur  bool
 		NoNota   string
 		Customer string
 		Total    string
@@ -43,7 +54,7 @@ func showLaporanDetailDialog(w fyne.Window, s *state.Session, date time.Time, on
 	var grandTotal float64
 	for _, h := range headers {
 		rows = append(rows, DetailRow{
-			ID:       h.ID.String(),
+			ID:       h.ID,
 			NoNota:   h.SellInvoiceNum,
 			Customer: h.CustomerName,
 			Total:    FormatCurrency(h.TotalAmount),
@@ -53,6 +64,19 @@ func showLaporanDetailDialog(w fyne.Window, s *state.Session, date time.Time, on
 			grandTotal += h.TotalAmount
 		}
 	}
+	for _, r := range returs {
+		rows = append(rows, DetailRow{
+			ID:       r.ID,
+			IsRetur:  true,
+			NoNota:   r.ReturInvoiceNum,
+			Customer: "Retur " + r.SellInvoiceNum + " - " + r.CustomerName,
+			Total:    FormatCurrency(-r.TotalAmount),
+			Status:   r.Status,
+		})
+		if r.Status == "ACTIVE" {
+			grandTotal -= r.TotalAmount
+		}
+	}
 
 	dateLabel := canvas.NewText(
 		fmt.Sprintf("Tanggal: %s", date.Format("2006-01-02")),
@@ -152,10 +176,14 @@ func showLaporanDetailDialog(w fyne.Window, s *state.Session, date time.Time, on
 		if isSubDialogOpen {
 			return
 		}
-		if id.Row > 0 && id.Row-1 < len(headers) {
+		if id.Row > 0 && id.Row-1 < len(rows) {
 			isSubDialogOpen = true
-			headerData := headers[id.Row-1]
-			sell, err := s.SellRepo.GetByID(headerData.ID)
+			row := rows[id.Row-1]
+			if row.IsRetur {
+				showViewReturPenjualanDialog(w, s, row.ID, func() { isSubDialogOpen = false })
+				return
+			}
+			sell, err := s.SellRepo.GetByID(row.ID)
 			if err != nil {
 				isSubDialogOpen = false
 				dialog.ShowError(err, w)
@@ -224,7 +252,7 @@ func LaporanPenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 
 	// Header
 	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
-		w.SetContent(HomePage(w, s))
+		w.SetContent(LaporanMenuPage(w, s))
 	})
 
 	title := canvas.NewText("LAPORAN PENJUALAN HARIAN", color.White)
@@ -261,10 +289,15 @@ func LaporanPenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 			if keyword != "" && !containsCI(dateStr, keyword) {
 				continue
 			}
+			returLabel := "-"
+			if r.ReturAmount != 0 {
+				returLabel = FormatCurrency(r.ReturAmount)
+			}
 			data = append(data, LaporanRow{
 				Date:             r.SellDate,
 				DateStr:          dateStr,
 				TransactionCount: fmt.Sprintf("%d", r.TransactionCount),
+				ReturAmount:      returLabel,
 				TotalAmount:      FormatCurrency(r.TotalAmount),
 			})
 		}
@@ -273,7 +306,7 @@ func LaporanPenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	loadData("")
 
 	// Table
-	colHeaders := []string{"Tanggal", "Jumlah Transaksi", "Total Penjualan"}
+	colHeaders := []string{"Tanggal", "Jumlah Transaksi", "Retur", "Total Penjualan"}
 	headerBgColor := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
 	rowBgColor := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
 
@@ -324,6 +357,9 @@ func LaporanPenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 					text.Text = item.TransactionCount
 					text.Alignment = fyne.TextAlignCenter
 				case 2:
+					text.Text = item.ReturAmount
+					text.Alignment = fyne.TextAlignTrailing
+				case 3:
 					text.Text = item.TotalAmount
 					text.Alignment = fyne.TextAlignTrailing
 				}
@@ -331,9 +367,10 @@ func LaporanPenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 		},
 	)
 
-	table.SetColumnWidth(0, 250)
-	table.SetColumnWidth(1, 250)
-	table.SetColumnWidth(2, 440)
+	table.SetColumnWidth(0, 220)
+	table.SetColumnWidth(1, 200)
+	table.SetColumnWidth(2, 240)
+	table.SetColumnWidth(3, 280)
 
 	// Focus helpers
 	var focusWrapper *focusableTable
@@ -55,10 +55,18 @@ func LoginPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 			return
 		}
 
+		perms, err := s.UserRepo.GetPermissions(user.Role)
+		if err != nil {
+			statusLabel.SetText("Error: " + err.Error())
+			statusLabel.Show()
+			return
+		}
+
 		// Set session data
 		s.IsLoggedIn = true
 		s.Username = user.Username
 		s.User = user
+		s.Permissions = perms
 
 		// Navigate to home page
 		w.SetContent(HomePage(w, s))
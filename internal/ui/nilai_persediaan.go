@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+)
+
+// valuationMethods are the cost bases of the nilai persediaan, in display order
+var valuationMethods = []string{models.ValuationAverage, models.ValuationFIFO, models.ValuationLatestPurchase}
+
+// valuationMethodLabel names a cost basis of the nilai persediaan
+func valuationMethodLabel(method string) string {
+	switch method {
+	case models.ValuationLatestPurchase:
+		return "Harga Beli Terakhir"
+	case models.ValuationFIFO:
+		return "FIFO"
+	}
+	return "Rata-rata Bergerak"
+}
+
+// NilaiPersediaanPage values the stock of every item as of a chosen date
+func NilaiPersediaanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(LaporanMenuPage(w, s))
+	})
+
+	title := canvas.NewText("LAPORAN NILAI PERSEDIAAN", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), widget.NewLabel(""))
+
+	// ===== FILTER =====
+	// Default: akhir bulan lalu, tanggal tutup buku yang paling sering dipakai
+	now := time.Now()
+	asOfLabel := widget.NewLabel(time.Date(now.Year(), now.Month(), 0, 0, 0, 0, 0, now.Location()).Format("2006-01-02"))
+	asOfBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, asOfLabel.Text, func(selectedDate string) {
+			asOfLabel.SetText(selectedDate)
+		})
+	})
+
+	var methodLabels []string
+	for _, m := range valuationMethods {
+		methodLabels = append(methodLabels, valuationMethodLabel(m))
+	}
+	methodSelect := widget.NewSelect(methodLabels, nil)
+	// Default: metode HPP yang dipakai aplikasi
+	methodSelect.SetSelectedIndex(0)
+	for i, m := range valuationMethods {
+		if m == s.Costing.Method() {
+			methodSelect.SetSelectedIndex(i)
+		}
+	}
+
+	var valuation *models.InventoryValuation
+
+	// ===== TABLE =====
+	colHeaders := []string{"Kode", "Nama Barang", "Qty", "Harga Pokok", "Nilai"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	totalBg := color.NRGBA{R: 210, G: 220, B: 240, A: 255}
+
+	// The last row is the grand total
+	table := widget.NewTable(
+		func() (int, int) {
+			if valuation == nil {
+				return 1, len(colHeaders)
+			}
+			return len(valuation.Rows) + 2, len(colHeaders)
+		},
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			text.Color = color.Black
+			text.TextSize = 13
+			text.TextStyle = fyne.TextStyle{}
+			text.Text = ""
+
+			if valuation == nil {
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 >= len(valuation.Rows) {
+				bg.FillColor = totalBg
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				switch id.Col {
+				case 1:
+					text.Text = "TOTAL"
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = fmt.Sprintf("%.0f", valuation.TotalQty)
+					text.Alignment = fyne.TextAlignTrailing
+				case 4:
+					text.Text = FormatCurrency(valuation.TotalValue)
+					text.Alignment = fyne.TextAlignTrailing
+				}
+				text.Refresh()
+				return
+			}
+
+			bg.FillColor = rowBg
+			row := valuation.Rows[id.Row-1]
+			if row.Qty < 0 {
+				text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+			}
+			switch id.Col {
+			case 0:
+				text.Text = row.Code
+				text.Alignment = fyne.TextAlignCenter
+			case 1:
+				text.Text = row.Name
+				text.Alignment = fyne.TextAlignLeading
+			case 2:
+				text.Text = fmt.Sprintf("%.0f", row.Qty)
+				text.Alignment = fyne.TextAlignTrailing
+			case 3:
+				text.Text = FormatCurrency(row.UnitCost)
+				text.Alignment = fyne.TextAlignTrailing
+			case 4:
+				text.Text = FormatCurrency(row.Value)
+				text.Alignment = fyne.TextAlignTrailing
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 120)
+	table.SetColumnWidth(1, 340)
+	table.SetColumnWidth(2, 100)
+	table.SetColumnWidth(3, 170)
+	table.SetColumnWidth(4, 200)
+
+	showBtn := widget.NewButtonWithIcon("Tampilkan", theme.SearchIcon(), func() {
+		asOf, err := time.Parse("2006-01-02", asOfLabel.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+		index := methodSelect.SelectedIndex()
+		if index < 0 {
+			index = 0
+		}
+
+		valuation, err = s.MutationRepo.GetValuation(asOf, valuationMethods[index])
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat nilai persediaan: %v", err), w)
+			return
+		}
+		table.Refresh()
+		table.ScrollToTop()
+	})
+	showBtn.Importance = widget.HighImportance
+
+	printBtn := widget.NewButtonWithIcon("Cetak PDF", theme.DocumentPrintIcon(), func() {
+		if valuation == nil {
+			dialog.ShowInformation("Info", "Tampilkan laporan terlebih dahulu!", w)
+			return
+		}
+		if err := PrintInventoryValuation(valuation); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal mencetak nilai persediaan: %v", err), w)
+		}
+	})
+
+	csvBtn := widget.NewButtonWithIcon("Export CSV", theme.DocumentSaveIcon(), func() {
+		if valuation == nil {
+			dialog.ShowInformation("Info", "Tampilkan laporan terlebih dahulu!", w)
+			return
+		}
+		if err := ExportInventoryValuationCSV(valuation); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal export nilai persediaan: %v", err), w)
+		}
+	})
+
+	filterForm := container.NewHBox(
+		widget.NewLabel("Per Tanggal"), asOfLabel, asOfBtn,
+		widget.NewLabel("Dasar Harga"), methodSelect,
+		showBtn, printBtn, csvBtn,
+	)
+
+	filterBg := canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	filterBg.CornerRadius = 6
+	filterPanel := container.NewMax(filterBg, container.NewPadded(filterForm))
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 440), table))
+
+	footer := canvas.NewText("Qty dihitung ulang dari mutasi stok sampai tanggal yang dipilih", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(
+		container.NewVBox(header, filterPanel),
+		footer,
+		nil,
+		nil,
+		tableWrapper,
+	)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	// Reset keyboard handler left behind by the previous page
+	w.Canvas().SetOnTypedKey(nil)
+
+	return container.NewMax(bg, centeredPanel)
+}
@@ -0,0 +1,668 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+type OpnameHeaderUI struct {
+	ID       uuid.UUID
+	Tanggal  string
+	NoOpname string
+	Catatan  string
+	Status   string
+}
+
+func showAddOpnameDialog(w fyne.Window, s *state.Session, onClose func()) {
+	tglOpname := widget.NewLabel(time.Now().Format("2006-01-02"))
+	tglOpname.TextStyle = fyne.TextStyle{Bold: true}
+
+	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, tglOpname.Text, func(selectedDate string) {
+			tglOpname.SetText(selectedDate)
+		})
+	})
+	calendarBtn.Importance = widget.LowImportance
+
+	noOpname := widget.NewEntry()
+	catatan := widget.NewEntry()
+	catatan.SetPlaceHolder("Opsional")
+
+	noOpname.OnSubmitted = func(string) { w.Canvas().Focus(catatan) }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Tgl. Opname", container.NewBorder(nil, nil, nil, calendarBtn, tglOpname)),
+		widget.NewFormItem("No. Opname", noOpname),
+		widget.NewFormItem("Catatan", catatan),
+	)
+
+	info := widget.NewLabel("Qty sistem seluruh barang akan di-snapshot saat sesi dibuat.")
+	info.Wrapping = fyne.TextWrapWord
+
+	var d dialog.Dialog
+
+	submitBtn := widget.NewButton("Buat Sesi", func() {
+		if noOpname.Text == "" {
+			dialog.ShowInformation("Error", "No. Opname harus diisi!", w)
+			return
+		}
+
+		existing, _ := s.OpnameRepo.GetByOpnameNum(noOpname.Text)
+		if existing != nil {
+			dialog.ShowInformation("Error", "No. Opname sudah terdaftar, silakan gunakan nomor lain!", w)
+			return
+		}
+
+		opnameDate, err := time.Parse("2006-01-02", tglOpname.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+
+		header := &models.OpnameHeader{
+			OpnameNum:  noOpname.Text,
+			OpnameDate: opnameDate,
+			Notes:      catatan.Text,
+			CreatedBy:  &s.User.ID,
+		}
+
+		if err := s.OpnameRepo.Create(header); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal membuat sesi opname: %v", err), w)
+			return
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", "Sesi opname berhasil dibuat!", w)
+		if onClose != nil {
+			onClose()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	catatan.OnSubmitted = func(string) { submitBtn.OnTapped() }
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, container.NewVBox(form, info))
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom("Sesi Opname Baru", "", dialogContent, w)
+	d.Resize(fyne.NewSize(450, 300))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			w.Canvas().Focus(noOpname)
+		})
+	})
+}
+
+// showOpnameCountDialog menampilkan lembar hitung opname. Pada sesi DRAFT user dapat
+// mengisi qty fisik per barang, meninjau selisih, lalu memposting sesi.
+func showOpnameCountDialog(w fyne.Window, s *state.Session, opnameID uuid.UUID, onClose func()) {
+	opname, err := s.OpnameRepo.GetByID(opnameID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+		if onClose != nil {
+			onClose()
+		}
+		return
+	}
+
+	isDraft := opname.Header.Status == "DRAFT"
+
+	var rows []models.OpnameDetail
+	var selectedRow int = -1
+	var onlyVariance bool
+	var keyword string
+
+	summaryLabel := canvas.NewText("", color.Black)
+	summaryLabel.TextStyle = fyne.TextStyle{Bold: true}
+	summaryLabel.Alignment = fyne.TextAlignTrailing
+
+	applyFilter := func() {
+		rows = nil
+		var varianceCount int
+		for _, d := range opname.Details {
+			if d.DiffQty() != 0 {
+				varianceCount++
+			}
+			if onlyVariance && d.DiffQty() == 0 {
+				continue
+			}
+			if keyword != "" && !containsCI(d.ItemCode, keyword) && !containsCI(d.ItemName, keyword) {
+				continue
+			}
+			rows = append(rows, d)
+		}
+		summaryLabel.Text = fmt.Sprintf("Barang selisih: %d dari %d", varianceCount, len(opname.Details))
+		summaryLabel.Refresh()
+	}
+
+	applyFilter()
+
+	colHeaders := []string{"Kode Barang", "Nama Barang", "Qty Sistem", "Qty Fisik", "Selisih"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	selectedBg := color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+
+	detailTable := widget.NewTable(
+		func() (int, int) { return len(rows) + 1, len(colHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = selectedBg
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+
+			if id.Row-1 < len(rows) {
+				row := rows[id.Row-1]
+				diff := row.DiffQty()
+				if diff != 0 && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = row.ItemCode
+					text.Alignment = fyne.TextAlignLeading
+				case 1:
+					text.Text = row.ItemName
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = fmt.Sprintf("%.0f", row.SystemQty)
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = fmt.Sprintf("%.0f", row.CountedQty)
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = fmt.Sprintf("%+.0f", diff)
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	detailTable.SetColumnWidth(0, 120)
+	detailTable.SetColumnWidth(1, 280)
+	detailTable.SetColumnWidth(2, 100)
+	detailTable.SetColumnWidth(3, 100)
+	detailTable.SetColumnWidth(4, 100)
+
+	// Input qty fisik untuk baris terpilih
+	selectedLabel := widget.NewLabel("Pilih barang pada tabel")
+	countedEntry := widget.NewEntry()
+	countedEntry.SetPlaceHolder("Qty Fisik")
+
+	saveCountBtn := widget.NewButtonWithIcon("Simpan", theme.DocumentSaveIcon(), func() {
+		if selectedRow < 0 || selectedRow >= len(rows) {
+			dialog.ShowInformation("Info", "Pilih barang terlebih dahulu!", w)
+			return
+		}
+
+		countedVal, err := strconv.ParseFloat(countedEntry.Text, 64)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Qty fisik harus berupa angka!"), w)
+			return
+		}
+
+		row := rows[selectedRow]
+		if err := s.OpnameRepo.UpdateCount(row.ID, countedVal, s.User.ID); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal menyimpan qty fisik: %v", err), w)
+			return
+		}
+
+		for i := range opname.Details {
+			if opname.Details[i].ID == row.ID {
+				opname.Details[i].CountedQty = countedVal
+				break
+			}
+		}
+
+		selectedRow = -1
+		selectedLabel.SetText("Pilih barang pada tabel")
+		countedEntry.SetText("")
+		applyFilter()
+		detailTable.Refresh()
+	})
+	saveCountBtn.Importance = widget.HighImportance
+
+	countedEntry.OnSubmitted = func(string) { saveCountBtn.OnTapped() }
+
+	detailTable.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 && id.Row-1 < len(rows) {
+			selectedRow = id.Row - 1
+			row := rows[selectedRow]
+			selectedLabel.SetText(fmt.Sprintf("%s - %s (sistem: %.0f)", row.ItemCode, row.ItemName, row.SystemQty))
+			countedEntry.SetText(fmt.Sprintf("%.0f", row.CountedQty))
+			detailTable.Refresh()
+			if isDraft {
+				w.Canvas().Focus(countedEntry)
+			}
+		}
+	}
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Cari kode / nama barang...")
+	search.OnChanged = func(value string) {
+		keyword = value
+		selectedRow = -1
+		applyFilter()
+		detailTable.Refresh()
+	}
+
+	varianceCheck := widget.NewCheck("Hanya tampilkan selisih", func(checked bool) {
+		onlyVariance = checked
+		selectedRow = -1
+		applyFilter()
+		detailTable.Refresh()
+	})
+
+	headerInfo := widget.NewForm(
+		widget.NewFormItem("Tgl. Opname", widget.NewLabel(opname.Header.OpnameDate.Format("2006-01-02"))),
+		widget.NewFormItem("No. Opname", widget.NewLabel(opname.Header.OpnameNum)),
+		widget.NewFormItem("Status", widget.NewLabel(opname.Header.Status)),
+	)
+
+	filterBar := container.NewBorder(nil, nil, nil, varianceCheck, search)
+
+	topContent := container.NewVBox(
+		container.NewCenter(widget.NewLabelWithStyle("STOCK OPNAME", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})),
+		widget.NewSeparator(),
+		headerInfo,
+		widget.NewSeparator(),
+		filterBar,
+	)
+
+	if isDraft {
+		countForm := container.NewBorder(nil, nil, nil,
+			container.NewHBox(container.NewGridWrap(fyne.NewSize(120, 36), countedEntry), saveCountBtn),
+			selectedLabel,
+		)
+		topContent.Add(countForm)
+	}
+
+	var d dialog.Dialog
+
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+
+	var buttons *fyne.Container
+	if isDraft {
+		postBtn := widget.NewButton("Posting Opname", func() {
+			if !s.Can(models.PermPostOpname) {
+				showAccessDenied(w)
+				return
+			}
+			dialog.ShowConfirm("Posting Opname",
+				"Selisih qty fisik akan diterapkan ke stok barang dan sesi tidak dapat diubah lagi.\n\nLanjutkan posting?",
+				func(b bool) {
+					if !b {
+						return
+					}
+					if err := s.OpnameRepo.Post(opname.Header.ID, s.User.ID); err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					d.Hide()
+					dialog.ShowInformation("Sukses", "Opname berhasil diposting!", w)
+					if onClose != nil {
+						onClose()
+					}
+				}, w)
+		})
+		postBtn.Importance = widget.HighImportance
+		closeBtn.Importance = widget.DangerImportance
+		buttons = container.NewGridWithColumns(2, closeBtn, postBtn)
+	} else {
+		closeBtn.Importance = widget.HighImportance
+		buttons = container.NewGridWithColumns(1, closeBtn)
+	}
+
+	tableSection := container.NewBorder(
+		nil,
+		container.NewVBox(
+			widget.NewSeparator(),
+			container.NewHBox(layout.NewSpacer(), summaryLabel),
+		),
+		nil,
+		nil,
+		func() fyne.CanvasObject {
+			scroll := container.NewScroll(detailTable)
+			scroll.SetMinSize(fyne.NewSize(0, 250))
+			return scroll
+		}(),
+	)
+
+	content := container.NewBorder(topContent, buttons, nil, nil, tableSection)
+
+	d = dialog.NewCustom("", "", container.NewPadded(content), w)
+	d.Resize(fyne.NewSize(800, 650))
+	d.Show()
+}
+
+func OpnamePage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("MENU STOCK OPNAME", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search No. Opname or Catatan...")
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), container.NewMax(search))
+
+	headers := []string{"Tgl. Opname", "No. Opname", "Catatan", "Status"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []OpnameHeaderUI
+	var selectedRow int = -1
+
+	loadData := func(keyword string) {
+		var headers []models.OpnameHeader
+		var err error
+
+		if keyword == "" {
+			headers, err = s.OpnameRepo.GetAll()
+		} else {
+			headers, err = s.OpnameRepo.Search(keyword)
+		}
+
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+
+		data = nil
+		for _, h := range headers {
+			data = append(data, OpnameHeaderUI{
+				ID:       h.ID,
+				Tanggal:  h.OpnameDate.Format("2006-01-02"),
+				NoOpname: h.OpnameNum,
+				Catatan:  h.Notes,
+				Status:   h.Status,
+			})
+		}
+	}
+
+	loadData("")
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				item := data[id.Row-1]
+
+				if item.Status == "VOID" && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = item.Tanggal
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = item.NoOpname
+					text.Alignment = fyne.TextAlignCenter
+				case 2:
+					text.Text = item.Catatan
+					text.Alignment = fyne.TextAlignLeading
+				case 3:
+					text.Text = item.Status
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 150)
+	table.SetColumnWidth(1, 220)
+	table.SetColumnWidth(2, 420)
+	table.SetColumnWidth(3, 150)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	var refreshTable func()
+	refreshTable = func() {
+		loadData(search.Text)
+		table.Refresh()
+		safeFocus()
+	}
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		// Sesi opname baru
+		case fyne.KeyInsert:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showAddOpnameDialog(w, s, func() { isDialogOpen = false; refreshTable() })
+
+		// Buka lembar hitung
+		case fyne.KeyV, fyne.KeyReturn:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showOpnameCountDialog(w, s, data[selectedRow].ID, func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih sesi opname terlebih dahulu!", w)
+			}
+
+		case fyne.KeyDelete:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				selectedID := data[selectedRow].ID
+				selectedStatus := data[selectedRow].Status
+
+				if selectedStatus == "VOID" {
+					dialog.ShowInformation("Info", "Opname ini sudah berstatus VOID!", w)
+					return
+				}
+
+				if !s.Can(models.PermVoid) {
+					showAccessDenied(w)
+					return
+				}
+
+				message := "Apakah Anda yakin ingin melakukan VOID pada sesi opname ini?"
+				if selectedStatus == "POSTED" {
+					message += "\n\nSelisih stok yang sudah diposting akan dikembalikan ke qty sebelum opname."
+				}
+
+				dialog.ShowConfirm("Void Opname", message,
+					func(b bool) {
+						if b {
+							err := s.OpnameRepo.Void(selectedID, s.User.ID)
+							if err != nil {
+								dialog.ShowError(err, w)
+							} else {
+								dialog.ShowInformation("Sukses", "Opname berhasil di-Void!", w)
+								refreshTable()
+							}
+						}
+					}, w)
+			} else {
+				dialog.ShowInformation("Info", "Pilih sesi opname terlebih dahulu sebelum di-Void!", w)
+			}
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 480), focusWrapper))
+
+	footer := canvas.NewText("[Insert] Sesi Baru  [V] Lembar Hitung / Posting  [Del] Void", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, footer, nil, nil, tableWrapper)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	return container.NewMax(bg, centeredPanel)
+}
@@ -0,0 +1,425 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// paymentTermLabel is the display name of a termin in days
+func paymentTermLabel(days int) string {
+	if days == 0 {
+		return "Tunai"
+	}
+	return fmt.Sprintf("%d Hari", days)
+}
+
+// selectedPaymentTerm returns the termin in days chosen in a select built from models.PaymentTerms
+func selectedPaymentTerm(sel *widget.Select) int {
+	if i := sel.SelectedIndex(); i >= 0 && i < len(models.PaymentTerms) {
+		return models.PaymentTerms[i]
+	}
+	return 0
+}
+
+// newPaymentTermSelect returns a select with every models.PaymentTerms choice
+func newPaymentTermSelect(onChanged func(string)) *widget.Select {
+	options := make([]string, len(models.PaymentTerms))
+	for i, t := range models.PaymentTerms {
+		options[i] = paymentTermLabel(t)
+	}
+	return widget.NewSelect(options, onChanged)
+}
+
+// paymentInput is what the payment dialog collects
+type paymentInput struct {
+	Date      time.Time
+	Amount    float64
+	Method    string
+	Reference string
+	Note      string
+}
+
+// showPaymentDialog records a (partial) payment of one nota. info describes the
+// nota, balance prefills the amount and save stores the payment.
+func showPaymentDialog(w fyne.Window, title string, info []*widget.FormItem, balance float64, save func(paymentInput) error, onClose func()) {
+	tglBayar := widget.NewLabel(time.Now().Format("2006-01-02"))
+	tglBayar.TextStyle = fyne.TextStyle{Bold: true}
+	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, tglBayar.Text, func(selectedDate string) {
+			tglBayar.SetText(selectedDate)
+		})
+	})
+	calendarBtn.Importance = widget.LowImportance
+
+	jumlah := widget.NewEntry()
+	jumlah.SetText(FormatCurrency(balance))
+	metode := widget.NewSelect(models.PaymentMethods, nil)
+	metode.SetSelected(models.PaymentMethods[0])
+	referensi := widget.NewEntry()
+	referensi.SetPlaceHolder("No. transfer / giro")
+	catatan := widget.NewEntry()
+
+	form := widget.NewForm(info...)
+	form.Append("Sisa", widget.NewLabelWithStyle(FormatCurrency(balance), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	form.Append("Tgl. Bayar", container.NewBorder(nil, nil, nil, calendarBtn, tglBayar))
+	form.Append("Jumlah Bayar", jumlah)
+	form.Append("Metode", metode)
+	form.Append("Referensi", referensi)
+	form.Append("Catatan", catatan)
+
+	var d dialog.Dialog
+
+	submitBtn := widget.NewButton("Simpan", func() {
+		amount, err := ParseCurrencyString(jumlah.Text)
+		if err != nil || amount <= 0 {
+			dialog.ShowInformation("Error", "Jumlah bayar harus berupa angka lebih dari 0!", w)
+			return
+		}
+		paymentDate, err := time.Parse("2006-01-02", tglBayar.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+
+		err = save(paymentInput{
+			Date:      paymentDate,
+			Amount:    amount,
+			Method:    metode.Selected,
+			Reference: strings.TrimSpace(referensi.Text),
+			Note:      strings.TrimSpace(catatan.Text),
+		})
+		if err != nil {
+			showSaveError(w, err)
+			return
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", "Pembayaran berhasil disimpan!", w)
+		if onClose != nil {
+			onClose()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	jumlah.OnSubmitted = func(string) { w.Canvas().Focus(referensi) }
+	referensi.OnSubmitted = func(string) { w.Canvas().Focus(catatan) }
+	catatan.OnSubmitted = func(string) { submitBtn.OnTapped() }
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom(title, "", dialogContent, w)
+	d.Resize(fyne.NewSize(480, 560))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			w.Canvas().Focus(jumlah)
+		})
+	})
+}
+
+// paymentRow is one line of the payment history dialog
+type paymentRow struct {
+	ID        uuid.UUID
+	Date      time.Time
+	Amount    float64
+	Method    string
+	Reference string
+	Note      string
+	Status    string
+}
+
+// showPaymentsDialog lists the payments of a nota and lets a user with void
+// permission cancel one through voidPayment
+func showPaymentsDialog(w fyne.Window, s *state.Session, summary string, payments []paymentRow, voidPayment func(uuid.UUID) error, onClose func()) {
+	colHeaders := []string{"Tanggal", "Jumlah", "Metode", "Referensi", "Catatan", "Status"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	selectedRow := -1
+
+	table := widget.NewTable(
+		func() (int, int) { return len(payments) + 1, len(colHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+
+			if id.Row-1 < len(payments) {
+				p := payments[id.Row-1]
+				if p.Status == "VOID" && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = p.Date.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = FormatCurrency(p.Amount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 2:
+					text.Text = p.Method
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = p.Reference
+					text.Alignment = fyne.TextAlignLeading
+				case 4:
+					text.Text = p.Note
+					text.Alignment = fyne.TextAlignLeading
+				case 5:
+					text.Text = p.Status
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 110)
+	table.SetColumnWidth(1, 130)
+	table.SetColumnWidth(2, 100)
+	table.SetColumnWidth(3, 140)
+	table.SetColumnWidth(4, 200)
+	table.SetColumnWidth(5, 80)
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+		}
+	}
+
+	var d dialog.Dialog
+
+	voidBtn := widget.NewButton("Void Pembayaran", func() {
+		if !s.Can(models.PermVoid) {
+			showAccessDenied(w)
+			return
+		}
+		if selectedRow < 0 || selectedRow >= len(payments) {
+			dialog.ShowInformation("Info", "Pilih pembayaran terlebih dahulu!", w)
+			return
+		}
+		p := payments[selectedRow]
+		if p.Status == "VOID" {
+			dialog.ShowInformation("Info", "Pembayaran ini sudah di-Void!", w)
+			return
+		}
+		dialog.ShowConfirm("Konfirmasi Void",
+			fmt.Sprintf("Void pembayaran %s tanggal %s?\n\nJumlahnya akan kembali menjadi sisa tagihan nota ini.", FormatCurrency(p.Amount), p.Date.Format("2006-01-02")),
+			func(b bool) {
+				if !b {
+					return
+				}
+				if err := voidPayment(p.ID); err != nil {
+					showSaveError(w, err)
+					return
+				}
+				d.Hide()
+				ShowSuccessToast("Success", "Pembayaran berhasil di-Void!", w)
+				if onClose != nil {
+					onClose()
+				}
+			}, w)
+	})
+	voidBtn.Importance = widget.DangerImportance
+
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	closeBtn.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		container.NewVBox(widget.NewLabel(summary), widget.NewSeparator()),
+		container.NewHBox(voidBtn, layout.NewSpacer(), closeBtn),
+		nil, nil,
+		table,
+	)
+
+	d = dialog.NewCustom("Riwayat Pembayaran", "", container.NewPadded(content), w)
+	d.Resize(fyne.NewSize(850, 450))
+	d.Show()
+}
+
+// newAgingTable shows aging rows with a grand total line
+func newAgingTable(rows []models.AgingRow, partyTitle string) *widget.Table {
+	colHeaders := append([]string{partyTitle}, models.AgingBucketLabels[:]...)
+	colHeaders = append(colHeaders, "Total")
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+	totalBg := color.NRGBA{R: 210, G: 220, B: 240, A: 255}
+
+	var grand models.AgingRow
+	for _, r := range rows {
+		for i, v := range r.Buckets {
+			grand.Buckets[i] += v
+		}
+		grand.Total += r.Total
+	}
+	grand.PartyName = "TOTAL"
+
+	table := widget.NewTable(
+		func() (int, int) { return len(rows) + 2, len(colHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = colHeaders[id.Col]
+				text.Color = color.White
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			row := grand
+			bg.FillColor = totalBg
+			text.TextStyle = fyne.TextStyle{Bold: true}
+			if id.Row-1 < len(rows) {
+				row = rows[id.Row-1]
+				bg.FillColor = rowBg
+				text.TextStyle = fyne.TextStyle{}
+			}
+			text.Color = color.Black
+
+			switch {
+			case id.Col == 0:
+				text.Text = row.PartyName
+				text.Alignment = fyne.TextAlignLeading
+			case id.Col == len(colHeaders)-1:
+				text.Text = FormatCurrency(row.Total)
+				text.Alignment = fyne.TextAlignTrailing
+			default:
+				text.Text = FormatCurrency(row.Buckets[id.Col-1])
+				text.Alignment = fyne.TextAlignTrailing
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 220)
+	for i := 1; i < len(colHeaders); i++ {
+		table.SetColumnWidth(i, 125)
+	}
+	return table
+}
+
+// showAgingDialog shows the open balances per supplier or customer by age as
+// of a chosen date; load reads the rows for that date
+func showAgingDialog(w fyne.Window, title, partyTitle string, load func(time.Time) ([]models.AgingRow, error), onClose func()) {
+	asOf := widget.NewLabel(time.Now().Format("2006-01-02"))
+	asOf.TextStyle = fyne.TextStyle{Bold: true}
+
+	tableHolder := container.NewMax()
+	reload := func() {
+		date, err := time.Parse("2006-01-02", asOf.Text)
+		if err != nil {
+			return
+		}
+		rows, err := load(date)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+		tableHolder.Objects = []fyne.CanvasObject{newAgingTable(rows, partyTitle)}
+		tableHolder.Refresh()
+	}
+	reload()
+
+	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, asOf.Text, func(selectedDate string) {
+			asOf.SetText(selectedDate)
+			reload()
+		})
+	})
+
+	var d dialog.Dialog
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	closeBtn.Importance = widget.HighImportance
+
+	content := container.NewBorder(
+		container.NewVBox(
+			container.NewCenter(widget.NewLabelWithStyle(title, fyne.TextAlignCenter, fyne.TextStyle{Bold: true})),
+			container.NewHBox(widget.NewLabel("Per Tanggal:"), asOf, calendarBtn),
+			widget.NewSeparator(),
+		),
+		container.NewCenter(closeBtn),
+		nil, nil,
+		tableHolder,
+	)
+
+	d = dialog.NewCustom("", "", container.NewPadded(content), w)
+	d.Resize(fyne.NewSize(1000, 500))
+	d.Show()
+}
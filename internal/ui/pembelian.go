@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"strconv"
@@ -16,6 +17,8 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 
 	"github.com/google/uuid"
@@ -44,10 +47,25 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	tglNota := widget.NewLabel(time.Now().Format("2006-01-02"))
 	tglNota.TextStyle = fyne.TextStyle{Bold: true}
 
+	// Jatuh tempo dihitung dari tanggal nota dan termin
+	dueDateLabel := widget.NewLabel("")
+	var termin *widget.Select
+	updateDueDate := func() {
+		purchaseDate, err := time.Parse("2006-01-02", tglNota.Text)
+		if err != nil || termin == nil {
+			return
+		}
+		dueDateLabel.SetText(purchaseDate.AddDate(0, 0, selectedPaymentTerm(termin)).Format("2006-01-02"))
+	}
+
+	termin = newPaymentTermSelect(func(string) { updateDueDate() })
+	termin.SetSelected(paymentTermLabel(0))
+
 	// Calendar button with calendar icon only
 	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
 		ShowDatePickerDialog(w, tglNota.Text, func(selectedDate string) {
 			tglNota.SetText(selectedDate)
+			updateDueDate()
 		})
 	})
 	calendarBtn.Importance = widget.LowImportance
@@ -59,7 +77,8 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	var vendorWidget fyne.CanvasObject
 
 	noNota := widget.NewEntry()
-	vendor := widget.NewEntry()
+	vendorPicker := newSupplierPicker(s)
+	vendor := vendorPicker.Entry
 
 	noNotaLabel := widget.NewLabel("")
 	noNotaLabel.TextStyle = fyne.TextStyle{Bold: false}
@@ -279,8 +298,10 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 		noNotaWidget = noNotaLabel
 		vendorWidget = vendorLabel
 		calendarBtn.Disable()
+		termin.Disable()
 	} else {
-		// New or Edit mode: use Entry fields
+		// New or Edit mode: No. Nota diisi nomor faktur dari supplier
+		noNota.SetPlaceHolder("No. faktur supplier")
 		noNotaWidget = noNota
 		vendorWidget = vendor
 	}
@@ -289,15 +310,17 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 		widget.NewFormItem("Tgl. Nota", tglNotaContainer),
 		widget.NewFormItem("No. Nota", noNotaWidget),
 		widget.NewFormItem("Vendor", vendorWidget),
+		widget.NewFormItem("Termin", container.NewGridWithColumns(2, termin, dueDateLabel)),
 	)
 	headerFormSeparator := widget.NewSeparator()
 
 	if existingData != nil {
 		formattedDate := existingData.Header.PurchaseDate.Format("2006-01-02")
 		tglNota.SetText(formattedDate)
+		termin.SetSelected(paymentTermLabel(existingData.Header.PaymentTermDays))
 		if isEditMode {
 			noNota.SetText(existingData.Header.PurchaseInvoiceNum)
-			vendor.SetText(existingData.Header.SupplierName)
+			vendorPicker.Select(s, existingData.Header.SupplierID, existingData.Header.SupplierName)
 		}
 
 		displayItems := LoadPurchaseDisplayItems(s, existingData.Details)
@@ -573,27 +596,25 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	}
 
 	var d dialog.Dialog
-	submitBtn := widget.NewButton("Submit", func() {
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Submit", func() {
 		if tglNota.Text == "" || noNota.Text == "" || vendor.Text == "" {
 			dialog.ShowInformation("Error", "Header data harus diisi!", w)
 			return
 		}
 
-		if !isEditMode {
-			// Validasi No Nota duplikat
-			existing, _ := s.PurchaseRepo.GetByInvoiceNum(noNota.Text)
-			if existing != nil {
-				dialog.ShowInformation("Error", "No. Nota sudah terdaftar, silakan gunakan nomor lain!", w)
-				return
-			}
-		}
-
 		// Validate item counts (prevent empty nota)
 		if len(items) == 0 {
 			dialog.ShowInformation("Error", "Minimal 1 item harus ditambahkan!", w)
 			return
 		}
 
+		supplier := vendorPicker.Selected()
+		if supplier == nil {
+			dialog.ShowInformation("Error", "Supplier tidak terdaftar! Pilih dari daftar atau tambahkan di Master Supplier.", w)
+			return
+		}
+
 		purchaseDate, _ := time.Parse("2006-01-02", tglNota.Text)
 		grandTotal := recalculateTotal()
 
@@ -601,8 +622,10 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 			Header: models.PurchaseHeader{
 				PurchaseInvoiceNum: noNota.Text,
 				PurchaseDate:       purchaseDate,
-				SupplierName:       vendor.Text,
+				SupplierName:       supplier.Name,
+				SupplierID:         &supplier.ID,
 				TotalAmount:        grandTotal,
+				PaymentTermDays:    selectedPaymentTerm(termin),
 			},
 			Details: make([]models.PurchaseDetail, len(items)),
 		}
@@ -611,6 +634,7 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 			purchase.Header.ID = existingData.Header.ID
 			purchase.Header.CreatedAt = existingData.Header.CreatedAt
 			purchase.Header.CreatedBy = existingData.Header.CreatedBy
+			purchase.Header.Version = existingData.Header.Version
 			now := time.Now()
 			purchase.Header.UpdatedAt = &now
 			purchase.Header.UpdatedBy = &s.User.ID
@@ -637,8 +661,39 @@ func showPembelianDialog(w fyne.Window, s *state.Session, refreshCallback func()
 			err = s.PurchaseRepo.Create(purchase)
 		}
 
+		if errors.Is(err, repository.ErrDocumentVoided) {
+			// Nota yang sudah di-void tidak bisa ditimpa, hanya bisa dilihat ulang
+			showConflictDialog(w, func() {
+				d.Hide()
+				if refreshCallback != nil {
+					refreshCallback()
+				}
+				showViewPembelianDialog(w, s, existingData.Header.ID)
+			}, nil)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			showConflictDialog(w, func() {
+				latest, err := s.PurchaseRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				d.Hide()
+				showPembelianDialog(w, s, refreshCallback, latest, true)
+			}, func() {
+				latest, err := s.PurchaseRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				existingData.Header.Version = latest.Header.Version
+				submitBtn.OnTapped()
+			})
+			return
+		}
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Gagal menyimpan data: %v", err), w)
+			showSaveError(w, err)
 			return
 		}
 
@@ -768,11 +823,13 @@ func showViewPembelianDialog(w fyne.Window, s *state.Session, headerID uuid.UUID
 	tglNota := widget.NewLabel(purchase.Header.PurchaseDate.Format("2006-01-02"))
 	noNota := widget.NewLabel(purchase.Header.PurchaseInvoiceNum)
 	vendor := widget.NewLabel(purchase.Header.SupplierName)
+	jatuhTempo := widget.NewLabel(paymentTermLabel(purchase.Header.PaymentTermDays) + " - " + purchase.Header.DueDate.Format("2006-01-02"))
 
 	headerInfo := container.NewVBox(
 		container.NewGridWithColumns(2, canvas.NewText("Tgl. Nota", color.White), tglNota),
 		container.NewGridWithColumns(2, canvas.NewText("No. Nota", color.White), noNota),
 		container.NewGridWithColumns(2, canvas.NewText("Vendor", color.White), vendor),
+		container.NewGridWithColumns(2, canvas.NewText("Jatuh Tempo", color.White), jatuhTempo),
 	)
 
 	displayItems := LoadPurchaseDisplayItems(s, purchase.Details)
@@ -882,7 +939,10 @@ func PembelianPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
 	bg.FillMode = canvas.ImageFillStretch
 
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
 	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
 		w.SetContent(HomePage(w, s))
 	})
 
@@ -1127,12 +1187,21 @@ func PembelianPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 					return
 				}
 
+				if !s.Can(models.PermVoid) {
+					showAccessDenied(w)
+					return
+				}
+
 				dialog.ShowConfirm("Void Nota Pembelian",
 					"Apakah Anda yakin ingin melakukan VOID pada nota ini?\n\nAksi ini akan memutarbalikan stok barang yang sudah dibeli dan mengubah status nota menjadi VOID.",
 					func(b bool) {
 						if b {
 							err := s.PurchaseRepo.Void(selectedID, s.User.ID)
-							if err != nil {
+							if errors.Is(err, repository.ErrHasPayments) {
+								dialog.ShowInformation("Info", "Nota ini sudah memiliki pembayaran. Void pembayarannya terlebih dahulu di menu Hutang Supplier.", w)
+							} else if errors.Is(err, repository.ErrHasPurchaseReturs) {
+								dialog.ShowInformation("Info", "Nota ini sudah memiliki retur pembelian. Void returnya terlebih dahulu di menu Retur Pembelian.", w)
+							} else if err != nil {
 								dialog.ShowError(err, w)
 							} else {
 								dialog.ShowInformation("Sukses", "Nota berhasil di-Void!", w)
@@ -1233,5 +1302,16 @@ func PembelianPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 
 	time.AfterFunc(150*time.Millisecond, safeFocus)
 
-	return container.NewMax(bg, centeredPanel)
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicPurchase)
+
+	return page
 }
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"strconv"
@@ -16,6 +17,9 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 
 	"github.com/google/uuid"
@@ -49,10 +53,21 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	tglNota := widget.NewLabel(time.Now().Format("2006-01-02"))
 	tglNota.TextStyle = fyne.TextStyle{Bold: true}
 
+	// Preview nomor nota otomatis ikut berubah saat tanggal nota diganti
+	var onDateChanged func()
+	// Jatuh tempo penjualan kredit ikut berubah saat tanggal nota diganti
+	var onPaymentChanged func()
+
 	// Calendar button with calendar icon only
 	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
 		ShowDatePickerDialog(w, tglNota.Text, func(selectedDate string) {
 			tglNota.SetText(selectedDate)
+			if onDateChanged != nil {
+				onDateChanged()
+			}
+			if onPaymentChanged != nil {
+				onPaymentChanged()
+			}
 		})
 	})
 	calendarBtn.Importance = widget.LowImportance
@@ -64,7 +79,48 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	var customerWidget fyne.CanvasObject
 
 	noNota := widget.NewEntry()
-	customer := widget.NewEntry()
+	customerPicker := newCustomerPicker(s)
+	customer := customerPicker.Entry
+
+	// Metode bayar; termin, jatuh tempo dan limit kredit hanya untuk penjualan kredit
+	dueDateLabel := widget.NewLabel("")
+	creditInfo := widget.NewLabel("")
+	var metode, termin *widget.Select
+	onPaymentChanged = func() {
+		if metode == nil || termin == nil {
+			return
+		}
+		if selectedSellPaymentMethod(metode) != models.SellPaymentCredit {
+			termin.Disable()
+			dueDateLabel.SetText("-")
+			creditInfo.SetText("")
+			return
+		}
+		if metode.Disabled() {
+			termin.Disable()
+		} else {
+			termin.Enable()
+		}
+		if sellDate, err := time.Parse("2006-01-02", tglNota.Text); err == nil {
+			dueDateLabel.SetText(sellDate.AddDate(0, 0, selectedPaymentTerm(termin)).Format("2006-01-02"))
+		}
+		creditInfo.SetText("")
+		if c := customerPicker.Selected(); c != nil {
+			balance, err := s.ReceivableRepo.GetCustomerBalance(c.ID)
+			if err == nil {
+				creditInfo.SetText(fmt.Sprintf("Limit %s, piutang %s", FormatCurrency(c.CreditLimit), FormatCurrency(balance)))
+			}
+		}
+	}
+	metodeOptions := make([]string, len(models.SellPaymentMethods))
+	for i, m := range models.SellPaymentMethods {
+		metodeOptions[i] = sellPaymentMethodLabel(m)
+	}
+	metode = widget.NewSelect(metodeOptions, func(string) { onPaymentChanged() })
+	termin = newPaymentTermSelect(func(string) { onPaymentChanged() })
+	termin.SetSelected(paymentTermLabel(30))
+	metode.SetSelected(sellPaymentMethodLabel(models.SellPaymentCash))
+	customerPicker.OnSelected = func(*models.Customer) { onPaymentChanged() }
 
 	noNotaLabel := widget.NewLabel("")
 	noNotaLabel.TextStyle = fyne.TextStyle{Bold: false}
@@ -248,7 +304,7 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 
 			v, err := strconv.ParseFloat(val, 64)
 			if err == nil {
-				if v > availableStock { // For Penjualan, stock must be sufficient
+				if v > availableStock && !selectedItem.AllowNegativeStock { // For Penjualan, stock must be sufficient
 					stockWarning.Text = "Stok kurang!"
 				} else if v <= 0 {
 					stockWarning.Text = "Qty invalid!"
@@ -310,8 +366,19 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 		noNotaWidget = noNotaLabel
 		customerWidget = customerLabel
 		calendarBtn.Disable()
+		metode.Disable()
+		termin.Disable()
+	} else if existingData == nil {
+		// New mode: nomor nota dibuat otomatis saat disimpan
+		onDateChanged = func() {
+			noNotaLabel.SetText(previewInvoiceNum(s, numbering.DocSell, tglNota.Text))
+		}
+		onDateChanged()
+		noNotaWidget = noNotaLabel
+		customerWidget = customer
+		customerPicker.SelectDefault()
 	} else {
-		// New or Edit mode: use Entry fields
+		// Edit mode: use Entry fields
 		noNotaWidget = noNota
 		customerWidget = customer
 	}
@@ -321,6 +388,8 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 		widget.NewFormItem("Tgl. Nota", tglNotaContainer),
 		widget.NewFormItem("No. Nota", noNotaWidget),
 		widget.NewFormItem("Customer", customerWidget),
+		widget.NewFormItem("Pembayaran", container.NewGridWithColumns(3, metode, termin, dueDateLabel)),
+		widget.NewFormItem("", creditInfo),
 	)
 	headerFormSeparator := widget.NewSeparator()
 
@@ -328,9 +397,13 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	if existingData != nil {
 		formattedDate := existingData.Header.SellDate.Format("2006-01-02")
 		tglNota.SetText(formattedDate)
+		if existingData.Header.PaymentMethod == models.SellPaymentCredit {
+			termin.SetSelected(paymentTermLabel(existingData.Header.PaymentTermDays))
+		}
+		metode.SetSelected(sellPaymentMethodLabel(existingData.Header.PaymentMethod))
 		if isEditMode {
 			noNota.SetText(existingData.Header.SellInvoiceNum)
-			customer.SetText(existingData.Header.CustomerName)
+			customerPicker.Select(s, existingData.Header.CustomerID, existingData.Header.CustomerName)
 		}
 
 		displayItems := LoadSellDisplayItems(s, existingData.Details)
@@ -410,7 +483,7 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 
 		availableStock := selectedItem.Qty - currentInCart
 
-		if qtyVal > availableStock {
+		if qtyVal > availableStock && !selectedItem.AllowNegativeStock {
 			dialog.ShowError(fmt.Errorf("Stok tidak mencukupi! Sisa stok yang bisa ditarik: %.0f", availableStock), w)
 			return
 		}
@@ -600,20 +673,18 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 	// Dialog content
 	var d dialog.Dialog
 
-	submitBtn := widget.NewButton("Submit", func() {
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Submit", func() {
 		// Validate
-		if tglNota.Text == "" || noNota.Text == "" || customer.Text == "" {
+		if tglNota.Text == "" || (isEditMode && noNota.Text == "") || customer.Text == "" {
 			dialog.ShowInformation("Error", "Header data harus diisi!", w)
 			return
 		}
 
-		if !isEditMode {
-			// Validasi No Nota duplikat (hanya untuk nota baru)
-			existing, _ := s.SellRepo.GetByInvoiceNum(noNota.Text)
-			if existing != nil {
-				dialog.ShowInformation("Error", "No. Nota sudah terdaftar, silakan gunakan nomor lain!", w)
-				return
-			}
+		customerData := customerPicker.Selected()
+		if customerData == nil {
+			dialog.ShowInformation("Error", "Customer tidak terdaftar! Pilih dari daftar atau tambahkan di Master Customer.", w)
+			return
 		}
 
 		// Validate item count
@@ -634,10 +705,13 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 		// Build sell model
 		sell := &models.SellFull{
 			Header: models.SellHeader{
-				SellInvoiceNum: noNota.Text,
-				SellDate:       sellDate,
-				CustomerName:   customer.Text,
-				TotalAmount:    grandTotal,
+				SellInvoiceNum:  noNota.Text,
+				SellDate:        sellDate,
+				CustomerName:    customerData.Name,
+				CustomerID:      &customerData.ID,
+				TotalAmount:     grandTotal,
+				PaymentMethod:   selectedSellPaymentMethod(metode),
+				PaymentTermDays: selectedPaymentTerm(termin),
 			},
 			Details: make([]models.SellDetail, len(items)),
 		}
@@ -646,6 +720,7 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 			sell.Header.ID = existingData.Header.ID
 			sell.Header.CreatedAt = existingData.Header.CreatedAt
 			sell.Header.CreatedBy = existingData.Header.CreatedBy
+			sell.Header.Version = existingData.Header.Version
 			now := time.Now()
 			sell.Header.UpdatedAt = &now
 			sell.Header.UpdatedBy = &s.User.ID
@@ -673,15 +748,50 @@ func showPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func()
 			err = s.SellRepo.Create(sell) // INSERT query
 		}
 
+		if errors.Is(err, repository.ErrDocumentVoided) {
+			// Nota yang sudah di-void tidak bisa ditimpa, hanya bisa dilihat ulang
+			showConflictDialog(w, func() {
+				d.Hide()
+				if refreshCallback != nil {
+					refreshCallback()
+				}
+				showViewPenjualanDialog(w, s, existingData.Header.ID)
+			}, nil)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			showConflictDialog(w, func() {
+				latest, err := s.SellRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				d.Hide()
+				showPenjualanDialog(w, s, refreshCallback, latest, true)
+			}, func() {
+				latest, err := s.SellRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				existingData.Header.Version = latest.Header.Version
+				submitBtn.OnTapped()
+			})
+			return
+		}
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Gagal menyimpan data: %v", err), w)
+			showSaveError(w, err)
 			return
 		}
 
 		// if date changed from original, mark blue
 		// (UI color feedback removed)
 
-		ShowSuccessToast("Success", "Data penjualan berhasil disimpan!", w)
+		if isEditMode {
+			ShowSuccessToast("Success", "Data penjualan berhasil disimpan!", w)
+		} else {
+			ShowSuccessToast("Success", "Data penjualan berhasil disimpan dengan No. Nota "+sell.Header.SellInvoiceNum, w)
+		}
 		d.Hide()
 
 		if refreshCallback != nil {
@@ -815,11 +925,13 @@ func showViewPenjualanDialog(w fyne.Window, s *state.Session, headerID uuid.UUID
 	tglNota := widget.NewLabel(sell.Header.SellDate.Format("2006-01-02"))
 	noNota := widget.NewLabel(sell.Header.SellInvoiceNum)
 	customer := widget.NewLabel(sell.Header.CustomerName)
+	pembayaran := widget.NewLabel(sellPaymentSummary(sell.Header))
 
 	headerInfo := container.NewVBox(
 		container.NewGridWithColumns(2, canvas.NewText("Tgl. Nota", color.White), tglNota),
 		container.NewGridWithColumns(2, canvas.NewText("No. Nota", color.White), noNota),
 		container.NewGridWithColumns(2, canvas.NewText("Customer", color.White), customer),
+		container.NewGridWithColumns(2, canvas.NewText("Pembayaran", color.White), pembayaran),
 	)
 
 	// Convert details to display items using helper function
@@ -951,7 +1063,10 @@ func PenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	bg.FillMode = canvas.ImageFillStretch
 
 	// Header
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
 	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
 		w.SetContent(HomePage(w, s))
 	})
 
@@ -1245,12 +1360,21 @@ func PenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 					return
 				}
 
+				if !s.Can(models.PermVoid) {
+					showAccessDenied(w)
+					return
+				}
+
 				dialog.ShowConfirm("Void Nota Penjualan",
 					"Apakah Anda yakin ingin melakukan VOID pada nota ini?\n\nAksi ini akan memutarbalikan stok barang yang sudah dijual kembali ke sistem dan mengubah status nota menjadi VOID.",
 					func(b bool) {
 						if b {
 							err := s.SellRepo.Void(selectedID, s.User.ID)
-							if err != nil {
+							if errors.Is(err, repository.ErrHasPayments) {
+								dialog.ShowInformation("Info", "Nota ini sudah memiliki pembayaran. Void pembayarannya terlebih dahulu di menu Piutang Customer.", w)
+							} else if errors.Is(err, repository.ErrHasReturns) {
+								dialog.ShowInformation("Info", "Nota ini sudah memiliki retur penjualan. Void returnya terlebih dahulu di menu Retur Penjualan.", w)
+							} else if err != nil {
 								dialog.ShowError(err, w)
 							} else {
 								dialog.ShowInformation("Sukses", "Nota berhasil di-Void!", w)
@@ -1379,8 +1503,19 @@ func PenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 		})
 	})
 
-	return container.NewMax(
+	page := container.NewMax(
 		bg,
 		centeredPanel,
 	)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicSell)
+
+	return page
 }
@@ -0,0 +1,481 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// sellPaymentMethodLabel is the display name of a models.SellPayment* value
+func sellPaymentMethodLabel(method string) string {
+	switch method {
+	case models.SellPaymentTransfer:
+		return "Transfer"
+	case models.SellPaymentCredit:
+		return "Kredit"
+	}
+	return "Tunai"
+}
+
+// selectedSellPaymentMethod returns the method chosen in a select built from models.SellPaymentMethods
+func selectedSellPaymentMethod(sel *widget.Select) string {
+	if i := sel.SelectedIndex(); i >= 0 && i < len(models.SellPaymentMethods) {
+		return models.SellPaymentMethods[i]
+	}
+	return models.SellPaymentCash
+}
+
+// sellPaymentSummary describes how a nota penjualan is paid, with the due date of a credit sale
+func sellPaymentSummary(h models.SellHeader) string {
+	if h.PaymentMethod != models.SellPaymentCredit {
+		return sellPaymentMethodLabel(h.PaymentMethod)
+	}
+	return fmt.Sprintf("Kredit %s - jatuh tempo %s", paymentTermLabel(h.PaymentTermDays), h.DueDate.Format("2006-01-02"))
+}
+
+// showSellPaymentDialog records a (partial) payment of one credit sale
+func showSellPaymentDialog(w fyne.Window, s *state.Session, invoice models.ReceivableInvoice, onClose func()) {
+	info := []*widget.FormItem{
+		widget.NewFormItem("No. Nota", widget.NewLabel(invoice.SellInvoiceNum)),
+		widget.NewFormItem("Customer", widget.NewLabel(invoice.CustomerName)),
+		widget.NewFormItem("Jatuh Tempo", widget.NewLabel(invoice.DueDate.Format("2006-01-02"))),
+		widget.NewFormItem("Total Nota", widget.NewLabel(FormatCurrency(invoice.TotalAmount))),
+		widget.NewFormItem("Sudah Dibayar", widget.NewLabel(FormatCurrency(invoice.PaidAmount))),
+		widget.NewFormItem("Retur", widget.NewLabel(FormatCurrency(invoice.ReturAmount))),
+	}
+
+	showPaymentDialog(w, "Terima Pembayaran Piutang", info, invoice.Balance, func(in paymentInput) error {
+		return s.ReceivableRepo.AddPayment(&models.SellPayment{
+			SellID:      invoice.SellID,
+			PaymentDate: in.Date,
+			Amount:      in.Amount,
+			Method:      in.Method,
+			Reference:   in.Reference,
+			Note:        in.Note,
+			CreatedBy:   &s.User.ID,
+		})
+	}, onClose)
+}
+
+// showSellPaymentsDialog lists the payments of a credit sale
+func showSellPaymentsDialog(w fyne.Window, s *state.Session, invoice models.ReceivableInvoice, onClose func()) {
+	payments, err := s.ReceivableRepo.GetPayments(invoice.SellID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+		return
+	}
+
+	rows := make([]paymentRow, len(payments))
+	for i, p := range payments {
+		rows[i] = paymentRow{ID: p.ID, Date: p.PaymentDate, Amount: p.Amount, Method: p.Method,
+			Reference: p.Reference, Note: p.Note, Status: p.Status}
+	}
+
+	summary := fmt.Sprintf("%s - %s\nTotal %s, dibayar %s, retur %s, sisa %s",
+		invoice.SellInvoiceNum, invoice.CustomerName, FormatCurrency(invoice.TotalAmount),
+		FormatCurrency(invoice.PaidAmount), FormatCurrency(invoice.ReturAmount), FormatCurrency(invoice.Balance))
+
+	showPaymentsDialog(w, s, summary, rows, func(id uuid.UUID) error {
+		return s.ReceivableRepo.VoidPayment(id, s.User.ID)
+	}, onClose)
+}
+
+// showCustomerStatementDialog picks a customer and period and prints the
+// rekening koran piutang of that customer as PDF
+func showCustomerStatementDialog(w fyne.Window, s *state.Session, customerID *uuid.UUID, customerName string, onClose func()) {
+	picker := newCustomerPicker(s)
+	if customerID != nil {
+		picker.Select(s, customerID, customerName)
+	}
+
+	now := time.Now()
+	startLabel := widget.NewLabel(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02"))
+	endLabel := widget.NewLabel(now.Format("2006-01-02"))
+
+	startBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, startLabel.Text, func(selectedDate string) {
+			startLabel.SetText(selectedDate)
+		})
+	})
+	endBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, endLabel.Text, func(selectedDate string) {
+			endLabel.SetText(selectedDate)
+		})
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Customer", picker.Entry),
+		widget.NewFormItem("Dari", container.NewBorder(nil, nil, nil, startBtn, startLabel)),
+		widget.NewFormItem("s/d", container.NewBorder(nil, nil, nil, endBtn, endLabel)),
+	)
+
+	var d dialog.Dialog
+
+	printBtn := widget.NewButtonWithIcon("Cetak PDF", theme.DocumentPrintIcon(), func() {
+		customer := picker.Selected()
+		if customer == nil {
+			dialog.ShowInformation("Info", "Pilih customer terlebih dahulu!", w)
+			return
+		}
+
+		startDate, err1 := time.Parse("2006-01-02", startLabel.Text)
+		endDate, err2 := time.Parse("2006-01-02", endLabel.Text)
+		if err1 != nil || err2 != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+		if endDate.Before(startDate) {
+			dialog.ShowInformation("Info", "Tanggal akhir tidak boleh sebelum tanggal awal!", w)
+			return
+		}
+
+		statement, err := s.ReceivableRepo.GetStatement(*customer, startDate, endDate)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat rekening koran: %v", err), w)
+			return
+		}
+		if err := PrintCustomerStatement(statement); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal mencetak rekening koran: %v", err), w)
+		}
+	})
+	printBtn.Importance = widget.HighImportance
+
+	closeBtn := widget.NewButton("Tutup", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	closeBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, closeBtn, printBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom("Rekening Koran Piutang", "", dialogContent, w)
+	d.Resize(fyne.NewSize(480, 260))
+	d.Show()
+}
+
+// PiutangPage lists the credit sales that are not fully paid
+func PiutangPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("PIUTANG CUSTOMER", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search no. nota atau customer...")
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), container.NewMax(search))
+
+	headers := []string{"No. Nota", "Customer", "Tgl. Nota", "Jatuh Tempo", "Total", "Dibayar", "Retur", "Sisa", "Telat"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []models.ReceivableInvoice
+	var selectedRow int = -1
+	var totalBalance float64
+
+	totalLabel := canvas.NewText("", color.White)
+	totalLabel.TextStyle = fyne.TextStyle{Bold: true}
+	totalLabel.Alignment = fyne.TextAlignTrailing
+
+	loadData := func(keyword string) {
+		invoices, err := s.ReceivableRepo.GetOutstanding()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+
+		data = nil
+		totalBalance = 0
+		for _, inv := range invoices {
+			if keyword != "" && !containsCI(inv.SellInvoiceNum, keyword) && !containsCI(inv.CustomerName, keyword) {
+				continue
+			}
+			data = append(data, inv)
+			totalBalance += inv.Balance
+		}
+		totalLabel.Text = "Total Sisa Piutang: " + FormatCurrency(totalBalance)
+		totalLabel.Refresh()
+	}
+
+	loadData("")
+
+	now := time.Now()
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				inv := data[id.Row-1]
+				overdue := models.DaysOverdue(inv.DueDate, now)
+				if overdue > 0 && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = inv.SellInvoiceNum
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = inv.CustomerName
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = inv.SellDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = inv.DueDate.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = FormatCurrency(inv.TotalAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = FormatCurrency(inv.PaidAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 6:
+					text.Text = FormatCurrency(inv.ReturAmount)
+					text.Alignment = fyne.TextAlignTrailing
+				case 7:
+					text.Text = FormatCurrency(inv.Balance)
+					text.Alignment = fyne.TextAlignTrailing
+				case 8:
+					text.Text = ""
+					if overdue > 0 {
+						text.Text = fmt.Sprintf("%d hari", overdue)
+					}
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 130)
+	table.SetColumnWidth(1, 170)
+	table.SetColumnWidth(2, 95)
+	table.SetColumnWidth(3, 100)
+	table.SetColumnWidth(4, 110)
+	table.SetColumnWidth(5, 110)
+	table.SetColumnWidth(6, 90)
+	table.SetColumnWidth(7, 110)
+	table.SetColumnWidth(8, 70)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable := func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+		safeFocus()
+	}
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		// Bayar nota terpilih
+		case fyne.KeyB, fyne.KeyReturn:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showSellPaymentDialog(w, s, data[selectedRow], func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Riwayat pembayaran
+		case fyne.KeyR:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showSellPaymentsDialog(w, s, data[selectedRow], func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Lihat nota penjualan
+		case fyne.KeyV:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				showViewPenjualanDialog(w, s, data[selectedRow].SellID)
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Umur piutang per customer
+		case fyne.KeyA:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showAgingDialog(w, "UMUR PIUTANG CUSTOMER", "Customer", s.ReceivableRepo.GetAging, func() { isDialogOpen = false; safeFocus() })
+
+		// Rekening koran customer
+		case fyne.KeyS:
+			lastDialogTime = time.Now()
+			var customerID *uuid.UUID
+			var customerName string
+			if selectedRow >= 0 && selectedRow < len(data) {
+				customerID, customerName = data[selectedRow].CustomerID, data[selectedRow].CustomerName
+			}
+			isDialogOpen = true
+			showCustomerStatementDialog(w, s, customerID, customerName, func() { isDialogOpen = false; safeFocus() })
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(980, 450), focusWrapper))
+
+	footer := canvas.NewText("[B] Terima Bayar  [R] Riwayat Bayar  [V] Lihat Nota  [A] Umur Piutang  [S] Rekening Koran  |  ↑↓ = Navigate", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, container.NewVBox(container.NewHBox(layout.NewSpacer(), totalLabel), footer), nil, nil, tableWrapper)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicReceivable, notify.TopicSellRetur)
+
+	return page
+}
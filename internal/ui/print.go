@@ -10,14 +10,45 @@ import (
 	"io/ioutil"
 	"time"
 
+	"fyne-app/internal/config"
 	"fyne-app/internal/models"
 
 	"github.com/go-pdf/fpdf"
 )
 
+// tempDir is the output folder for generated PDF files
+var tempDir = "temp"
+
+// storeInfo is printed at the top of every document
+var storeInfo config.StoreConfig
+
+// ConfigurePrint sets the PDF output folder and store identity from the app configuration
+func ConfigurePrint(cfg *config.AppConfig) {
+	if cfg.Paths.TempDir != "" {
+		tempDir = cfg.Paths.TempDir
+	}
+	storeInfo = cfg.Store
+}
+
+// printStoreHeader writes the configured store identity, if any, above the document title
+func printStoreHeader(pdf *fpdf.Fpdf) {
+	if storeInfo.Name == "" {
+		return
+	}
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(190, 6, storeInfo.Name, "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	if storeInfo.Address != "" {
+		pdf.CellFormat(190, 5, storeInfo.Address, "", 1, "L", false, 0, "")
+	}
+	if storeInfo.Phone != "" {
+		pdf.CellFormat(190, 5, "Telp. "+storeInfo.Phone, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+}
+
 // AutoCleanupTempFolder runs asynchronously to delete files in the temp folder older than a certain duration (e.g. 1 hour).
 func AutoCleanupTempFolder(maxAge time.Duration) {
-	tempDir := "temp"
 	files, err := ioutil.ReadDir(tempDir)
 	if err != nil {
 		return // Silently ignore if folder doesn't exist
@@ -36,6 +67,7 @@ func AutoCleanupTempFolder(maxAge time.Duration) {
 func PrintNotaPenjualan(header models.SellHeader, items []DisplayItem) error {
 	pdf := fpdf.New("P", "mm", "A4", "")
 	pdf.AddPage()
+	printStoreHeader(pdf)
 
 	// Title
 	pdf.SetFont("Arial", "B", 16)
@@ -65,6 +97,10 @@ func PrintNotaPenjualan(header models.SellHeader, items []DisplayItem) error {
 	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
 	pdf.CellFormat(100, 6, header.CustomerName, "", 1, "L", false, 0, "")
 
+	pdf.CellFormat(30, 6, "Pembayaran", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(100, 6, sellPaymentSummary(header), "", 1, "L", false, 0, "")
+
 	pdf.Ln(5)
 
 	// Table Header
@@ -91,7 +127,6 @@ func PrintNotaPenjualan(header models.SellHeader, items []DisplayItem) error {
 	pdf.CellFormat(45, 8, FormatCurrency(header.TotalAmount), "1", 1, "R", false, 0, "")
 
 	// Create temp directory if it doesn't exist
-	tempDir := "temp"
 	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
 		return fmt.Errorf("gagal membuat folder temp: %v", err)
 	}
@@ -121,3 +156,232 @@ func openFile(fileName string) error {
 	}
 	return err
 }
+
+// PrintKartuStok generates a PDF stock card for the given item and period
+// and automatically opens it.
+func PrintKartuStok(card *models.StockCard) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	printStoreHeader(pdf)
+
+	// Title
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(190, 10, "KARTU STOK", "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	// Header Info
+	pdf.SetFont("Arial", "", 10)
+	pdf.CellFormat(30, 6, "Barang", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, fmt.Sprintf("%s - %s", card.Item.Code, card.Item.Name), "", 1, "L", false, 0, "")
+
+	pdf.CellFormat(30, 6, "Periode", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, fmt.Sprintf("%s s/d %s", card.StartDate.Format("02-01-2006"), card.EndDate.Format("02-01-2006")), "", 1, "L", false, 0, "")
+
+	pdf.Ln(5)
+
+	// Table Header
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(25, 8, "Tanggal", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(50, 8, "No. Dokumen", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 8, "Jenis", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(25, 8, "Masuk", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(25, 8, "Keluar", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(25, 8, "Saldo", "1", 1, "C", false, 0, "")
+
+	// Opening balance
+	pdf.SetFont("Arial", "I", 10)
+	pdf.CellFormat(165, 8, "Saldo Awal", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(25, 8, fmt.Sprintf("%.0f", card.OpeningBalance), "1", 1, "R", false, 0, "")
+
+	// Table Body
+	pdf.SetFont("Arial", "", 10)
+	for _, e := range card.Entries {
+		qtyIn, qtyOut := "", ""
+		if e.QtyIn() > 0 {
+			qtyIn = fmt.Sprintf("%.0f", e.QtyIn())
+		}
+		if e.QtyOut() > 0 {
+			qtyOut = fmt.Sprintf("%.0f", e.QtyOut())
+		}
+		pdf.CellFormat(25, 8, e.TrxDate.Format("02-01-2006"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(50, 8, e.DocumentNum, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 8, MutationTypeLabel(e.ModelType), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 8, qtyIn, "1", 0, "R", false, 0, "")
+		pdf.CellFormat(25, 8, qtyOut, "1", 0, "R", false, 0, "")
+		pdf.CellFormat(25, 8, fmt.Sprintf("%.0f", e.Balance), "1", 1, "R", false, 0, "")
+	}
+
+	// Closing balance
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(165, 8, "Saldo Akhir", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(25, 8, fmt.Sprintf("%.0f", card.ClosingBalance), "1", 1, "R", false, 0, "")
+
+	// Create temp directory if it doesn't exist
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return fmt.Errorf("gagal membuat folder temp: %v", err)
+	}
+
+	// Save file
+	fileName := filepath.Join(tempDir, fmt.Sprintf("Kartu_Stok_%s_%s_%s.pdf",
+		card.Item.Code, card.StartDate.Format("20060102"), card.EndDate.Format("20060102")))
+	err := pdf.OutputFileAndClose(fileName)
+	if err != nil {
+		return err
+	}
+
+	return openFile(fileName)
+}
+
+// PrintCustomerStatement generates a PDF account statement (piutang) of a
+// customer for the given period and automatically opens it.
+func PrintCustomerStatement(st *models.CustomerStatement) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	printStoreHeader(pdf)
+
+	// Title
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(190, 10, "REKENING KORAN PIUTANG", "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	// Header Info
+	pdf.SetFont("Arial", "", 10)
+	pdf.CellFormat(30, 6, "Customer", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, fmt.Sprintf("%s - %s", st.Customer.Code, st.Customer.Name), "", 1, "L", false, 0, "")
+
+	if st.Customer.Address != "" {
+		pdf.CellFormat(30, 6, "Alamat", "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+		pdf.CellFormat(150, 6, st.Customer.Address, "", 1, "L", false, 0, "")
+	}
+
+	pdf.CellFormat(30, 6, "Periode", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, fmt.Sprintf("%s s/d %s", st.StartDate.Format("02-01-2006"), st.EndDate.Format("02-01-2006")), "", 1, "L", false, 0, "")
+
+	pdf.CellFormat(30, 6, "Limit Kredit", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, FormatCurrency(st.Customer.CreditLimit), "", 1, "L", false, 0, "")
+
+	pdf.Ln(5)
+
+	// Table Header
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(25, 8, "Tanggal", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 8, "No. Nota", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(45, 8, "Keterangan", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(25, 8, "Debet", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(25, 8, "Kredit", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(30, 8, "Saldo", "1", 1, "C", false, 0, "")
+
+	// Opening balance
+	pdf.SetFont("Arial", "I", 10)
+	pdf.CellFormat(160, 8, "Saldo Awal", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(30, 8, FormatCurrency(st.OpeningBalance), "1", 1, "R", false, 0, "")
+
+	// Table Body
+	pdf.SetFont("Arial", "", 10)
+	for _, e := range st.Entries {
+		debit, credit := "", ""
+		if e.Debit > 0 {
+			debit = FormatCurrency(e.Debit)
+		}
+		if e.Credit > 0 {
+			credit = FormatCurrency(e.Credit)
+		}
+		pdf.CellFormat(25, 8, e.Date.Format("02-01-2006"), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(40, 8, e.DocumentNum, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(45, 8, e.Description, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 8, debit, "1", 0, "R", false, 0, "")
+		pdf.CellFormat(25, 8, credit, "1", 0, "R", false, 0, "")
+		pdf.CellFormat(30, 8, FormatCurrency(e.Balance), "1", 1, "R", false, 0, "")
+	}
+
+	// Closing balance
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(160, 8, "Saldo Akhir", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 8, FormatCurrency(st.ClosingBalance), "1", 1, "R", false, 0, "")
+
+	// Create temp directory if it doesn't exist
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return fmt.Errorf("gagal membuat folder temp: %v", err)
+	}
+
+	// Save file
+	fileName := filepath.Join(tempDir, fmt.Sprintf("Rekening_Piutang_%s_%s_%s.pdf",
+		st.Customer.Code, st.StartDate.Format("20060102"), st.EndDate.Format("20060102")))
+	err := pdf.OutputFileAndClose(fileName)
+	if err != nil {
+		return err
+	}
+
+	return openFile(fileName)
+}
+
+// PrintInventoryValuation generates a PDF of the stock value per item as of
+// the valuation date and automatically opens it.
+func PrintInventoryValuation(v *models.InventoryValuation) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+	printStoreHeader(pdf)
+
+	// Title
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(190, 10, "LAPORAN NILAI PERSEDIAAN", "", 1, "C", false, 0, "")
+	pdf.Ln(5)
+
+	// Header Info
+	pdf.SetFont("Arial", "", 10)
+	pdf.CellFormat(30, 6, "Per Tanggal", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, v.AsOf.Format("02-01-2006"), "", 1, "L", false, 0, "")
+
+	pdf.CellFormat(30, 6, "Dasar Harga", "", 0, "L", false, 0, "")
+	pdf.CellFormat(5, 6, ":", "", 0, "L", false, 0, "")
+	pdf.CellFormat(150, 6, valuationMethodLabel(v.Method), "", 1, "L", false, 0, "")
+
+	pdf.Ln(5)
+
+	// Table Header
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(25, 8, "Kode", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(70, 8, "Nama Barang", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(20, 8, "Qty", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(35, 8, "Harga Pokok", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(40, 8, "Nilai", "1", 1, "C", false, 0, "")
+
+	// Table Body
+	pdf.SetFont("Arial", "", 10)
+	for _, row := range v.Rows {
+		pdf.CellFormat(25, 8, row.Code, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(70, 8, row.Name, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 8, fmt.Sprintf("%.0f", row.Qty), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 8, FormatCurrency(row.UnitCost), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 8, FormatCurrency(row.Value), "1", 1, "R", false, 0, "")
+	}
+
+	// Grand total
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(95, 8, "TOTAL", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(20, 8, fmt.Sprintf("%.0f", v.TotalQty), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 8, "", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(40, 8, FormatCurrency(v.TotalValue), "1", 1, "R", false, 0, "")
+
+	// Create temp directory if it doesn't exist
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return fmt.Errorf("gagal membuat folder temp: %v", err)
+	}
+
+	// Save file
+	fileName := filepath.Join(tempDir, fmt.Sprintf("Nilai_Persediaan_%s_%s.pdf",
+		v.AsOf.Format("20060102"), v.Method))
+	err := pdf.OutputFileAndClose(fileName)
+	if err != nil {
+		return err
+	}
+
+	return openFile(fileName)
+}
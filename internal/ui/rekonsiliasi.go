@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// RekonsiliasiStokPage lists items whose qty no longer matches the stock_mutations
+// ledger and lets an admin repair them in either direction.
+func RekonsiliasiStokPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("REKONSILIASI STOK", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), widget.NewLabel(""))
+
+	headers := []string{"Kode Barang", "Nama Barang", "Qty Barang", "Qty Ledger", "Selisih"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []models.StockDiscrepancy
+	var selectedRow int = -1
+
+	statusText := canvas.NewText("", color.White)
+	statusText.TextStyle = fyne.TextStyle{Bold: true}
+
+	loadData := func() {
+		selectedRow = -1
+		rows, err := s.ReconRepo.GetDiscrepancies()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+		data = rows
+		if len(data) == 0 {
+			statusText.Text = "Semua qty barang sesuai dengan ledger mutasi stok."
+		} else {
+			statusText.Text = fmt.Sprintf("%d barang tidak sesuai dengan ledger (diperiksa %s)", len(data), time.Now().Format("15:04:05"))
+		}
+		statusText.Refresh()
+	}
+
+	loadData()
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				row := data[id.Row-1]
+				switch id.Col {
+				case 0:
+					text.Text = row.Code
+					text.Alignment = fyne.TextAlignLeading
+				case 1:
+					text.Text = row.Name
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = fmt.Sprintf("%.0f", row.Qty)
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = fmt.Sprintf("%.0f", row.LedgerQty)
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = fmt.Sprintf("%+.0f", row.Difference())
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 140)
+	table.SetColumnWidth(1, 400)
+	table.SetColumnWidth(2, 130)
+	table.SetColumnWidth(3, 130)
+	table.SetColumnWidth(4, 130)
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 && id.Row-1 < len(data) {
+			if selectedRow == id.Row-1 {
+				selectedRow = -1
+			} else {
+				selectedRow = id.Row - 1
+			}
+			table.UnselectAll()
+			table.Refresh()
+		}
+	}
+
+	refreshTable := func() {
+		loadData()
+		table.Refresh()
+	}
+
+	// targets returns the selected item, or every listed discrepancy when nothing is selected
+	targets := func() ([]uuid.UUID, string) {
+		if selectedRow >= 0 && selectedRow < len(data) {
+			row := data[selectedRow]
+			return []uuid.UUID{row.ItemID}, fmt.Sprintf("barang '%s'", row.Name)
+		}
+		ids := make([]uuid.UUID, len(data))
+		for i, row := range data {
+			ids[i] = row.ItemID
+		}
+		return ids, fmt.Sprintf("%d barang", len(ids))
+	}
+
+	checkBtn := widget.NewButtonWithIcon("Periksa Ulang", theme.ViewRefreshIcon(), refreshTable)
+
+	rebuildBtn := widget.NewButtonWithIcon("Rebuild Qty dari Ledger", theme.HistoryIcon(), func() {
+		ids, label := targets()
+		if len(ids) == 0 {
+			dialog.ShowInformation("Info", "Tidak ada selisih yang perlu diperbaiki.", w)
+			return
+		}
+		dialog.ShowConfirm("Rebuild Qty",
+			fmt.Sprintf("Qty %s akan ditimpa dengan saldo ledger mutasi stok.\n\nLanjutkan?", label),
+			func(b bool) {
+				if !b {
+					return
+				}
+				if err := s.ReconRepo.RebuildQty(ids, s.User.ID); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				ShowSuccessToast("Success", "Qty barang berhasil di-rebuild!", w)
+				refreshTable()
+			}, w)
+	})
+	rebuildBtn.Importance = widget.WarningImportance
+
+	adjustBtn := widget.NewButtonWithIcon("Posting Penyesuaian", theme.DocumentCreateIcon(), func() {
+		ids, label := targets()
+		if len(ids) == 0 {
+			dialog.ShowInformation("Info", "Tidak ada selisih yang perlu diperbaiki.", w)
+			return
+		}
+		dialog.ShowConfirm("Posting Penyesuaian",
+			fmt.Sprintf("Mutasi penyesuaian akan dicatat untuk %s sehingga ledger sama dengan qty barang saat ini.\n\nLanjutkan?", label),
+			func(b bool) {
+				if !b {
+					return
+				}
+				if err := s.ReconRepo.PostAdjustments(ids, s.User.ID); err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				ShowSuccessToast("Success", "Mutasi penyesuaian berhasil diposting!", w)
+				refreshTable()
+			}, w)
+	})
+	adjustBtn.Importance = widget.HighImportance
+
+	actions := container.NewHBox(checkBtn, rebuildBtn, adjustBtn)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 420), table))
+
+	footer := canvas.NewText("Klik baris untuk memperbaiki satu barang, tanpa pilihan aksi berlaku untuk semua barang", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(
+		container.NewVBox(header, container.NewBorder(nil, nil, statusText, actions)),
+		footer,
+		nil,
+		nil,
+		tableWrapper,
+	)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	w.Canvas().SetOnTypedKey(nil)
+
+	return container.NewMax(bg, centeredPanel)
+}
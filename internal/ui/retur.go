@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"strconv"
@@ -8,6 +9,9 @@ import (
 	"time"
 
 	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 
 	"fyne.io/fyne/v2"
@@ -43,10 +47,16 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 	tglNota := widget.NewLabel(time.Now().Format("2006-01-02"))
 	tglNota.TextStyle = fyne.TextStyle{Bold: true}
 
+	// Preview nomor nota otomatis ikut berubah saat tanggal nota diganti
+	var onDateChanged func()
+
 	// Calendar button with calendar icon only
 	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
 		ShowDatePickerDialog(w, tglNota.Text, func(selectedDate string) {
 			tglNota.SetText(selectedDate)
+			if onDateChanged != nil {
+				onDateChanged()
+			}
 		})
 	})
 	calendarBtn.Importance = widget.LowImportance
@@ -58,7 +68,18 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 	var vendorWidget fyne.CanvasObject
 
 	noNota := widget.NewEntry()
-	vendor := widget.NewEntry()
+	vendorPicker := newSupplierPicker(s)
+	vendor := vendorPicker.Entry
+
+	// Nota pembelian yang hutangnya dikurangi oleh retur ini
+	purchaseLink := newPurchaseLinkSelect()
+	vendorPicker.OnSelected = func(supplier *models.Supplier) {
+		if supplier == nil {
+			purchaseLink.Load(s, nil, nil)
+			return
+		}
+		purchaseLink.Load(s, &supplier.ID, nil)
+	}
 
 	noNotaLabel := widget.NewLabel("")
 	noNotaLabel.TextStyle = fyne.TextStyle{Bold: false}
@@ -440,8 +461,18 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 		noNotaWidget = noNotaLabel
 		vendorWidget = vendorLabel
 		calendarBtn.Disable()
+		purchaseLink.Load(s, existingData.Header.SupplierID, existingData.Header.PurchaseID)
+		purchaseLink.Select.Disable()
+	} else if existingData == nil {
+		// New mode: nomor nota dibuat otomatis saat disimpan
+		onDateChanged = func() {
+			noNotaLabel.SetText(previewInvoiceNum(s, numbering.DocRetur, tglNota.Text))
+		}
+		onDateChanged()
+		noNotaWidget = noNotaLabel
+		vendorWidget = vendor
 	} else {
-		// New or Edit mode: use Entry fields
+		// Edit mode: use Entry fields
 		noNotaWidget = noNota
 		vendorWidget = vendor
 	}
@@ -450,6 +481,7 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 		widget.NewFormItem("Tgl. Nota", tglNotaContainer),
 		widget.NewFormItem("No. Nota", noNotaWidget),
 		widget.NewFormItem("Vendor", vendorWidget),
+		widget.NewFormItem("Nota Beli", purchaseLink.Select),
 	)
 	headerFormSeparator := widget.NewSeparator()
 
@@ -598,33 +630,33 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 	}
 
 	var d dialog.Dialog
-	submitBtn := widget.NewButton("Submit", func() {
-		if tglNota.Text == "" || noNota.Text == "" || vendor.Text == "" {
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Submit", func() {
+		if tglNota.Text == "" || (isEditMode && noNota.Text == "") || vendor.Text == "" {
 			dialog.ShowInformation("Error", "Header data harus diisi!", w)
 			return
 		}
 
-		if !isEditMode {
-			// Validasi No Nota duplikat
-			existing, _ := s.ReturRepo.GetByInvoiceNum(noNota.Text)
-			if existing != nil {
-				dialog.ShowInformation("Error", "No. Nota sudah terdaftar, silakan gunakan nomor lain!", w)
-				return
-			}
-		}
-
 		if len(items) == 0 {
 			dialog.ShowInformation("Error", "Minimal 1 item harus ditambahkan!", w)
 			return
 		}
 
+		supplier := vendorPicker.Selected()
+		if supplier == nil {
+			dialog.ShowInformation("Error", "Supplier tidak terdaftar! Pilih dari daftar atau tambahkan di Master Supplier.", w)
+			return
+		}
+
 		returDate, _ := time.Parse("2006-01-02", tglNota.Text)
 		grandTotal := recalculateTotal()
 
 		header := models.ReturHeader{
 			ReturInvoiceNum: noNota.Text,
 			ReturDate:       returDate,
-			SupplierName:    vendor.Text,
+			SupplierName:    supplier.Name,
+			SupplierID:      &supplier.ID,
+			PurchaseID:      purchaseLink.Selected(),
 			TotalAmount:     grandTotal,
 			CreatedBy:       &s.User.ID,
 		}
@@ -647,6 +679,7 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 			header.ID = existingData.Header.ID
 			header.CreatedAt = existingData.Header.CreatedAt
 			header.CreatedBy = existingData.Header.CreatedBy
+			header.Version = existingData.Header.Version
 			now := time.Now()
 			header.UpdatedAt = &now
 			header.UpdatedBy = &s.User.ID
@@ -659,12 +692,47 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 			_, err = s.ReturRepo.Create(&header, details)
 		}
 
+		if errors.Is(err, repository.ErrDocumentVoided) {
+			// Nota yang sudah di-void tidak bisa ditimpa, hanya bisa dilihat ulang
+			showConflictDialog(w, func() {
+				d.Hide()
+				if refreshCallback != nil {
+					refreshCallback()
+				}
+				showViewReturDialog(w, s, existingData.Header.ID)
+			}, nil)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			showConflictDialog(w, func() {
+				latest, err := s.ReturRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				d.Hide()
+				showReturDialog(w, s, refreshCallback, latest, true)
+			}, func() {
+				latest, err := s.ReturRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				existingData.Header.Version = latest.Header.Version
+				submitBtn.OnTapped()
+			})
+			return
+		}
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Gagal menyimpan data: %v", err), w)
+			showSaveError(w, err)
 			return
 		}
 
-		ShowSuccessToast("Success", "Data retur pembelian berhasil disimpan!", w)
+		if isEditMode {
+			ShowSuccessToast("Success", "Data retur pembelian berhasil disimpan!", w)
+		} else {
+			ShowSuccessToast("Success", "Data retur pembelian berhasil disimpan dengan No. Nota "+header.ReturInvoiceNum, w)
+		}
 		d.Hide()
 		if refreshCallback != nil {
 			refreshCallback()
@@ -734,7 +802,8 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 		tglNota.SetText(formattedDate)
 		if isEditMode {
 			noNota.SetText(existingData.Header.ReturInvoiceNum)
-			vendor.SetText(existingData.Header.SupplierName)
+			vendorPicker.Select(s, existingData.Header.SupplierID, existingData.Header.SupplierName)
+			purchaseLink.Load(s, existingData.Header.SupplierID, existingData.Header.PurchaseID)
 		}
 
 		displayItems := LoadReturDisplayItems(s, existingData.Details)
@@ -786,7 +855,11 @@ func showReturDialog(w fyne.Window, s *state.Session, refreshCallback func(), ex
 	if !(existingData != nil && !isEditMode) {
 		time.AfterFunc(100*time.Millisecond, func() {
 			fyne.Do(func() {
-				w.Canvas().Focus(noNota)
+				if existingData == nil {
+					w.Canvas().Focus(vendor)
+				} else {
+					w.Canvas().Focus(noNota)
+				}
 			})
 		})
 	}
@@ -802,11 +875,18 @@ func showViewReturDialog(w fyne.Window, s *state.Session, headerID uuid.UUID) {
 	tglNota := widget.NewLabel(retur.Header.ReturDate.Format("2006-01-02"))
 	noNota := widget.NewLabel(retur.Header.ReturInvoiceNum)
 	vendor := widget.NewLabel(retur.Header.SupplierName)
+	notaBeli := widget.NewLabel("-")
+	if retur.Header.PurchaseID != nil {
+		if purchase, err := s.PurchaseRepo.GetByID(*retur.Header.PurchaseID); err == nil {
+			notaBeli.SetText(purchase.Header.PurchaseInvoiceNum)
+		}
+	}
 
 	headerInfo := widget.NewForm(
 		widget.NewFormItem("Tgl. Nota", tglNota),
 		widget.NewFormItem("No. Nota", noNota),
 		widget.NewFormItem("Vendor", vendor),
+		widget.NewFormItem("Nota Beli", notaBeli),
 	)
 
 	displayItems := LoadReturDisplayItems(s, retur.Details)
@@ -918,7 +998,10 @@ func ReturPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
 	bg.FillMode = canvas.ImageFillStretch
 
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
 	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
 		w.SetContent(HomePage(w, s))
 	})
 
@@ -1170,6 +1253,11 @@ func ReturPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 					return
 				}
 
+				if !s.Can(models.PermVoid) {
+					showAccessDenied(w)
+					return
+				}
+
 				dialog.ShowConfirm("Void Nota Retur Pembelian",
 					"Apakah Anda yakin ingin melakukan VOID pada nota retur ini?\n\nAksi ini akan membatalkan retur barang ke supplier sehingga barang akan masuk kembali ke stok gudang, dan mengubah status nota menjadi VOID.",
 					func(b bool) {
@@ -1276,5 +1364,16 @@ func ReturPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
 
 	time.AfterFunc(150*time.Millisecond, safeFocus)
 
-	return container.NewMax(bg, centeredPanel)
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicRetur)
+
+	return page
 }
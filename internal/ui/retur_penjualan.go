@@ -0,0 +1,1023 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/numbering"
+	"fyne-app/internal/repository"
+	"fyne-app/internal/state"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/google/uuid"
+)
+
+type SellReturHeaderUI struct {
+	ID       uuid.UUID
+	TglNota  string
+	NoNota   string
+	NotaJual string
+	Customer string
+	Total    string
+	Status   string
+}
+
+type SellReturItemUI struct {
+	ItemID     uuid.UUID
+	KodeBarang string
+	NamaBarang string
+	Qty        float64
+	Harga      float64
+}
+
+// sellPicker is the searchable nota penjualan field of the retur penjualan
+// dialog; only active notas can be returned against
+type sellPicker struct {
+	Entry   *widget.SelectEntry
+	sells   []models.SellHeader
+	options []string
+
+	// OnSelected is called when the chosen nota changes, with nil when the
+	// text no longer matches one
+	OnSelected func(*models.SellHeader)
+	lastID     uuid.UUID
+}
+
+func newSellPicker(s *state.Session) *sellPicker {
+	p := &sellPicker{}
+	sells, _ := s.SellRepo.GetAll()
+	for _, h := range sells {
+		if h.Status != "ACTIVE" {
+			continue
+		}
+		p.sells = append(p.sells, h)
+		p.options = append(p.options, sellOption(h))
+	}
+
+	p.Entry = widget.NewSelectEntry(p.options)
+	p.Entry.PlaceHolder = "Cari No. Nota Jual..."
+	p.Entry.OnChanged = func(text string) {
+		selected := p.Selected()
+		var id uuid.UUID
+		if selected != nil {
+			id = selected.ID
+		}
+		if id != p.lastID {
+			p.lastID = id
+			if p.OnSelected != nil {
+				p.OnSelected(selected)
+			}
+		}
+		if text == "" || selected != nil {
+			p.Entry.SetOptions(p.options)
+			return
+		}
+		var filtered []string
+		for _, opt := range p.options {
+			if containsCI(opt, text) {
+				filtered = append(filtered, opt)
+			}
+		}
+		p.Entry.SetOptions(filtered)
+	}
+	return p
+}
+
+func sellOption(h models.SellHeader) string {
+	return fmt.Sprintf("%s - %s (%s)", h.SellInvoiceNum, h.CustomerName, h.SellDate.Format("2006-01-02"))
+}
+
+// Selected resolves the typed or chosen text to a nota penjualan, or nil
+func (p *sellPicker) Selected() *models.SellHeader {
+	text := strings.TrimSpace(p.Entry.Text)
+	for i, h := range p.sells {
+		if text == sellOption(h) || strings.EqualFold(text, h.SellInvoiceNum) {
+			return &p.sells[i]
+		}
+	}
+	return nil
+}
+
+func showReturPenjualanDialog(w fyne.Window, s *state.Session, refreshCallback func(), existingData *models.SellReturFull, isEditMode bool) {
+	isPreview := existingData != nil && !isEditMode
+
+	// Header form fields
+	tglNota := widget.NewLabel(time.Now().Format("2006-01-02"))
+	tglNota.TextStyle = fyne.TextStyle{Bold: true}
+
+	// Preview nomor nota otomatis ikut berubah saat tanggal nota diganti
+	var onDateChanged func()
+
+	calendarBtn := widget.NewButtonWithIcon("", theme.CalendarIcon(), func() {
+		ShowDatePickerDialog(w, tglNota.Text, func(selectedDate string) {
+			tglNota.SetText(selectedDate)
+			if onDateChanged != nil {
+				onDateChanged()
+			}
+		})
+	})
+	calendarBtn.Importance = widget.LowImportance
+
+	tglNotaContainer := container.NewBorder(nil, nil, nil, calendarBtn, tglNota)
+
+	noNota := widget.NewEntry()
+	noNotaLabel := widget.NewLabel("")
+	customerLabel := widget.NewLabel("-")
+	notaJualLabel := widget.NewLabel("")
+
+	var noNotaWidget fyne.CanvasObject = noNotaLabel
+	var notaJualWidget fyne.CanvasObject = notaJualLabel
+
+	// Item entry fields: hanya barang yang ada di nota penjualan yang bisa diretur
+	var returnables []models.SellReturnable
+	var sellID uuid.UUID
+
+	barang := widget.NewSelect(nil, nil)
+	barang.PlaceHolder = "Pilih barang dari nota..."
+	qty := widget.NewEntry()
+	hargaLabel := widget.NewLabel("")
+
+	qtyInfo := canvas.NewText("", color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	qtyInfo.TextSize = 12
+	qtyWarning := canvas.NewText("", color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+	qtyWarning.TextSize = 12
+	qtyWarning.TextStyle = fyne.TextStyle{Italic: true}
+
+	qtyContainer := container.NewVBox(
+		qty,
+		container.NewHBox(qtyInfo, layout.NewSpacer(), qtyWarning),
+	)
+
+	var items []SellReturItemUI
+	var itemsTable *widget.Table
+	var selectedItemIndex int = -1
+
+	selectedReturnable := func() *models.SellReturnable {
+		i := barang.SelectedIndex()
+		if i < 0 || i >= len(returnables) {
+			return nil
+		}
+		return &returnables[i]
+	}
+
+	// availableFor is the qty that can still be returned, excluding what is
+	// already in the list apart from the row being edited
+	availableFor := func(line *models.SellReturnable) float64 {
+		available := line.Available()
+		for i, it := range items {
+			if it.ItemID == line.ItemID && i != selectedItemIndex {
+				available -= it.Qty
+			}
+		}
+		return available
+	}
+
+	updateQtyInfo := func() {
+		qtyInfo.Text = ""
+		qtyWarning.Text = ""
+		hargaLabel.SetText("")
+		if line := selectedReturnable(); line != nil {
+			hargaLabel.SetText(FormatCurrency(line.PriceAmount))
+			qtyInfo.Text = fmt.Sprintf("Terjual %.0f, sudah diretur %.0f, bisa diretur %.0f",
+				line.SoldQty, line.ReturnedQty, availableFor(line))
+			if v, err := strconv.ParseFloat(qty.Text, 64); err == nil {
+				if v > availableFor(line) {
+					qtyWarning.Text = "Melebihi qty jual!"
+				} else if v <= 0 {
+					qtyWarning.Text = "Qty invalid!"
+				}
+			}
+		}
+		qtyInfo.Refresh()
+		qtyWarning.Refresh()
+	}
+	barang.OnChanged = func(string) { updateQtyInfo() }
+	qty.OnChanged = func(string) { updateQtyInfo() }
+
+	loadReturnables := func() {
+		returnables = nil
+		if sellID != uuid.Nil {
+			var excludeID uuid.UUID
+			if existingData != nil {
+				excludeID = existingData.Header.ID
+			}
+			var err error
+			returnables, err = s.SellReturRepo.GetReturnable(sellID, excludeID)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Gagal memuat barang nota: %v", err), w)
+			}
+		}
+		options := make([]string, len(returnables))
+		for i, l := range returnables {
+			options[i] = fmt.Sprintf("%s - %s", l.Code, l.Name)
+		}
+		barang.ClearSelected()
+		barang.SetOptions(options)
+		updateQtyInfo()
+	}
+
+	totalLabel := canvas.NewText("Total : Rp 0", color.Black)
+	totalLabel.TextStyle = fyne.TextStyle{Bold: true}
+	totalLabel.Alignment = fyne.TextAlignTrailing
+
+	recalculateTotal := func() float64 {
+		var sum float64
+		for _, it := range items {
+			sum += it.Qty * it.Harga
+		}
+		totalLabel.Text = "Total : " + FormatCurrency(sum)
+		totalLabel.Refresh()
+		return sum
+	}
+
+	refreshItemsTable := func() {
+		if itemsTable != nil {
+			itemsTable.Refresh()
+		}
+		recalculateTotal()
+		updateQtyInfo()
+	}
+
+	// Buttons
+	addItemBtn := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), nil)
+	addItemBtn.Importance = widget.HighImportance
+
+	deleteItemBtn := widget.NewButtonWithIcon("Hapus Item", theme.DeleteIcon(), nil)
+	deleteItemBtn.Importance = widget.DangerImportance
+
+	clearBtn := widget.NewButtonWithIcon("Batal", theme.ContentClearIcon(), nil)
+
+	clearItemForm := func() {
+		selectedItemIndex = -1
+		barang.ClearSelected()
+		qty.SetText("")
+		updateQtyInfo()
+	}
+
+	updateButtonStates := func() {
+		if selectedItemIndex >= 0 {
+			addItemBtn.SetText("Update")
+			addItemBtn.SetIcon(theme.DocumentSaveIcon())
+			deleteItemBtn.Show()
+			clearBtn.Show()
+		} else {
+			addItemBtn.SetText("Add")
+			addItemBtn.SetIcon(theme.ContentAddIcon())
+			deleteItemBtn.Hide()
+			clearBtn.Hide()
+		}
+	}
+
+	addItemBtn.OnTapped = func() {
+		line := selectedReturnable()
+		if line == nil {
+			dialog.ShowInformation("Error", "Pilih barang dari nota penjualan!", w)
+			return
+		}
+
+		qtyVal, err := strconv.ParseFloat(qty.Text, 64)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Qty harus berupa angka!"), w)
+			return
+		}
+		if qtyVal <= 0 {
+			dialog.ShowInformation("Error", "Qty harus lebih besar dari 0!", w)
+			return
+		}
+		if available := availableFor(line); qtyVal > available {
+			dialog.ShowError(fmt.Errorf("Qty retur melebihi qty yang dijual! Sisa yang bisa diretur: %.0f", available), w)
+			return
+		}
+
+		newItem := SellReturItemUI{
+			ItemID:     line.ItemID,
+			KodeBarang: line.Code,
+			NamaBarang: line.Name,
+			Qty:        qtyVal,
+			Harga:      line.PriceAmount,
+		}
+
+		if selectedItemIndex >= 0 {
+			items[selectedItemIndex] = newItem
+		} else {
+			items = append(items, newItem)
+		}
+
+		clearItemForm()
+		updateButtonStates()
+		refreshItemsTable()
+		w.Canvas().Focus(qty)
+	}
+
+	qty.OnSubmitted = func(string) { addItemBtn.OnTapped() }
+
+	deleteItemBtn.OnTapped = func() {
+		if selectedItemIndex >= 0 {
+			items = append(items[:selectedItemIndex], items[selectedItemIndex+1:]...)
+			clearItemForm()
+			updateButtonStates()
+			refreshItemsTable()
+		}
+	}
+
+	clearBtn.OnTapped = func() {
+		clearItemForm()
+		updateButtonStates()
+	}
+
+	updateButtonStates()
+
+	// Nota jual hanya bisa dipilih saat membuat retur baru
+	sellSelect := newSellPicker(s)
+	sellSelect.OnSelected = func(sell *models.SellHeader) {
+		items = nil
+		sellID = uuid.Nil
+		customerLabel.SetText("-")
+		if sell != nil {
+			sellID = sell.ID
+			customerLabel.SetText(sell.CustomerName)
+		}
+		clearItemForm()
+		updateButtonStates()
+		loadReturnables()
+		refreshItemsTable()
+	}
+
+	if existingData == nil {
+		// New mode: nomor nota dibuat otomatis saat disimpan
+		onDateChanged = func() {
+			noNotaLabel.SetText(previewInvoiceNum(s, numbering.DocSellRetur, tglNota.Text))
+		}
+		onDateChanged()
+		notaJualWidget = sellSelect.Entry
+	} else {
+		tglNota.SetText(existingData.Header.ReturDate.Format("2006-01-02"))
+		noNotaLabel.SetText(existingData.Header.ReturInvoiceNum)
+		notaJualLabel.SetText(existingData.Header.SellInvoiceNum)
+		customerLabel.SetText(existingData.Header.CustomerName)
+		sellID = existingData.Header.SellID
+
+		if isEditMode {
+			noNota.SetText(existingData.Header.ReturInvoiceNum)
+			noNotaWidget = noNota
+			loadReturnables()
+		} else {
+			calendarBtn.Disable()
+		}
+
+		displayItems := LoadSellReturDisplayItems(s, existingData.Details)
+		items = make([]SellReturItemUI, len(existingData.Details))
+		for i, d := range existingData.Details {
+			items[i] = SellReturItemUI{
+				ItemID:     d.ItemID,
+				KodeBarang: displayItems[i].Code,
+				NamaBarang: displayItems[i].Name,
+				Qty:        d.Qty,
+				Harga:      d.PriceAmount,
+			}
+		}
+		recalculateTotal()
+	}
+
+	headerForm := widget.NewForm(
+		widget.NewFormItem("Tgl. Nota", tglNotaContainer),
+		widget.NewFormItem("No. Nota", noNotaWidget),
+		widget.NewFormItem("Nota Jual", notaJualWidget),
+		widget.NewFormItem("Customer", customerLabel),
+	)
+
+	itemForm := widget.NewForm(
+		widget.NewFormItem("Barang", barang),
+		widget.NewFormItem("Qty", qtyContainer),
+		widget.NewFormItem("Harga", hargaLabel),
+	)
+	itemFormButtons := container.NewHBox(addItemBtn, deleteItemBtn, clearBtn)
+
+	itemHeaders := []string{"Kode Barang", "Nama Barang", "QTY", "Harga", "Total", ""}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	itemsTable = widget.NewTable(
+		func() (int, int) { return len(items) + 1, len(itemHeaders) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.Alignment = fyne.TextAlignCenter
+			text.TextSize = 13
+			btn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {})
+			return container.NewMax(bg, text, btn)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+			btn := cont.Objects[2].(*widget.Button)
+			btn.Hide()
+			text.Show()
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = itemHeaders[id.Col]
+				text.Color = color.White
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			bg.FillColor = rowBg
+			text.Color = color.Black
+			text.TextStyle = fyne.TextStyle{}
+
+			if id.Row-1 < len(items) {
+				item := items[id.Row-1]
+				switch id.Col {
+				case 0:
+					text.Text = item.KodeBarang
+					text.Alignment = fyne.TextAlignLeading
+				case 1:
+					text.Text = item.NamaBarang
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = fmt.Sprintf("%.0f", item.Qty)
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = FormatCurrency(item.Harga)
+					text.Alignment = fyne.TextAlignTrailing
+				case 4:
+					text.Text = FormatCurrency(item.Qty * item.Harga)
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = ""
+					text.Hide()
+					if !isPreview {
+						btn.OnTapped = func() {
+							rowIndex := id.Row - 1
+							items = append(items[:rowIndex], items[rowIndex+1:]...)
+							clearItemForm()
+							updateButtonStates()
+							refreshItemsTable()
+						}
+						btn.Show()
+					}
+				}
+			} else {
+				text.Text = ""
+			}
+			text.Refresh()
+		},
+	)
+
+	itemsTable.OnSelected = func(id widget.TableCellID) {
+		if isPreview || id.Row == 0 || id.Row-1 >= len(items) {
+			return
+		}
+		item := items[id.Row-1]
+		for i, l := range returnables {
+			if l.ItemID == item.ItemID {
+				selectedItemIndex = id.Row - 1
+				barang.SetSelectedIndex(i)
+				qty.SetText(fmt.Sprintf("%.0f", item.Qty))
+				updateButtonStates()
+				return
+			}
+		}
+	}
+
+	itemsTable.SetColumnWidth(0, 120) // Kode
+	itemsTable.SetColumnWidth(1, 200) // Nama
+	itemsTable.SetColumnWidth(2, 60)  // Qty
+	itemsTable.SetColumnWidth(3, 120) // Harga
+	itemsTable.SetColumnWidth(4, 120) // Total
+	itemsTable.SetColumnWidth(5, 40)  // Delete
+
+	if isPreview {
+		itemsTable.SetColumnWidth(4, 160) // Absorb Delete button width (120+40)
+		itemsTable.SetColumnWidth(5, 0)
+	}
+
+	var d dialog.Dialog
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Submit", func() {
+		if tglNota.Text == "" || (isEditMode && noNota.Text == "") {
+			dialog.ShowInformation("Error", "Header data harus diisi!", w)
+			return
+		}
+		if sellID == uuid.Nil {
+			dialog.ShowInformation("Error", "Pilih nota penjualan yang diretur!", w)
+			return
+		}
+		if len(items) == 0 {
+			dialog.ShowInformation("Error", "Minimal 1 item harus ditambahkan!", w)
+			return
+		}
+
+		returDate, err := time.Parse("2006-01-02", tglNota.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Format tanggal salah! Gunakan YYYY-MM-DD"), w)
+			return
+		}
+
+		header := models.SellReturHeader{
+			ReturDate:   returDate,
+			SellID:      sellID,
+			TotalAmount: recalculateTotal(),
+			CreatedBy:   &s.User.ID,
+		}
+
+		details := make([]models.SellReturDetail, len(items))
+		for i, it := range items {
+			details[i] = models.SellReturDetail{
+				ItemID:      it.ItemID,
+				Qty:         it.Qty,
+				PriceAmount: it.Harga,
+				TotalAmount: it.Qty * it.Harga,
+			}
+		}
+
+		if isEditMode && existingData != nil {
+			header.ID = existingData.Header.ID
+			header.ReturInvoiceNum = strings.TrimSpace(noNota.Text)
+			header.CreatedAt = existingData.Header.CreatedAt
+			header.CreatedBy = existingData.Header.CreatedBy
+			header.Version = existingData.Header.Version
+			header.UpdatedBy = &s.User.ID
+			err = s.SellReturRepo.Update(&header, details)
+		} else {
+			_, err = s.SellReturRepo.Create(&header, details)
+		}
+
+		if errors.Is(err, repository.ErrDocumentVoided) {
+			// Nota yang sudah di-void tidak bisa ditimpa, hanya bisa dilihat ulang
+			showConflictDialog(w, func() {
+				d.Hide()
+				if refreshCallback != nil {
+					refreshCallback()
+				}
+				showViewReturPenjualanDialog(w, s, existingData.Header.ID, nil)
+			}, nil)
+			return
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			showConflictDialog(w, func() {
+				latest, err := s.SellReturRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				d.Hide()
+				showReturPenjualanDialog(w, s, refreshCallback, latest, true)
+			}, func() {
+				latest, err := s.SellReturRepo.GetByID(existingData.Header.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				existingData.Header.Version = latest.Header.Version
+				submitBtn.OnTapped()
+			})
+			return
+		}
+		if err != nil {
+			showSaveError(w, err)
+			return
+		}
+
+		if isEditMode {
+			ShowSuccessToast("Success", "Data retur penjualan berhasil disimpan!", w)
+		} else {
+			ShowSuccessToast("Success", "Data retur penjualan berhasil disimpan dengan No. Nota "+header.ReturInvoiceNum, w)
+		}
+		d.Hide()
+		if refreshCallback != nil {
+			refreshCallback()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if refreshCallback != nil {
+			refreshCallback()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	var buttons *fyne.Container
+	if isPreview {
+		cancelBtn.SetText("Tutup")
+		cancelBtn.Importance = widget.HighImportance
+		buttons = container.NewGridWithColumns(1, cancelBtn)
+	} else {
+		buttons = container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	}
+
+	labelText := "Isi Data Retur Penjualan"
+	if isEditMode {
+		labelText = "Edit Data Retur"
+	} else if existingData != nil {
+		labelText = "Detail Retur"
+	}
+
+	topContent := container.NewVBox(
+		container.NewCenter(widget.NewLabelWithStyle("MENU RETUR PENJUALAN", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})),
+		container.NewCenter(widget.NewLabelWithStyle(labelText, fyne.TextAlignCenter, fyne.TextStyle{})),
+		widget.NewSeparator(),
+		headerForm,
+		widget.NewSeparator(),
+	)
+	if !isPreview {
+		topContent.Add(itemForm)
+		topContent.Add(itemFormButtons)
+		topContent.Add(widget.NewSeparator())
+	}
+
+	tableSection := container.NewBorder(
+		nil,
+		container.NewVBox(
+			widget.NewSeparator(),
+			container.NewHBox(layout.NewSpacer(), totalLabel),
+		),
+		nil,
+		nil,
+		func() fyne.CanvasObject {
+			scroll := container.NewScroll(itemsTable)
+			scroll.SetMinSize(fyne.NewSize(0, 150))
+			return scroll
+		}(),
+	)
+
+	content := container.NewBorder(topContent, buttons, nil, nil, tableSection)
+
+	d = dialog.NewCustom("", "", container.NewPadded(content), w)
+	d.Resize(fyne.NewSize(750, 600))
+	d.Show()
+
+	if !isPreview {
+		time.AfterFunc(100*time.Millisecond, func() {
+			fyne.Do(func() {
+				if existingData == nil {
+					w.Canvas().Focus(sellSelect.Entry)
+				} else {
+					w.Canvas().Focus(noNota)
+				}
+			})
+		})
+	}
+}
+
+// showViewReturPenjualanDialog opens a retur penjualan read-only
+func showViewReturPenjualanDialog(w fyne.Window, s *state.Session, headerID uuid.UUID, onClose func()) {
+	retur, err := s.SellReturRepo.GetByID(headerID)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+		if onClose != nil {
+			onClose()
+		}
+		return
+	}
+	showReturPenjualanDialog(w, s, onClose, retur, false)
+}
+
+func ReturPenjualanPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("MENU RETUR PENJUALAN", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search No. Nota, Nota Jual or Customer...")
+
+	header := container.NewGridWithColumns(3, backBtn, title, container.NewMax(search))
+
+	headers := []string{"Tgl. Nota", "No. Nota", "Nota Jual", "Customer", "Total", ""}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []SellReturHeaderUI
+	var selectedRow int = -1
+	var refreshTable func()
+
+	var isDialogOpen bool
+	var lastDialogTime time.Time
+
+	loadData := func(keyword string) {
+		var headers []models.SellReturHeader
+		var err error
+		if keyword == "" {
+			headers, err = s.SellReturRepo.GetAll()
+		} else {
+			headers, err = s.SellReturRepo.Search(keyword)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		data = nil
+		for _, h := range headers {
+			data = append(data, SellReturHeaderUI{
+				ID:       h.ID,
+				TglNota:  h.ReturDate.Format("2006-01-02"),
+				NoNota:   h.ReturInvoiceNum,
+				NotaJual: h.SellInvoiceNum,
+				Customer: h.CustomerName,
+				Total:    FormatCurrency(h.TotalAmount),
+				Status:   h.Status,
+			})
+		}
+	}
+
+	loadData("")
+
+	openEdit := func(row SellReturHeaderUI) {
+		if row.Status == "VOID" {
+			dialog.ShowInformation("Info", "Nota VOID tidak bisa diedit!", w)
+			return
+		}
+		retur, err := s.SellReturRepo.GetByID(row.ID)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		isDialogOpen = true
+		showReturPenjualanDialog(w, s, func() { isDialogOpen = false; refreshTable() }, retur, true)
+	}
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+
+			editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			editBtn.Importance = widget.LowImportance
+			return container.NewMax(bg, text, container.NewCenter(editBtn))
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			btnCont := cont.Objects[2].(*fyne.Container)
+			editBtn := btnCont.Objects[0].(*widget.Button)
+			editBtn.Hide()
+			text.Show()
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.Alignment = fyne.TextAlignCenter
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			if id.Row-1 < len(data) {
+				item := data[id.Row-1]
+
+				// Red text indicating VOID for all columns
+				if item.Status == "VOID" && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = item.TglNota
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					if item.Status == "VOID" {
+						text.Text = item.NoNota + " [VOID]"
+					} else {
+						text.Text = item.NoNota
+					}
+					text.Alignment = fyne.TextAlignCenter
+				case 2:
+					text.Text = item.NotaJual
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = item.Customer
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = item.Total
+					text.Alignment = fyne.TextAlignTrailing
+				case 5:
+					text.Text = ""
+					text.Hide()
+					row := item
+					if row.Status == "VOID" {
+						editBtn.Disable()
+					} else {
+						editBtn.Enable()
+					}
+					editBtn.OnTapped = func() {
+						if isDialogOpen {
+							return
+						}
+						openEdit(row)
+					}
+					editBtn.Show()
+				}
+			}
+		},
+	)
+
+	table.SetColumnWidth(0, 130)
+	table.SetColumnWidth(1, 200)
+	table.SetColumnWidth(2, 200)
+	table.SetColumnWidth(3, 220)
+	table.SetColumnWidth(4, 150)
+	table.SetColumnWidth(5, 50) // Edit button
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable = func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+		safeFocus()
+	}
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		case fyne.KeyInsert:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showReturPenjualanDialog(w, s, func() { isDialogOpen = false; refreshTable() }, nil, false)
+
+		// Edit nota
+		case fyne.KeyE:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				openEdit(data[selectedRow])
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		// Show View details
+		case fyne.KeyV:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showViewReturPenjualanDialog(w, s, data[selectedRow].ID, func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu!", w)
+			}
+
+		case fyne.KeyDelete:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				selectedID := data[selectedRow].ID
+
+				if data[selectedRow].Status == "VOID" {
+					dialog.ShowInformation("Info", "Nota ini sudah berstatus VOID!", w)
+					return
+				}
+
+				if !s.Can(models.PermVoid) {
+					showAccessDenied(w)
+					return
+				}
+
+				dialog.ShowConfirm("Void Nota Retur Penjualan",
+					"Apakah Anda yakin ingin melakukan VOID pada nota retur ini?\n\nBarang yang diretur akan keluar lagi dari stok gudang dan nilai retur kembali menjadi tagihan nota penjualan.",
+					func(b bool) {
+						if b {
+							err := s.SellReturRepo.Void(selectedID, s.User.ID)
+							if err != nil {
+								showSaveError(w, err)
+							} else {
+								dialog.ShowInformation("Sukses", "Nota Retur berhasil di-Void!", w)
+								refreshTable()
+							}
+						}
+					}, w)
+			} else {
+				dialog.ShowInformation("Info", "Pilih nota terlebih dahulu sebelum di-Void!", w)
+			}
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 480), focusWrapper))
+	footer := canvas.NewText("[Insert] Add Retur  [V] View Detail  [E] Edit  [Del] Void", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, footer, nil, nil, tableWrapper)
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicSellRetur)
+
+	return page
+}
@@ -0,0 +1,510 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/notify"
+	"fyne-app/internal/repository"
+	"fyne-app/internal/state"
+
+	"github.com/google/uuid"
+)
+
+// supplierPicker is the searchable supplier field of the purchase and retur
+// dialogs. Options are "CODE - Name" and are filtered while typing.
+type supplierPicker struct {
+	Entry     *widget.SelectEntry
+	suppliers []models.Supplier
+	options   []string
+	syncing   bool
+
+	// OnSelected is called when typing changes which supplier is selected,
+	// with nil when the text no longer matches one
+	OnSelected func(*models.Supplier)
+	lastID     uuid.UUID
+}
+
+func newSupplierPicker(s *state.Session) *supplierPicker {
+	p := &supplierPicker{}
+	p.suppliers, _ = s.SupplierRepo.GetAll()
+	for _, sup := range p.suppliers {
+		p.options = append(p.options, supplierOption(sup))
+	}
+
+	p.Entry = widget.NewSelectEntry(p.options)
+	p.Entry.PlaceHolder = "Cari Supplier..."
+	p.Entry.OnChanged = func(text string) {
+		if p.syncing {
+			return
+		}
+		selected := p.Selected()
+		if p.OnSelected != nil {
+			var id uuid.UUID
+			if selected != nil {
+				id = selected.ID
+			}
+			if id != p.lastID {
+				p.lastID = id
+				p.OnSelected(selected)
+			}
+		}
+		if text == "" || selected != nil {
+			p.Entry.SetOptions(p.options)
+			return
+		}
+		var filtered []string
+		for _, opt := range p.options {
+			if containsCI(opt, text) {
+				filtered = append(filtered, opt)
+			}
+		}
+		p.Entry.SetOptions(filtered)
+	}
+	return p
+}
+
+func supplierOption(sup models.Supplier) string {
+	return sup.Code + " - " + sup.Name
+}
+
+// Selected resolves the typed or chosen text to a supplier: an option, or an
+// exact code or name. It returns nil when the text matches no supplier.
+func (p *supplierPicker) Selected() *models.Supplier {
+	text := strings.TrimSpace(p.Entry.Text)
+	for i, sup := range p.suppliers {
+		if text == supplierOption(sup) || strings.EqualFold(text, sup.Code) || strings.EqualFold(text, sup.Name) {
+			return &p.suppliers[i]
+		}
+	}
+	return nil
+}
+
+// Select shows the supplier of an existing nota. A supplier that was deleted
+// since is added to the options so the nota can still be saved unchanged.
+func (p *supplierPicker) Select(s *state.Session, id *uuid.UUID, name string) {
+	p.syncing = true
+	defer func() { p.syncing = false }()
+
+	if id == nil {
+		p.Entry.SetText(name)
+		return
+	}
+	p.lastID = *id
+	for _, sup := range p.suppliers {
+		if sup.ID == *id {
+			p.Entry.SetText(supplierOption(sup))
+			return
+		}
+	}
+	if sup, err := s.SupplierRepo.GetByID(*id); err == nil {
+		p.suppliers = append(p.suppliers, *sup)
+		p.Entry.SetText(supplierOption(*sup))
+		return
+	}
+	p.Entry.SetText(name)
+}
+
+// showSupplierDialog creates a new supplier when supplier is nil, otherwise edits it
+func showSupplierDialog(w fyne.Window, s *state.Session, supplier *models.Supplier, onClose func()) {
+	isNew := supplier == nil
+
+	kode := widget.NewEntry()
+	nama := widget.NewEntry()
+	alamat := widget.NewMultiLineEntry()
+	alamat.SetMinRowsVisible(3)
+	telepon := widget.NewEntry()
+	npwp := widget.NewEntry()
+
+	if !isNew {
+		kode.SetText(supplier.Code)
+		nama.SetText(supplier.Name)
+		alamat.SetText(supplier.Address)
+		telepon.SetText(supplier.Phone)
+		npwp.SetText(supplier.NPWP)
+	}
+
+	kode.OnSubmitted = func(string) { w.Canvas().Focus(nama) }
+	nama.OnSubmitted = func(string) { w.Canvas().Focus(alamat) }
+	telepon.OnSubmitted = func(string) { w.Canvas().Focus(npwp) }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Kode", kode),
+		widget.NewFormItem("Nama", nama),
+		widget.NewFormItem("Alamat", alamat),
+		widget.NewFormItem("Telepon", telepon),
+		widget.NewFormItem("NPWP", npwp),
+	)
+
+	var d dialog.Dialog
+
+	var submitBtn *widget.Button
+	submitBtn = widget.NewButton("Simpan", func() {
+		if strings.TrimSpace(kode.Text) == "" || strings.TrimSpace(nama.Text) == "" {
+			dialog.ShowInformation("Error", "Kode dan nama supplier harus diisi!", w)
+			return
+		}
+
+		var err error
+		if isNew {
+			err = s.SupplierRepo.Create(&models.Supplier{
+				Code:      strings.TrimSpace(kode.Text),
+				Name:      strings.TrimSpace(nama.Text),
+				Address:   alamat.Text,
+				Phone:     telepon.Text,
+				NPWP:      npwp.Text,
+				CreatedBy: &s.User.ID,
+			})
+		} else {
+			updated := *supplier
+			updated.Code = strings.TrimSpace(kode.Text)
+			updated.Name = strings.TrimSpace(nama.Text)
+			updated.Address = alamat.Text
+			updated.Phone = telepon.Text
+			updated.NPWP = npwp.Text
+			updated.UpdatedBy = &s.User.ID
+			err = s.SupplierRepo.Update(&updated)
+		}
+
+		switch {
+		case errors.Is(err, repository.ErrDuplicateCode):
+			dialog.ShowInformation("Error", "Kode supplier sudah terdaftar, silakan gunakan kode lain!", w)
+			return
+		case errors.Is(err, repository.ErrVersionConflict):
+			showConflictDialog(w, func() {
+				latest, err := s.SupplierRepo.GetByID(supplier.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				d.Hide()
+				showSupplierDialog(w, s, latest, onClose)
+			}, func() {
+				latest, err := s.SupplierRepo.GetByID(supplier.ID)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+					return
+				}
+				supplier.Version = latest.Version
+				submitBtn.OnTapped()
+			})
+			return
+		case err != nil:
+			dialog.ShowError(fmt.Errorf("Gagal menyimpan supplier: %v", err), w)
+			return
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", "Data supplier berhasil disimpan!", w)
+		if onClose != nil {
+			onClose()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	npwp.OnSubmitted = func(string) { submitBtn.OnTapped() }
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	dialogTitle := "Supplier Baru"
+	if !isNew {
+		dialogTitle = "Edit Supplier"
+	}
+	d = dialog.NewCustom(dialogTitle, "", dialogContent, w)
+	d.Resize(fyne.NewSize(480, 400))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			w.Canvas().Focus(kode)
+		})
+	})
+}
+
+// SupplierPage maintains the supplier master data used by pembelian and retur
+func SupplierPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	// Langganan live refresh dilepas saat kembali ke menu utama
+	var unsubscribe func()
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		unsubscribe()
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("MASTER SUPPLIER", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search kode, nama atau telepon...")
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), container.NewMax(search))
+
+	headers := []string{"Kode", "Nama", "Alamat", "Telepon", "NPWP"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	var data []models.Supplier
+	var selectedRow int = -1
+
+	loadData := func(keyword string) {
+		var suppliers []models.Supplier
+		var err error
+
+		if keyword == "" {
+			suppliers, err = s.SupplierRepo.GetAll()
+		} else {
+			suppliers, err = s.SupplierRepo.Search(keyword)
+		}
+
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+		data = suppliers
+	}
+
+	loadData("")
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				sup := data[id.Row-1]
+
+				switch id.Col {
+				case 0:
+					text.Text = sup.Code
+					text.Alignment = fyne.TextAlignCenter
+				case 1:
+					text.Text = sup.Name
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = strings.ReplaceAll(sup.Address, "\n", ", ")
+					text.Alignment = fyne.TextAlignLeading
+				case 3:
+					text.Text = sup.Phone
+					text.Alignment = fyne.TextAlignLeading
+				case 4:
+					text.Text = sup.NPWP
+					text.Alignment = fyne.TextAlignLeading
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 110)
+	table.SetColumnWidth(1, 250)
+	table.SetColumnWidth(2, 270)
+	table.SetColumnWidth(3, 140)
+	table.SetColumnWidth(4, 170)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable := func() {
+		loadData(search.Text)
+		table.Refresh()
+		safeFocus()
+	}
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		// Supplier baru
+		case fyne.KeyInsert:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showSupplierDialog(w, s, nil, func() { isDialogOpen = false; refreshTable() })
+
+		// Edit supplier
+		case fyne.KeyE, fyne.KeyReturn:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				selected := data[selectedRow]
+				showSupplierDialog(w, s, &selected, func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih supplier terlebih dahulu!", w)
+			}
+
+		// Hapus supplier
+		case fyne.KeyDelete:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				selected := data[selectedRow]
+				dialog.ShowConfirm("Hapus Supplier",
+					fmt.Sprintf("Hapus supplier '%s'? Nota lama tetap menyimpan supplier ini.", selected.Name),
+					func(b bool) {
+						if !b {
+							return
+						}
+						if err := s.SupplierRepo.Delete(selected.ID, s.User.ID); err != nil {
+							dialog.ShowError(err, w)
+							return
+						}
+						selectedRow = -1
+						ShowSuccessToast("Success", "Supplier berhasil dihapus!", w)
+						refreshTable()
+					}, w)
+			} else {
+				dialog.ShowInformation("Info", "Pilih supplier terlebih dahulu!", w)
+			}
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 480), focusWrapper))
+
+	footer := canvas.NewText("[Insert] Supplier Baru  [E] Edit  [Del] Hapus  |  ↑↓ = Navigate", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, footer, nil, nil, tableWrapper)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	page := container.NewMax(bg, centeredPanel)
+
+	// Perubahan dari workstation lain: muat ulang tanpa memindahkan fokus
+	unsubscribe = subscribeChanges(w, s, page, func() {
+		loadData(search.Text)
+		if selectedRow >= len(data) {
+			selectedRow = len(data) - 1
+		}
+		table.Refresh()
+	}, notify.TopicSupplier)
+
+	return page
+}
@@ -0,0 +1,498 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fyne-app/internal/models"
+	"fyne-app/internal/repository"
+	"fyne-app/internal/state"
+)
+
+// showUserDialog creates a new user when user is nil, otherwise edits its name and role
+func showUserDialog(w fyne.Window, s *state.Session, user *models.User, roles []models.Role, onClose func()) {
+	isNew := user == nil
+
+	roleNames := make([]string, len(roles))
+	roleCodes := make(map[string]string, len(roles))
+	for i, r := range roles {
+		roleNames[i] = r.Name
+		roleCodes[r.Name] = r.Code
+	}
+
+	username := widget.NewEntry()
+	nama := widget.NewEntry()
+	password := widget.NewPasswordEntry()
+	roleSelect := widget.NewSelect(roleNames, nil)
+
+	if isNew {
+		roleSelect.SetSelected(roleLabel(roles, models.RoleKasir))
+	} else {
+		username.SetText(user.Username)
+		username.Disable()
+		nama.SetText(user.Name)
+		roleSelect.SetSelected(roleLabel(roles, user.Role))
+	}
+
+	username.OnSubmitted = func(string) { w.Canvas().Focus(nama) }
+	nama.OnSubmitted = func(string) {
+		if isNew {
+			w.Canvas().Focus(password)
+		}
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Username", username),
+		widget.NewFormItem("Nama", nama),
+	)
+	if isNew {
+		form.Append("Password", password)
+	}
+	form.Append("Role", roleSelect)
+
+	var d dialog.Dialog
+
+	submitBtn := widget.NewButton("Simpan", func() {
+		if username.Text == "" || nama.Text == "" || roleSelect.Selected == "" {
+			dialog.ShowInformation("Error", "Username, nama dan role harus diisi!", w)
+			return
+		}
+
+		if isNew {
+			if len(password.Text) < minPasswordLength {
+				dialog.ShowInformation("Error", fmt.Sprintf("Password minimal %d karakter!", minPasswordLength), w)
+				return
+			}
+
+			existing, _ := s.UserRepo.GetByUsername(username.Text)
+			if existing != nil {
+				dialog.ShowInformation("Error", "Username sudah terdaftar, silakan gunakan username lain!", w)
+				return
+			}
+
+			newUser := &models.User{
+				Username: username.Text,
+				Name:     nama.Text,
+				Password: password.Text,
+				Role:     roleCodes[roleSelect.Selected],
+			}
+			if err := s.UserRepo.Create(newUser, s.User.ID); err != nil {
+				dialog.ShowError(fmt.Errorf("Gagal menyimpan user: %v", err), w)
+				return
+			}
+		} else {
+			updated := *user
+			updated.Name = nama.Text
+			updated.Role = roleCodes[roleSelect.Selected]
+			if err := s.UserRepo.Update(&updated, s.User.ID); err != nil {
+				dialog.ShowError(fmt.Errorf("Gagal mengupdate user: %v", err), w)
+				return
+			}
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", "Data user berhasil disimpan!", w)
+		if onClose != nil {
+			onClose()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	dialogTitle := "User Baru"
+	if !isNew {
+		dialogTitle = "Edit User"
+	}
+	d = dialog.NewCustom(dialogTitle, "", dialogContent, w)
+	d.Resize(fyne.NewSize(420, 300))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			if isNew {
+				w.Canvas().Focus(username)
+			} else {
+				w.Canvas().Focus(nama)
+			}
+		})
+	})
+}
+
+func showResetPasswordDialog(w fyne.Window, s *state.Session, user models.User, onClose func()) {
+	newPassword := widget.NewPasswordEntry()
+	confirmPassword := widget.NewPasswordEntry()
+
+	newPassword.OnSubmitted = func(string) { w.Canvas().Focus(confirmPassword) }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Password Baru", newPassword),
+		widget.NewFormItem("Ulangi Password", confirmPassword),
+	)
+
+	var d dialog.Dialog
+
+	submitBtn := widget.NewButton("Reset", func() {
+		if len(newPassword.Text) < minPasswordLength {
+			dialog.ShowInformation("Error", fmt.Sprintf("Password minimal %d karakter!", minPasswordLength), w)
+			return
+		}
+		if newPassword.Text != confirmPassword.Text {
+			dialog.ShowInformation("Error", "Konfirmasi password tidak sama!", w)
+			return
+		}
+
+		if err := s.UserRepo.ResetPassword(user.ID, newPassword.Text, s.User.ID); err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal reset password: %v", err), w)
+			return
+		}
+
+		d.Hide()
+		ShowSuccessToast("Success", fmt.Sprintf("Password '%s' berhasil direset!", user.Username), w)
+		if onClose != nil {
+			onClose()
+		}
+	})
+	submitBtn.Importance = widget.HighImportance
+
+	confirmPassword.OnSubmitted = func(string) { submitBtn.OnTapped() }
+
+	cancelBtn := widget.NewButton("Cancel", func() {
+		d.Hide()
+		if onClose != nil {
+			onClose()
+		}
+	})
+	cancelBtn.Importance = widget.DangerImportance
+
+	buttons := container.NewGridWithColumns(2, cancelBtn, submitBtn)
+	content := container.NewBorder(nil, buttons, nil, nil, form)
+
+	dialogContent := container.NewMax(
+		canvas.NewRectangle(color.NRGBA{R: 255, G: 255, B: 255, A: 255}),
+		container.NewPadded(content),
+	)
+
+	d = dialog.NewCustom("Reset Password - "+user.Username, "", dialogContent, w)
+	d.Resize(fyne.NewSize(420, 240))
+	d.Show()
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		fyne.Do(func() {
+			w.Canvas().Focus(newPassword)
+		})
+	})
+}
+
+// roleLabel returns the display name of a role code
+func roleLabel(roles []models.Role, code string) string {
+	for _, r := range roles {
+		if r.Code == code {
+			return r.Name
+		}
+	}
+	return code
+}
+
+func UserManagementPage(w fyne.Window, s *state.Session) fyne.CanvasObject {
+	bg := canvas.NewImageFromFile("assets/bg-login.jpg")
+	bg.FillMode = canvas.ImageFillStretch
+
+	backBtn := widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+		w.SetContent(HomePage(w, s))
+	})
+
+	title := canvas.NewText("MANAJEMEN USER", color.White)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.TextSize = 16
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search username, nama atau role...")
+
+	header := container.NewGridWithColumns(3, backBtn, container.NewCenter(title), container.NewMax(search))
+
+	headers := []string{"Username", "Nama", "Role", "Status", "Dibuat"}
+	headerBg := color.NRGBA{R: 30, G: 30, B: 30, A: 255}
+	rowBg := color.NRGBA{R: 235, G: 235, B: 235, A: 255}
+
+	roles, err := s.UserRepo.GetRoles()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Gagal memuat role: %v", err), w)
+	}
+
+	var data []models.User
+	var selectedRow int = -1
+
+	loadData := func(keyword string) {
+		var users []models.User
+		var err error
+
+		if keyword == "" {
+			users, err = s.UserRepo.GetAll()
+		} else {
+			users, err = s.UserRepo.Search(keyword)
+		}
+
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gagal memuat data: %v", err), w)
+			return
+		}
+		data = users
+	}
+
+	loadData("")
+
+	table := widget.NewTable(
+		func() (int, int) { return len(data) + 1, len(headers) },
+		func() fyne.CanvasObject {
+			bg := canvas.NewRectangle(color.Transparent)
+			text := canvas.NewText("", color.Black)
+			text.TextSize = 13
+			return container.NewMax(bg, text)
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			cont := cell.(*fyne.Container)
+			bg := cont.Objects[0].(*canvas.Rectangle)
+			text := cont.Objects[1].(*canvas.Text)
+
+			if id.Row == 0 {
+				bg.FillColor = headerBg
+				text.Text = headers[id.Col]
+				text.Color = color.White
+				text.TextSize = 14
+				text.TextStyle = fyne.TextStyle{Bold: true}
+				text.Alignment = fyne.TextAlignCenter
+				text.Refresh()
+				return
+			}
+
+			if id.Row-1 == selectedRow {
+				bg.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+				text.Color = color.White
+			} else {
+				bg.FillColor = rowBg
+				text.Color = color.Black
+			}
+			text.TextStyle = fyne.TextStyle{}
+			text.TextSize = 13
+
+			if id.Row-1 < len(data) {
+				u := data[id.Row-1]
+
+				if !u.IsActive() && id.Row-1 != selectedRow {
+					text.Color = color.NRGBA{R: 220, G: 50, B: 50, A: 255}
+				}
+
+				switch id.Col {
+				case 0:
+					text.Text = u.Username
+					text.Alignment = fyne.TextAlignLeading
+				case 1:
+					text.Text = u.Name
+					text.Alignment = fyne.TextAlignLeading
+				case 2:
+					text.Text = roleLabel(roles, u.Role)
+					text.Alignment = fyne.TextAlignCenter
+				case 3:
+					text.Text = "AKTIF"
+					if !u.IsActive() {
+						text.Text = "NONAKTIF"
+					}
+					text.Alignment = fyne.TextAlignCenter
+				case 4:
+					text.Text = u.CreatedAt.Format("2006-01-02")
+					text.Alignment = fyne.TextAlignCenter
+				}
+			}
+			text.Refresh()
+		},
+	)
+
+	table.SetColumnWidth(0, 180)
+	table.SetColumnWidth(1, 300)
+	table.SetColumnWidth(2, 160)
+	table.SetColumnWidth(3, 130)
+	table.SetColumnWidth(4, 150)
+
+	var focusWrapper *focusableTable
+	safeFocus := func() {
+		if focusWrapper != nil {
+			fyne.Do(func() {
+				w.Canvas().Focus(focusWrapper)
+			})
+		}
+	}
+
+	refreshTable := func() {
+		loadData(search.Text)
+		table.Refresh()
+		safeFocus()
+	}
+
+	search.OnChanged = func(keyword string) {
+		selectedRow = -1
+		loadData(keyword)
+		table.Refresh()
+	}
+
+	var lastDialogTime time.Time
+	var isDialogOpen bool
+
+	handleKey := func(k *fyne.KeyEvent) {
+		if time.Since(lastDialogTime) < 500*time.Millisecond || isDialogOpen {
+			return
+		}
+
+		switch k.Name {
+
+		// User baru
+		case fyne.KeyInsert:
+			lastDialogTime = time.Now()
+			isDialogOpen = true
+			showUserDialog(w, s, nil, roles, func() { isDialogOpen = false; refreshTable() })
+
+		// Edit nama dan role
+		case fyne.KeyE:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				selected := data[selectedRow]
+				showUserDialog(w, s, &selected, roles, func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih user terlebih dahulu!", w)
+			}
+
+		// Reset password
+		case fyne.KeyR:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				isDialogOpen = true
+				showResetPasswordDialog(w, s, data[selectedRow], func() { isDialogOpen = false; refreshTable() })
+			} else {
+				dialog.ShowInformation("Info", "Pilih user terlebih dahulu!", w)
+			}
+
+		// Nonaktifkan / aktifkan kembali
+		case fyne.KeyDelete:
+			lastDialogTime = time.Now()
+			if selectedRow >= 0 && selectedRow < len(data) {
+				selected := data[selectedRow]
+				activate := !selected.IsActive()
+
+				message := fmt.Sprintf("Nonaktifkan user '%s'? User tidak akan bisa login lagi.", selected.Username)
+				if activate {
+					message = fmt.Sprintf("Aktifkan kembali user '%s'?", selected.Username)
+				}
+
+				dialog.ShowConfirm("Status User", message,
+					func(b bool) {
+						if !b {
+							return
+						}
+						err := s.UserRepo.SetActive(selected.ID, activate, s.User.ID)
+						if err != nil {
+							if errors.Is(err, repository.ErrLastAdmin) {
+								dialog.ShowInformation("Info", "Minimal harus ada satu admin yang aktif!", w)
+							} else {
+								dialog.ShowError(err, w)
+							}
+							return
+						}
+						ShowSuccessToast("Success", "Status user berhasil diubah!", w)
+						refreshTable()
+					}, w)
+			} else {
+				dialog.ShowInformation("Info", "Pilih user terlebih dahulu!", w)
+			}
+
+		case fyne.KeyUp:
+			if len(data) > 0 {
+				if selectedRow > 0 {
+					selectedRow--
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyDown:
+			if len(data) > 0 {
+				if selectedRow < len(data)-1 {
+					selectedRow++
+				} else if selectedRow == -1 {
+					selectedRow = 0
+				}
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		case fyne.KeyHome:
+			if len(data) > 0 {
+				selectedRow = 0
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: 1, Col: 0})
+			}
+		case fyne.KeyEnd:
+			if len(data) > 0 {
+				selectedRow = len(data) - 1
+				table.Refresh()
+				table.ScrollTo(widget.TableCellID{Row: selectedRow + 1, Col: 0})
+			}
+		}
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row > 0 {
+			selectedRow = id.Row - 1
+			table.Refresh()
+			time.AfterFunc(50*time.Millisecond, safeFocus)
+		}
+	}
+
+	focusWrapper = newFocusableTable(table, handleKey)
+	w.Canvas().SetOnTypedKey(handleKey)
+
+	tableWrapper := container.NewCenter(container.NewGridWrap(fyne.NewSize(950, 480), focusWrapper))
+
+	footer := canvas.NewText("[Insert] User Baru  [E] Edit  [R] Reset Password  [Del] Nonaktifkan / Aktifkan", color.White)
+	footer.TextStyle = fyne.TextStyle{Italic: true}
+	footer.Alignment = fyne.TextAlignCenter
+
+	content := container.NewBorder(header, footer, nil, nil, tableWrapper)
+
+	rect := canvas.NewRectangle(color.NRGBA{R: 30, G: 30, B: 30, A: 180})
+	rect.CornerRadius = 12
+	rect.StrokeColor = color.NRGBA{R: 255, G: 255, B: 255, A: 40}
+	rect.StrokeWidth = 1
+	rect.SetMinSize(fyne.NewSize(1050, 650))
+
+	panel := container.NewMax(rect, container.NewPadded(content))
+	centeredPanel := container.NewCenter(panel)
+
+	time.AfterFunc(150*time.Millisecond, safeFocus)
+
+	return container.NewMax(bg, centeredPanel)
+}
@@ -1,31 +1,58 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/dialog"
 
-	"time"
-
 	"fyne-app/internal/config"
+	"fyne-app/internal/migrations"
+	"fyne-app/internal/repository"
 	"fyne-app/internal/state"
 	"fyne-app/internal/theme"
 	"fyne-app/internal/ui"
 )
 
 func main() {
+	backfillRetur := flag.Bool("backfill-retur-mutations", false, "catat stock_mutations untuk retur lama yang belum memiliki mutasi, lalu keluar")
+	migrateStatus := flag.Bool("migrate-status", false, "tampilkan versi skema dan migrasi yang belum dijalankan, lalu keluar")
+	migrateTo := flag.Int("migrate-to", -1, "migrasi skema ke versi tertentu (naik atau turun), lalu keluar")
+	flag.Parse()
+
+	// Load configuration: defaults -> config.toml -> DB_* environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Source != "" {
+		log.Printf("Using configuration from %s", cfg.Source)
+	}
+
+	if *migrateStatus || *migrateTo >= 0 {
+		runMigrateCommand(cfg, *migrateStatus, *migrateTo)
+		return
+	}
+
+	if *backfillRetur {
+		runBackfillReturMutations(cfg)
+		return
+	}
+
 	a := app.New()
 	a.Settings().SetTheme(&theme.AppTheme{})
-	w := a.NewWindow("Program SO")
+	w := a.NewWindow(cfg.Store.Name)
+
+	ui.ConfigurePrint(cfg)
 
-	// Panggil auto cleanup hapus PDF yang lebih tua dari 3 bulan (~90 hari)
-	go ui.AutoCleanupTempFolder(90 * 24 * time.Hour)
+	// Panggil auto cleanup hapus PDF yang lebih tua dari batas umur di konfigurasi
+	go ui.AutoCleanupTempFolder(cfg.PDFMaxAge())
 
 	// Initialize database connection
-	dbConfig := config.NewDBConfig()
-	db, err := config.ConnectDB(dbConfig)
+	db, err := config.ConnectDB(&cfg.Database, &cfg.Pool)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 		dialog.ShowError(err, w)
@@ -33,10 +60,82 @@ func main() {
 	}
 	defer db.Close()
 
+	// Bring the schema up to date before anything touches the tables
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Up(); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
 	// Initialize session with database
-	session := state.NewSession(db)
+	session, err := state.NewSession(db, cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize session: %v", err)
+	}
+	defer session.Notify.Close()
 
 	w.SetContent(ui.LoginPage(w, session))
 	w.Resize(fyne.NewSize(500, 380))
 	w.ShowAndRun()
 }
+
+// runMigrateCommand handles -migrate-status and -migrate-to
+func runMigrateCommand(cfg *config.AppConfig, status bool, target int) {
+	db, err := config.ConnectDB(&cfg.Database, &cfg.Pool)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	if target >= 0 {
+		if err := migrator.MigrateTo(target); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Printf("Skema dimigrasi ke versi %d", target)
+	}
+
+	if status {
+		applied, err := migrator.Applied()
+		if err != nil {
+			log.Fatalf("Failed to read schema_migrations: %v", err)
+		}
+		current := 0
+		if len(applied) > 0 {
+			current = applied[len(applied)-1].Version
+		}
+		fmt.Printf("Versi skema saat ini: %d (terbaru: %d)\n", current, migrator.Latest())
+
+		pending, err := migrator.Pending()
+		if err != nil {
+			log.Fatalf("Failed to read pending migrations: %v", err)
+		}
+		if len(pending) == 0 {
+			fmt.Println("Tidak ada migrasi yang tertunda.")
+		}
+		for _, m := range pending {
+			fmt.Printf("  pending %04d_%s\n", m.Version, m.Name)
+		}
+	}
+}
+
+// runBackfillReturMutations is the one-off entry point for -backfill-retur-mutations
+func runBackfillReturMutations(cfg *config.AppConfig) {
+	db, err := config.ConnectDB(&cfg.Database, &cfg.Pool)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	count, err := repository.NewReturRepository(db, nil, nil, nil).BackfillMutations()
+	if err != nil {
+		log.Fatalf("Backfill mutasi retur gagal: %v", err)
+	}
+	log.Printf("Backfill mutasi retur selesai: %d dokumen diproses", count)
+}